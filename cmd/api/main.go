@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -13,14 +14,18 @@ import (
 	v1 "github.com/swibly/swibly-api/internal/controller/http/v1"
 	"github.com/swibly/swibly-api/internal/service"
 	"github.com/swibly/swibly-api/pkg/aws"
+	"github.com/swibly/swibly-api/pkg/contentschema"
 	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/live"
 	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/ratelimit"
 	"github.com/swibly/swibly-api/pkg/sender"
 	"github.com/swibly/swibly-api/translations"
 )
 
 func main() {
 	config.Parse()
+	contentschema.Init()
 	db.Load()
 
 	if err := aws.NewAWSService(); err != nil {
@@ -31,6 +36,22 @@ func main() {
 	translations.Init("./translations")
 
 	sender.Init()
+	ratelimit.Init()
+	live.Init(service.Project)
+
+	flushInterval := config.RateLimit.APIKeyFlushInterval
+	if flushInterval <= 0 {
+		flushInterval = 30 * time.Second
+	}
+
+	go service.APIKey.FlushUsageEvery(flushInterval)
+
+	airdropInterval := config.Wallet.AirdropInterval
+	if airdropInterval <= 0 {
+		airdropInterval = time.Minute
+	}
+
+	go service.RunAirdropsEvery(airdropInterval)
 
 	gin.SetMode(config.Router.GinMode)
 
@@ -47,6 +68,7 @@ func main() {
 		gin.Recovery(),
 		middleware.DisableCache,
 		middleware.GetLanguage,
+		middleware.RateLimit("global", config.RateLimit.Global),
 		middleware.GetAPIKey,
 	)
 
@@ -80,5 +102,8 @@ func main() {
 	signal.Notify(exit, os.Interrupt, syscall.SIGTERM)
 	<-exit // Keep process alive
 
+	service.APIKey.FlushUsage()
+	live.Default.PersistAll()
+
 	log.Print("Server stopped. Graceful Shutdown (CTRL+C)")
 }
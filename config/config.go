@@ -5,11 +5,27 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
 )
 
+type RateLimitRule struct {
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+type AchievementDefinition struct {
+	Key         string `yaml:"key"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Metric      string `yaml:"metric"`
+	Threshold   uint64 `yaml:"threshold"`
+	Arkhoins    uint64 `yaml:"arkhoins"`
+	XP          uint64 `yaml:"xp"`
+}
+
 var (
 	Router struct {
 		GinMode     string `yaml:"gin_mode"`
@@ -32,8 +48,12 @@ var (
 	}
 
 	Security struct {
-		BcryptCost int    `yaml:"bcrypt_cost"`
-		JWTSecret  string `yaml:"jwt_secret"`
+		BcryptCost      int           `yaml:"bcrypt_cost"`
+		JWTSecret       string        `yaml:"jwt_secret"`
+		AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+		RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+
+		EmailVerificationCooldown time.Duration `yaml:"email_verification_cooldown"`
 	}
 
 	SMTP struct {
@@ -54,9 +74,87 @@ var (
 	}
 
 	Redirects struct {
-		SecurityTab string `yaml:"security"`
-		Profile     string `yaml:"profile"`
-		Project     string `yaml:"project"`
+		SecurityTab  string `yaml:"security"`
+		Profile      string `yaml:"profile"`
+		Project      string `yaml:"project"`
+		Invites      string `yaml:"invites"`
+		Transfers    string `yaml:"transfers"`
+		Organization string `yaml:"organization"`
+	}
+
+	RateLimit struct {
+		Backend string        `yaml:"backend"`
+		Global  RateLimitRule `yaml:"global"`
+
+		APIKeyFlushInterval time.Duration `yaml:"api_key_flush_interval"`
+
+		Login struct {
+			IP      RateLimitRule `yaml:"ip"`
+			Account RateLimitRule `yaml:"account"`
+		} `yaml:"login"`
+
+		PasswordReset struct {
+			IP      RateLimitRule `yaml:"ip"`
+			Account RateLimitRule `yaml:"account"`
+		} `yaml:"password_reset"`
+
+		Lockout struct {
+			Threshold int           `yaml:"threshold"`
+			Window    time.Duration `yaml:"window"`
+			Base      time.Duration `yaml:"base"`
+			Max       time.Duration `yaml:"max"`
+		} `yaml:"lockout"`
+	}
+
+	Wallet struct {
+		AirdropInterval time.Duration `yaml:"airdrop_interval"`
+	}
+
+	XP struct {
+		DailyCap uint64 `yaml:"daily_cap"`
+
+		Rules map[string]struct {
+			Amount   uint64 `yaml:"amount"`
+			DailyCap uint64 `yaml:"daily_cap"`
+		} `yaml:"rules"`
+
+		Levels struct {
+			Thresholds []uint64 `yaml:"thresholds"`
+			Step       uint64   `yaml:"step"`
+		} `yaml:"levels"`
+	}
+
+	Achievements struct {
+		Definitions []AchievementDefinition `yaml:"achievements"`
+	}
+
+	Projects struct {
+		Revisions struct {
+			KeepLast       int `yaml:"keep_last"`
+			DailySnapshots int `yaml:"daily_snapshots"`
+		} `yaml:"revisions"`
+
+		Live struct {
+			PersistInterval time.Duration `yaml:"persist_interval"`
+			MaxMessageSize  int64         `yaml:"max_message_size"`
+		} `yaml:"live"`
+
+		Archives struct {
+			MaxImportSize int64 `yaml:"max_import_size"`
+		} `yaml:"archives"`
+
+		ShareLinks struct {
+			DefaultExpiry time.Duration `yaml:"default_expiry"`
+			MaxExpiry     time.Duration `yaml:"max_expiry"`
+		} `yaml:"share_links"`
+
+		Invites struct {
+			Expiry time.Duration `yaml:"expiry"`
+		} `yaml:"invites"`
+
+		Transfers struct {
+			Expiry time.Duration `yaml:"expiry"`
+		} `yaml:"transfers"`
 	}
 )
 
@@ -109,6 +207,26 @@ func Parse() {
 		log.Fatalf("error: %v", err)
 	}
 
+	if err := yaml.Unmarshal(read("ratelimit.yaml"), &RateLimit); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	if err := yaml.Unmarshal(read("projects.yaml"), &Projects); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	if err := yaml.Unmarshal(read("wallet.yaml"), &Wallet); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	if err := yaml.Unmarshal(read("xp.yaml"), &XP); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	if err := yaml.Unmarshal(read("achievements.yaml"), &Achievements); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
 	log.Print("Loaded config files")
 }
 
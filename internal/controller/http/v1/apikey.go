@@ -0,0 +1,190 @@
+package v1
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+	"gorm.io/gorm"
+)
+
+func newAPIKeyRoutes(handler *gin.RouterGroup) {
+	h := handler.Group("/keys")
+	h.Use(middleware.APIKeyHasEnabledKeyManage, middleware.Auth)
+	{
+		h.GET("", middleware.HasPermissions(config.Permissions.ManageAPIKey), GetAllAPIKeysHandler)
+		h.GET("/mine", GetMyAPIKeysHandler)
+
+		h.POST("", middleware.HasPermissions(config.Permissions.ManageAPIKey), CreateAPIKeyHandler)
+	}
+
+	specific := h.Group("/:prefix", middleware.APIKeyLookup)
+	{
+		specific.GET("", GetAPIKeyHandler)
+
+		specific.PATCH("", middleware.HasPermissions(config.Permissions.ManageAPIKey), UpdateAPIKeyHandler)
+		specific.PATCH("/regenerate", RegenerateAPIKeyHandler)
+
+		specific.DELETE("", DeleteAPIKeyHandler)
+	}
+}
+
+func GetAllAPIKeysHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	keys, err := service.APIKey.GetAll(page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func GetMyAPIKeysHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	keys, err := service.APIKey.GetByOwner(issuer.Username, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func CreateAPIKeyHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	var body dto.CreateAPIKey
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	key, err := service.APIKey.Create(&body)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.UserNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+func GetAPIKeyHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, ctx.Keys["api_key_lookup"].(*dto.ReadAPIKey))
+}
+
+func UpdateAPIKeyHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	key := ctx.Keys["api_key_lookup"].(*dto.ReadAPIKey)
+
+	var body dto.UpdateAPIKey
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if err := service.APIKey.Update(key.Prefix, &body); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.UserNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.APIKeyUpdated})
+}
+
+func RegenerateAPIKeyHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	key := ctx.Keys["api_key_lookup"].(*dto.ReadAPIKey)
+
+	newKey, err := service.APIKey.Regenerate(key.Prefix)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.APIKeyUpdated, "key": newKey})
+}
+
+func DeleteAPIKeyHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	key := ctx.Keys["api_key_lookup"].(*dto.ReadAPIKey)
+
+	if err := service.APIKey.Delete(key.Prefix); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.APIKeyDestroyed})
+}
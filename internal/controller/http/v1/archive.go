@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/pkg/archive"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+)
+
+func ExportProjectHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	exported, err := service.Project.Export(issuer.ID, project.ID)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	if err := exported.Write(buf); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d.zip"`, project.ID))
+	ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// Expects the archive made by ExportProjectHandler in the "archive" field of a multipart form
+func ImportProjectHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	file, err := ctx.FormFile("archive")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectArchiveMissing})
+		return
+	}
+
+	if limit := config.Projects.Archives.MaxImportSize; limit > 0 && file.Size > limit {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.FileTooLarge})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	imported, err := archive.Read(data)
+	if err != nil {
+		if errors.Is(err, archive.ErrArchiveTooLarge) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.FileTooLarge})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectArchiveInvalid})
+		return
+	}
+
+	metadata := imported.Manifest.Project
+	project := &dto.ProjectCreation{
+		Name:        metadata.Name,
+		Description: metadata.Description,
+		Budget:      metadata.Budget,
+		Width:       metadata.Width,
+		Height:      metadata.Height,
+	}
+
+	if errs := utils.ValidateStruct(project); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	id, missing, err := service.Project.Import(issuer.ID, imported, project)
+	if err != nil {
+		projectCreationError(ctx, err)
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryProject,
+		Message:  fmt.Sprintf(dict.NotificationNewProjectCreated, project.Name),
+		Type:     notification.Information,
+		Redirect: utils.ToPtr(fmt.Sprintf(config.Redirects.Project, id)),
+	}, issuer.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectImported, "project": id, "missing_components": missing})
+}
@@ -2,17 +2,21 @@ package v1
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/swibly/swibly-api/config"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
 	"github.com/swibly/swibly-api/pkg/aws"
 	"github.com/swibly/swibly-api/pkg/middleware"
 	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/pkg/ratelimit"
 	"github.com/swibly/swibly-api/pkg/utils"
 	"github.com/swibly/swibly-api/translations"
 	"golang.org/x/crypto/bcrypt"
@@ -25,7 +29,10 @@ func newAuthRoutes(handler *gin.RouterGroup) {
 		h.GET("/validate", middleware.Auth, GetUserByBearerHandler)
 
 		h.POST("/register", RegisterHandler)
-		h.POST("/login", LoginHandler)
+		h.POST("/login", middleware.RateLimit("login", config.RateLimit.Login.IP), LoginHandler)
+		h.POST("/login/2fa", middleware.RateLimit("login", config.RateLimit.Login.IP), LoginTOTPHandler)
+		h.POST("/refresh", RefreshSessionHandler)
+		h.POST("/logout", middleware.Auth, LogoutHandler)
 
 		h.PATCH("/update", middleware.APIKeyHasEnabledUserActions, middleware.Auth, UpdateUserHandler)
 		h.PATCH("/image", middleware.APIKeyHasEnabledUserActions, middleware.Auth, UploadUserImage)
@@ -34,9 +41,31 @@ func newAuthRoutes(handler *gin.RouterGroup) {
 		h.DELETE("/image", middleware.APIKeyHasEnabledUserActions, middleware.Auth, RemoveUserImage)
 	}
 
+	sessions := h.Group("/sessions", middleware.APIKeyHasEnabledUserActions, middleware.Auth)
+	{
+		sessions.GET("", GetSessionsHandler)
+
+		sessions.DELETE("", RevokeAllSessionsHandler)
+		sessions.DELETE("/:id", RevokeSessionHandler)
+	}
+
+	twoFactor := h.Group("/2fa", middleware.APIKeyHasEnabledUserActions, middleware.Auth)
+	{
+		twoFactor.POST("/enroll", EnrollTOTPHandler)
+		twoFactor.POST("/confirm", ConfirmTOTPHandler)
+
+		twoFactor.DELETE("", DisableTOTPHandler)
+	}
+
+	verify := h.Group("/verify")
+	{
+		verify.POST("", middleware.APIKeyHasEnabledUserActions, middleware.Auth, ResendEmailVerificationHandler)
+		verify.POST("/:key", VerifyEmailHandler)
+	}
+
 	password := h.Group("/password")
 	{
-		password.POST("/reset", RequestPasswordResetHandler)
+		password.POST("/reset", middleware.RateLimit("password_reset", config.RateLimit.PasswordReset.IP), RequestPasswordResetHandler)
 		password.POST("/reset/:key", PasswordResetHandler)
 
 		password.OPTIONS("/reset/:key", ValidatePasswordResetHandler)
@@ -69,7 +98,7 @@ func RegisterHandler(ctx *gin.Context) {
 	user, err := service.User.CreateUser(ctx, body.FirstName, body.LastName, body.Username, body.Email, body.Password)
 
 	if err == nil {
-		if token, err := utils.GenerateJWT(user.ID); err != nil {
+		if tokens, err := service.Session.Create(user.ID, sessionMetadata(ctx)); err != nil {
 			log.Print(err)
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
 		} else {
@@ -79,7 +108,11 @@ func RegisterHandler(ctx *gin.Context) {
 				Type:    notification.Information,
 			}, user.ID)
 
-			ctx.JSON(http.StatusOK, gin.H{"token": token})
+			if err := service.EmailVerification.Send(dict, user.ID, user.FirstName, user.Email); err != nil {
+				log.Print(err)
+			}
+
+			ctx.JSON(http.StatusOK, tokens)
 		}
 
 		return
@@ -127,16 +160,40 @@ func LoginHandler(ctx *gin.Context) {
 	}
 
 	user, err := service.User.UnsafeGetByUsernameOrEmail(body.Username, body.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
 
-	if err != nil {
+	// Failures count against the account itself, however it was named. Unknown accounts fall back to
+	// the identifier they were asked for with.
+	var account string
+	if user != nil {
+		account = fmt.Sprintf("login:user:%d", user.ID)
+	} else if body.Username != "" {
+		account = "login:" + strings.ToLower(body.Username)
+	} else {
+		account = "login:" + strings.ToLower(body.Email)
+	}
+
+	if until, err := ratelimit.LockedUntil(account); err != nil {
 		log.Print(err)
+	} else if !until.IsZero() {
+		middleware.AbortRateLimited(ctx, until, dict.AccountLocked)
+		return
+	}
 
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.AuthWrongCredentials})
-			return
-		}
+	if result, err := ratelimit.Allow(account, config.RateLimit.Login.Account); err != nil {
+		log.Print(err)
+	} else if !result.Allowed {
+		middleware.AbortRateLimited(ctx, result.ResetAt, dict.RateLimited)
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+	// Unknown accounts count as failures too, so they can't be told apart from existing ones
+	if user == nil {
+		failedLogin(ctx, account)
 		return
 	}
 
@@ -144,7 +201,7 @@ func LoginHandler(ctx *gin.Context) {
 		log.Print(err)
 
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.AuthWrongCredentials})
+			failedLogin(ctx, account)
 			return
 		}
 
@@ -152,7 +209,53 @@ func LoginHandler(ctx *gin.Context) {
 		return
 	}
 
-	if token, err := utils.GenerateJWT(user.ID); err != nil {
+	if err := ratelimit.ClearFailures(account); err != nil {
+		log.Print(err)
+	}
+
+	twoFactor, err := service.TOTP.IsEnabled(user.ID)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	// The session is only created once the second step (`/auth/login/2fa`) succeeds
+	if twoFactor {
+		challenge, err := utils.GenerateChallengeJWT(user.ID)
+		if err != nil {
+			log.Print(err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, gin.H{"message": dict.AuthTwoFactorRequired, "challenge": challenge})
+		return
+	}
+
+	completeLogin(ctx, user.ID)
+}
+
+func failedLogin(ctx *gin.Context, account string) {
+	dict := translations.GetTranslation(ctx)
+
+	until, err := ratelimit.RegisterFailure(account)
+	if err != nil {
+		log.Print(err)
+	}
+
+	if !until.IsZero() {
+		middleware.AbortRateLimited(ctx, until, dict.AccountLocked)
+		return
+	}
+
+	ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.AuthWrongCredentials})
+}
+
+func completeLogin(ctx *gin.Context, userID uint) {
+	dict := translations.GetTranslation(ctx)
+
+	if tokens, err := service.Session.Create(userID, sessionMetadata(ctx)); err != nil {
 		log.Print(err)
 
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
@@ -162,9 +265,9 @@ func LoginHandler(ctx *gin.Context) {
 			Message:  dict.NotificationNewLoginDetected,
 			Type:     notification.Warning,
 			Redirect: &config.Redirects.SecurityTab,
-		}, user.ID)
+		}, userID)
 
-		ctx.JSON(http.StatusOK, gin.H{"token": token})
+		ctx.JSON(http.StatusOK, tokens)
 	}
 }
 
@@ -196,16 +299,23 @@ func UpdateUserHandler(ctx *gin.Context) {
 		}
 	}
 
-	if body.Email != nil && *body.Email != "" && *body.Email != issuer.Email {
+	emailChanged := body.Email != nil && *body.Email != "" && *body.Email != issuer.Email
+
+	if emailChanged {
 		if profile, err := service.User.GetByEmail(*body.Email); profile != nil && err == nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.AuthDuplicatedUser})
 			return
 		}
+
+		// The new address has to be verified again
+		body.Verified = utils.ToPtr(false)
 	}
 
 	if body.Password != nil && *body.Password != "" {
 		if hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*body.Password), config.Security.BcryptCost); err != nil {
+			log.Print(err)
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+			return
 		} else {
 			*body.Password = string(hashedPassword)
 		}
@@ -217,6 +327,21 @@ func UpdateUserHandler(ctx *gin.Context) {
 		return
 	}
 
+	if emailChanged {
+		if err := service.EmailVerification.Send(dict, issuer.ID, issuer.FirstName, *body.Email); err != nil {
+			log.Print(err)
+		}
+	}
+
+	// Changing the password logs out every device, including this one
+	if body.Password != nil && *body.Password != "" {
+		if err := service.Session.RevokeAll(issuer.ID); err != nil {
+			log.Print(err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+			return
+		}
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.AuthUserUpdated})
 }
 
@@ -278,6 +403,11 @@ func DeleteUserHandler(ctx *gin.Context) {
 	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
 
 	if err := service.User.DeleteUser(issuer.ID); err != nil {
+		if errors.Is(err, repository.ErrOrganizationLastOwner) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.OrganizationLastOwner})
+			return
+		}
+
 		log.Print(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
 		return
@@ -319,6 +449,13 @@ func RequestPasswordResetHandler(ctx *gin.Context) {
 		return
 	}
 
+	if result, err := ratelimit.Allow("password_reset:"+strings.ToLower(body.Email), config.RateLimit.PasswordReset.Account); err != nil {
+		log.Print(err)
+	} else if !result.Allowed {
+		middleware.AbortRateLimited(ctx, result.ResetAt, dict.RateLimited)
+		return
+	}
+
 	if err := service.PasswordReset.Request(dict, body.Email); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			log.Print(err)
@@ -388,3 +525,45 @@ func ValidatePasswordResetHandler(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusNotAcceptable, gin.H{"message": dict.InvalidPasswordResetKey})
 }
+
+func ResendEmailVerificationHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	if issuer.Verified {
+		ctx.JSON(http.StatusConflict, gin.H{"error": dict.EmailAlreadyVerified})
+		return
+	}
+
+	if err := service.EmailVerification.Resend(dict, issuer.ID, issuer.FirstName, issuer.Email); err != nil {
+		if errors.Is(err, repository.ErrEmailVerificationThrottled) {
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": dict.EmailVerificationThrottled})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": dict.EmailVerificationSent})
+}
+
+func VerifyEmailHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	if err := service.EmailVerification.Verify(ctx.Param("key")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Print(err)
+			ctx.JSON(http.StatusForbidden, gin.H{"error": dict.InvalidEmailVerificationKey})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.EmailVerified})
+}
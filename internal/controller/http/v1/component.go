@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/internal/service"
 	"github.com/swibly/swibly-api/internal/service/repository"
@@ -28,11 +29,22 @@ func newComponentRoutes(handler *gin.RouterGroup) {
 
 		h.DELETE("/trash", DeleteTrashComponentsHandler)
 
+		transfers := h.Group("/transfers")
+		{
+			transfers.GET("", GetOwnComponentTransfersHandler)
+
+			transfers.POST("/:transfer/accept", AcceptComponentTransferHandler)
+
+			transfers.DELETE("/:transfer", DeclineComponentTransferHandler)
+		}
+
 		byUser := h.Group("/user/:username", middleware.UserLookup)
 		{
 			byUser.GET("", middleware.UserPrivacy(dto.UserShow{Components: true}), GetComponentsByUserHandler)
 			byUser.GET("/owned", GetOwnedComponentsByUserHandler)
 		}
+
+		h.GET("/organization/:organization", middleware.OrganizationLookup, GetComponentsByOrganizationHandler)
 	}
 
 	specific := h.Group("/:id", middleware.ComponentLookup)
@@ -47,6 +59,15 @@ func newComponentRoutes(handler *gin.RouterGroup) {
 
 		specific.DELETE("/unpublish", middleware.ComponentOwnership, UnpublishComponentHandler)
 
+		transfer := specific.Group("/transfer", middleware.ComponentTransferOwnership)
+		{
+			transfer.GET("", GetComponentTransferHandler)
+
+			transfer.POST("/:username", middleware.UserLookup, TransferComponentHandler)
+
+			transfer.DELETE("", CancelComponentTransferHandler)
+		}
+
 		trashActions := specific.Group("/trash", middleware.ComponentOwnership)
 		{
 			trashActions.PATCH("/restore", RestoreComponentHandler)
@@ -141,7 +162,18 @@ func CreateComponentHandler(ctx *gin.Context) {
 
 	component.OwnerID = issuer.ID
 
+	organizationID, ok := organizationFromQuery(ctx, dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin, dto.OrganizationRoleMember)
+	if !ok {
+		return
+	}
+
+	component.OrganizationID = organizationID
+
 	if err := service.Component.Create(component); err != nil {
+		if contentInvalid(ctx, err) {
+			return
+		}
+
 		log.Print(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
 		return
@@ -195,6 +227,36 @@ func GetComponentsByUserHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, components)
 }
 
+// Members see every component of the organization, everyone else only the published ones
+func GetComponentsByOrganizationHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	onlyPublic := organization.Role == "" && !issuer.HasPermissions(config.Permissions.ManageStore)
+
+	components, err := service.Component.GetByOrganization(issuer.ID, organization.ID, onlyPublic, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, components)
+}
+
 func GetOwnedComponentsByUserHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
@@ -247,6 +309,10 @@ func UpdateComponentHandler(ctx *gin.Context) {
 	}
 
 	if err := service.Component.Update(component.ID, body); err != nil {
+		if contentInvalid(ctx, err) {
+			return
+		}
+
 		if errors.Is(err, repository.ErrComponentNotFound) {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": dict.ComponentAlreadyTrashed})
 			return
@@ -260,13 +326,51 @@ func UpdateComponentHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentUpdated})
 }
 
+// Reads the Idempotency-Key header clients send so a retried purchase or sale is not charged twice.
+// Responds and returns false when the key is not usable.
+func idempotencyKey(ctx *gin.Context) (*string, bool) {
+	dict := translations.GetTranslation(ctx)
+
+	key := ctx.GetHeader("Idempotency-Key")
+	if key == "" {
+		return nil, true
+	}
+
+	if len(key) > 255 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": dict.IdempotencyKeyInvalid})
+		return nil, false
+	}
+
+	return &key, true
+}
+
 func BuyComponentHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
 	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
 	component := ctx.Keys["component_lookup"].(*dto.ComponentInfo)
 
-	if err := service.Component.Buy(issuer.ID, component.ID); err != nil {
+	// Only owners and admins can spend from the organization wallet
+	organizationID, ok := organizationFromQuery(ctx, dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin)
+	if !ok {
+		return
+	}
+
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	if err := service.Component.Buy(issuer.ID, component.ID, organizationID, key); err != nil {
+		// A retry of a purchase that went through gets the same answer, without the notifications
+		if errors.Is(err, repository.ErrAlreadyProcessed) {
+			ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentBought})
+			return
+		}
+		if errors.Is(err, repository.ErrIdempotencyKeyReused) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": dict.IdempotencyKeyReused})
+			return
+		}
 		if errors.Is(err, repository.ErrInsufficientArkhoins) {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": dict.InsufficientArkhoins})
 			return
@@ -297,6 +401,9 @@ func BuyComponentHandler(ctx *gin.Context) {
 		Type:    notification.Information,
 	}, issuer.ID)
 
+	service.AwardXP(dict, component.OwnerID, dto.XPComponentSold, fmt.Sprintf("component:%d:user:%d", component.ID, issuer.ID))
+	service.CheckAchievements(dict, component.OwnerID, dto.MetricComponentsSold)
+
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentBought})
 }
 
@@ -306,7 +413,20 @@ func SellComponentHandler(ctx *gin.Context) {
 	issuerID := ctx.Keys["auth_user"].(*dto.UserProfile).ID
 	componentID := ctx.Keys["component_lookup"].(*dto.ComponentInfo).ID
 
-	if err := service.Component.Sell(issuerID, componentID); err != nil {
+	key, ok := idempotencyKey(ctx)
+	if !ok {
+		return
+	}
+
+	if err := service.Component.Sell(issuerID, componentID, key); err != nil {
+		if errors.Is(err, repository.ErrAlreadyProcessed) {
+			ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentSold})
+			return
+		}
+		if errors.Is(err, repository.ErrIdempotencyKeyReused) {
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": dict.IdempotencyKeyReused})
+			return
+		}
 		if errors.Is(err, repository.ErrComponentNotOwned) {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": dict.ComponentNotOwned})
 			return
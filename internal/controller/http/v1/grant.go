@@ -0,0 +1,291 @@
+package v1
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+)
+
+func newGrantRoutes(handler *gin.RouterGroup) {
+	h := handler.Group("/grants", middleware.APIKeyHasEnabledUserActions, middleware.Auth, middleware.HasAnyPermissions(config.Permissions.ManageStore, config.Permissions.ManageUser))
+	{
+		h.GET("", GetGrantsHandler)
+		h.POST("", BulkGrantHandler)
+
+		h.GET("/airdrops", GetAirdropsHandler)
+		h.POST("/airdrops", ScheduleAirdropHandler)
+		h.DELETE("/airdrops/:airdrop", CancelAirdropHandler)
+	}
+}
+
+// Arkhoins belong to the store, XP to whoever manages users
+func canGrant(ctx *gin.Context, currency string) bool {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	permission := config.Permissions.ManageUser
+	if currency == dto.GrantArkhoin {
+		permission = config.Permissions.ManageStore
+	}
+
+	if !issuer.HasPermissions(permission) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": dict.Unauthorized})
+		return false
+	}
+
+	return true
+}
+
+func grantError(ctx *gin.Context, err error) {
+	dict := translations.GetTranslation(ctx)
+
+	switch {
+	case errors.Is(err, repository.ErrInsufficientArkhoins):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InsufficientArkhoins})
+	case errors.Is(err, repository.ErrInsufficientXP):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InsufficientXP})
+	default:
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+	}
+}
+
+// Looks the usernames up, answering with the ones that do not exist when there are any
+func resolveGrantUsernames(ctx *gin.Context, usernames []string) ([]uint, bool) {
+	dict := translations.GetTranslation(ctx)
+
+	ids, missing, err := service.Grant.ResolveUsernames(usernames)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return nil, false
+	}
+
+	if len(missing) > 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.GrantUsersNotFound, "usernames": missing})
+		return nil, false
+	}
+
+	return ids, true
+}
+
+func GrantUserHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	grant := &dto.GrantCreation{}
+	if err := ctx.BindJSON(grant); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(grant); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if !canGrant(ctx, grant.Currency) {
+		return
+	}
+
+	changes, err := service.Grant.Grant(issuer.ID, []uint{user.ID}, grant)
+	if err != nil {
+		grantError(ctx, err)
+		return
+	}
+
+	service.NotifyGrant(dict, grant, user.ID)
+	service.NotifyLevelUps(dict, changes...)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.GrantApplied})
+}
+
+func BulkGrantHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	grant := &dto.BulkGrantCreation{}
+	if err := ctx.BindJSON(grant); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(grant); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if !canGrant(ctx, grant.Currency) {
+		return
+	}
+
+	ids, ok := resolveGrantUsernames(ctx, grant.Usernames)
+	if !ok {
+		return
+	}
+
+	changes, err := service.Grant.Grant(issuer.ID, ids, &grant.GrantCreation)
+	if err != nil {
+		grantError(ctx, err)
+		return
+	}
+
+	service.NotifyGrant(dict, &grant.GrantCreation, ids...)
+	service.NotifyLevelUps(dict, changes...)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.GrantApplied})
+}
+
+// Audit log of every grant, newest first. ?username= narrows it down to a single user
+func GetGrantsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	var userID *uint
+	if username := ctx.Query("username"); username != "" {
+		user, err := service.User.GetByUsername(username)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.UserNotFound})
+			return
+		}
+
+		userID = &user.ID
+	}
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	grants, err := service.Grant.GetAll(userID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, grants)
+}
+
+func GetAirdropsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	airdrops, err := service.Grant.GetAirdrops(page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, airdrops)
+}
+
+// Recipients are notified in the language the airdrop was scheduled in
+func ScheduleAirdropHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	airdrop := &dto.AirdropCreation{}
+	if err := ctx.BindJSON(airdrop); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(airdrop); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if !canGrant(ctx, airdrop.Currency) {
+		return
+	}
+
+	if !airdrop.ScheduledAt.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.AirdropInPast})
+		return
+	}
+
+	var ids []uint
+	if !airdrop.Everyone {
+		var ok bool
+		if ids, ok = resolveGrantUsernames(ctx, airdrop.Usernames); !ok {
+			return
+		}
+	}
+
+	airdropID, err := service.Grant.ScheduleAirdrop(issuer.ID, ctx.GetString("lang_code"), ids, airdrop)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.AirdropScheduled, "id": airdropID})
+}
+
+func CancelAirdropHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	airdropID, err := strconv.ParseUint(ctx.Param("airdrop"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.AirdropNotFound})
+		return
+	}
+
+	if err := service.Grant.CancelAirdrop(uint(airdropID)); err != nil {
+		if errors.Is(err, repository.ErrAirdropNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.AirdropNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.AirdropCanceled})
+}
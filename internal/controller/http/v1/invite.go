@@ -0,0 +1,253 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+	"gorm.io/gorm"
+)
+
+// The invites waiting for the issuer to accept or decline them
+func GetOwnProjectInvitesHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	invites, err := service.ProjectInvite.GetByInvitee(issuer, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invites)
+}
+
+func AcceptProjectInviteHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	inviteID, err := strconv.ParseUint(ctx.Param("invite"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectInviteNotFound})
+		return
+	}
+
+	invite, err := service.ProjectInvite.Accept(issuer, uint(inviteID))
+	if err != nil {
+		if errors.Is(err, repository.ErrInviteNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectInviteNotFound})
+			return
+		}
+
+		if errors.Is(err, repository.ErrCannotAssignOwner) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectCannotAssignOwner})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	notifyInviteAnswer(dict, invite, issuer, fmt.Sprintf(dict.NotificationAddedUserToProject, issuer.FirstName+" "+issuer.LastName, invite.ProjectName))
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectInviteAccepted, "project": invite.ProjectID})
+}
+
+func DeclineProjectInviteHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	inviteID, err := strconv.ParseUint(ctx.Param("invite"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectInviteNotFound})
+		return
+	}
+
+	invite, err := service.ProjectInvite.Decline(issuer, uint(inviteID))
+	if err != nil {
+		if errors.Is(err, repository.ErrInviteNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectInviteNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	notifyInviteAnswer(dict, invite, issuer, fmt.Sprintf(dict.NotificationUserDeclinedInvite, issuer.FirstName+" "+issuer.LastName, invite.ProjectName))
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectInviteDeclined})
+}
+
+// Tells the owner of the project and whoever sent the invite how it was answered
+func notifyInviteAnswer(dict translations.Translation, invite *dto.ProjectInviteInfo, invitee *dto.UserProfile, message string) {
+	ids := []uint{}
+
+	if project, err := service.Project.GetByID(invitee.ID, invite.ProjectID); err == nil {
+		ids = append(ids, project.OwnerID)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Print(err)
+	}
+
+	if invite.InviterID != nil && (len(ids) == 0 || ids[0] != *invite.InviterID) {
+		ids = append(ids, *invite.InviterID)
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryProject,
+		Message:  message,
+		Type:     notification.Information,
+		Redirect: utils.ToPtr(fmt.Sprintf(config.Redirects.Project, invite.ProjectID)),
+	}, ids...)
+}
+
+func GetProjectInvitesHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	invites, err := service.ProjectInvite.GetByProject(project.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invites)
+}
+
+// Invites by email address. When the address belongs to a user, it works the same as assigning them,
+// otherwise a link to sign up is sent to the address.
+func InviteEmailToProjectHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	var body dto.ProjectInviteByEmail
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if !resolveProjectRole(ctx, project.ID, &body.ProjectAssign) {
+		return
+	}
+
+	if body.ProjectAssign.IsEmpty() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectEmptyAssign})
+		return
+	}
+
+	user, err := service.User.GetByEmail(body.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := service.ProjectInvite.InviteEmail(dict, project, issuer, body.Email, &body.ProjectAssign); err != nil {
+			log.Print(err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectInviteSent})
+		return
+	} else if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	if project.OrganizationID == nil && user.ID == project.OwnerID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectCannotAssignOwner})
+		return
+	}
+
+	if err := service.ProjectInvite.Invite(project.ID, issuer.ID, user.ID, &body.ProjectAssign); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryProject,
+		Message:  fmt.Sprintf(dict.NotificationInvitedYouToProject, issuer.FirstName+" "+issuer.LastName, project.Name),
+		Type:     notification.Information,
+		Redirect: utils.ToPtr(config.Redirects.Invites),
+	}, user.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectInviteSent})
+}
+
+func CancelProjectInviteHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	inviteID, err := strconv.ParseUint(ctx.Param("invite"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectInviteNotFound})
+		return
+	}
+
+	if err := service.ProjectInvite.Cancel(project.ID, uint(inviteID)); err != nil {
+		if errors.Is(err, repository.ErrInviteNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectInviteNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectInviteCanceled})
+}
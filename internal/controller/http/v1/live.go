@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/live"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/translations"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// Any origin is accepted, just like in the CORS config. Requests are authenticated by token, not cookies
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func LiveProjectHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// The upgrader already replied with the error
+		log.Print(err)
+		return
+	}
+
+	// The rechecks can outlive the handler, so they work on a copy of the request
+	request := ctx.Copy()
+
+	live.Default.Serve(project.ID, issuer, dict, conn, func() bool {
+		return middleware.StillHasProjectPermissions(request, dto.Allow{Edit: true})
+	})
+}
@@ -0,0 +1,376 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/live"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+	"gorm.io/gorm"
+)
+
+func newOrganizationRoutes(handler *gin.RouterGroup) {
+	h := handler.Group("/organizations", middleware.APIKeyHasEnabledUserFetch, middleware.Auth)
+	{
+		h.POST("", middleware.APIKeyHasEnabledUserActions, CreateOrganizationHandler)
+
+		h.GET("/user/:username", middleware.UserLookup, GetOrganizationsByUserHandler)
+	}
+
+	specific := h.Group("/:organization", middleware.OrganizationLookup)
+	{
+		specific.GET("", GetOrganizationHandler)
+		specific.GET("/members", GetOrganizationMembersHandler)
+
+		actions := specific.Group("", middleware.APIKeyHasEnabledUserActions)
+		{
+			actions.PATCH("", middleware.OrganizationHasRole(dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin), UpdateOrganizationHandler)
+			actions.POST("/deposit", middleware.OrganizationHasRole(dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin, dto.OrganizationRoleMember), DepositOrganizationHandler)
+			actions.DELETE("", middleware.OrganizationHasRole(dto.OrganizationRoleOwner), DeleteOrganizationHandler)
+
+			members := actions.Group("/members/:username", middleware.UserLookup)
+			{
+				members.PUT("", middleware.OrganizationHasRole(dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin), SetOrganizationMemberHandler)
+				members.DELETE("", RemoveOrganizationMemberHandler)
+			}
+		}
+	}
+}
+
+// Resolves the ?organization= query param used to act on behalf of an organization. The issuer must have one of
+// the roles, and nil is returned when the param is not set. Responds and returns false when it cannot be used.
+func organizationFromQuery(ctx *gin.Context, roles ...string) (*uint, bool) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	name := ctx.Query("organization")
+	if name == "" {
+		return nil, true
+	}
+
+	organization, err := service.Organization.GetByName(issuer.ID, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.OrganizationNotFound})
+			return nil, false
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return nil, false
+	}
+
+	if !slices.Contains(roles, organization.Role) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": dict.OrganizationMissingPermissions})
+		return nil, false
+	}
+
+	return &organization.ID, true
+}
+
+func CreateOrganizationHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	var body dto.OrganizationCreation
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	id, err := service.Organization.Create(&body, issuer.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrOrganizationNameTaken) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": dict.OrganizationNameTaken})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.OrganizationCreated, "organization": id})
+}
+
+func GetOrganizationsByUserHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	organizations, err := service.Organization.GetByMember(issuer.ID, user.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organizations)
+}
+
+func GetOrganizationHandler(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, ctx.Keys["organization_lookup"])
+}
+
+func GetOrganizationMembersHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	members, err := service.Organization.GetMembers(organization.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+func UpdateOrganizationHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+
+	var body dto.OrganizationUpdate
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if err := service.Organization.Update(organization.ID, &body); err != nil {
+		if errors.Is(err, repository.ErrOrganizationNameTaken) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": dict.OrganizationNameTaken})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.OrganizationUpdated})
+}
+
+func DeleteOrganizationHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+
+	if err := service.Organization.Delete(organization.ID, issuer.ID); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	live.Default.RecheckAll()
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.OrganizationDeleted})
+}
+
+// Moves Arkhoins from the issuer to the organization wallet
+func DepositOrganizationHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+
+	var body dto.OrganizationDeposit
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if err := service.Organization.Deposit(organization.ID, issuer.ID, body.Amount); err != nil {
+		if errors.Is(err, repository.ErrInsufficientArkhoins) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InsufficientArkhoins})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.OrganizationDeposited})
+}
+
+// Adds the user to the organization or changes their role. Only owners can make someone an owner or
+// change the role of another owner.
+func SetOrganizationMemberHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	var body dto.OrganizationMemberAssign
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	currentRole, err := service.Organization.GetRole(organization.ID, user.ID)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	isOwner := organization.Role == dto.OrganizationRoleOwner || issuer.HasPermissions(config.Permissions.ManageUser)
+	if (body.Role == dto.OrganizationRoleOwner || currentRole == dto.OrganizationRoleOwner) && !isOwner {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": dict.OrganizationMissingPermissions})
+		return
+	}
+
+	if err := service.Organization.SetMember(organization.ID, user.ID, body.Role); err != nil {
+		if errors.Is(err, repository.ErrOrganizationLastOwner) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.OrganizationLastOwner})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	// Roles apply to every project of the organization
+	live.Default.RecheckAll()
+
+	if currentRole == "" && user.ID != issuer.ID {
+		service.CreateNotification(dto.CreateNotification{
+			Title:    dict.CategoryOrganization,
+			Message:  fmt.Sprintf(dict.NotificationAddedYouToOrganization, issuer.FirstName+" "+issuer.LastName, organization.DisplayName),
+			Type:     notification.Information,
+			Redirect: utils.ToPtr(fmt.Sprintf(config.Redirects.Organization, organization.Name)),
+		}, user.ID)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.OrganizationMemberUpdated})
+}
+
+// Members can always leave, owners and admins can remove others. Admins cannot remove owners.
+func RemoveOrganizationMemberHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	if user.ID != issuer.ID {
+		role, err := service.Organization.GetRole(organization.ID, user.ID)
+		if err != nil {
+			log.Print(err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+			return
+		}
+
+		isAdmin := issuer.HasPermissions(config.Permissions.ManageUser)
+		if !isAdmin && (!dto.IsOrganizationManager(organization.Role) || (role == dto.OrganizationRoleOwner && organization.Role != dto.OrganizationRoleOwner)) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": dict.OrganizationMissingPermissions})
+			return
+		}
+	}
+
+	if err := service.Organization.RemoveMember(organization.ID, user.ID); err != nil {
+		if errors.Is(err, repository.ErrNotOrganizationMember) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.OrganizationNotMember})
+			return
+		}
+
+		if errors.Is(err, repository.ErrOrganizationLastOwner) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.OrganizationLastOwner})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	live.Default.RecheckAll()
+
+	if user.ID != issuer.ID {
+		service.CreateNotification(dto.CreateNotification{
+			Title:   dict.CategoryOrganization,
+			Message: fmt.Sprintf(dict.NotificationRemovedYouFromOrganization, organization.DisplayName),
+			Type:    notification.Information,
+		}, user.ID)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.OrganizationMemberRemoved})
+}
@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/translations"
+	"gorm.io/gorm"
+)
+
+func newPermissionRoutes(handler *gin.RouterGroup) {
+	h := handler.Group("/permissions")
+	h.Use(middleware.APIKeyHasEnabledUserActions, middleware.Auth, middleware.HasPermissions(config.Permissions.ManagePermissions))
+	{
+		h.GET("", GetAllPermissionsHandler)
+
+		byUser := h.Group("/user/:username", middleware.UserLookup)
+		{
+			byUser.GET("", GetUserPermissionsHandler)
+
+			byUser.PUT("/:permission", GrantPermissionHandler)
+			byUser.DELETE("/:permission", RevokePermissionHandler)
+		}
+	}
+}
+
+func GetAllPermissionsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	permissions, err := service.Permission.GetAll()
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	names := []string{}
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"permissions": names})
+}
+
+func GetUserPermissionsHandler(ctx *gin.Context) {
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	ctx.JSON(http.StatusOK, gin.H{"permissions": user.Permissions})
+}
+
+// Only admins can hand out or take away the admin permission
+func canChangePermission(issuer *dto.UserProfile, permission string) bool {
+	return permission != config.Permissions.Admin || issuer.HasPermissions(config.Permissions.Admin)
+}
+
+// Nobody can take away the permissions that allow them to manage permissions from themselves
+func revokesOwnAccess(issuer, user *dto.UserProfile, permission string) bool {
+	return issuer.ID == user.ID && (permission == config.Permissions.Admin || permission == config.Permissions.ManagePermissions)
+}
+
+func GrantPermissionHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+	permission := ctx.Param("permission")
+
+	if !canChangePermission(issuer, permission) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": dict.UserMissingPermissions})
+		return
+	}
+
+	if err := service.Permission.Grant(user.ID, permission); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.PermissionNotFound})
+			return
+		}
+
+		if errors.Is(err, repository.ErrPermissionAlreadyGranted) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": dict.PermissionAlreadyGranted})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryAuth,
+		Message:  fmt.Sprintf(dict.NotificationPermissionGranted, permission),
+		Type:     notification.Warning,
+		Redirect: &config.Redirects.SecurityTab,
+	}, user.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.PermissionGranted})
+}
+
+func RevokePermissionHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+	permission := ctx.Param("permission")
+
+	if !canChangePermission(issuer, permission) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": dict.UserMissingPermissions})
+		return
+	}
+
+	if revokesOwnAccess(issuer, user, permission) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": dict.PermissionCannotRevokeSelf})
+		return
+	}
+
+	if err := service.Permission.Revoke(user.ID, permission); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.PermissionNotFound})
+			return
+		}
+
+		if errors.Is(err, repository.ErrPermissionNotGranted) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": dict.PermissionNotGranted})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryAuth,
+		Message:  fmt.Sprintf(dict.NotificationPermissionRevoked, permission),
+		Type:     notification.Danger,
+		Redirect: &config.Redirects.SecurityTab,
+	}, user.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.PermissionRevoked})
+}
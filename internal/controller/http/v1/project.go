@@ -14,6 +14,9 @@ import (
 	"github.com/swibly/swibly-api/internal/service"
 	"github.com/swibly/swibly-api/internal/service/repository"
 	"github.com/swibly/swibly-api/pkg/aws"
+	"github.com/swibly/swibly-api/pkg/contentschema"
+	"github.com/swibly/swibly-api/pkg/jsonpatch"
+	"github.com/swibly/swibly-api/pkg/live"
 	"github.com/swibly/swibly-api/pkg/middleware"
 	"github.com/swibly/swibly-api/pkg/notification"
 	"github.com/swibly/swibly-api/pkg/utils"
@@ -28,6 +31,25 @@ func newProjectRoutes(handler *gin.RouterGroup) {
 		h.GET("/trash", GetTrashProjectsHandler)
 
 		h.POST("", CreateProjectHandler)
+		h.POST("/import", ImportProjectHandler)
+
+		invites := h.Group("/invites")
+		{
+			invites.GET("", GetOwnProjectInvitesHandler)
+
+			invites.POST("/:invite/accept", AcceptProjectInviteHandler)
+
+			invites.DELETE("/:invite", DeclineProjectInviteHandler)
+		}
+
+		transfers := h.Group("/transfers")
+		{
+			transfers.GET("", GetOwnProjectTransfersHandler)
+
+			transfers.POST("/:transfer/accept", AcceptProjectTransferHandler)
+
+			transfers.DELETE("/:transfer", DeclineProjectTransferHandler)
+		}
 
 		h.DELETE("/trash", DeleteTrashProjectsHandler)
 
@@ -36,16 +58,25 @@ func newProjectRoutes(handler *gin.RouterGroup) {
 			byUser.GET("", middleware.UserPrivacy(dto.UserShow{Projects: true}), GetProjectsByUserHandler)
 			byUser.GET("/favorite", middleware.UserPrivacy(dto.UserShow{Favorites: true}), GetFavoriteProjectsByUserHandler)
 		}
+
+		h.GET("/organization/:organization", middleware.OrganizationLookup, GetProjectsByOrganizationHandler)
 	}
 
 	specific := h.Group("/:id", middleware.ProjectLookup)
 	{
 		specific.GET("", middleware.ProjectIsAllowed(dto.Allow{View: true}), GetProjectHandler)
 		specific.GET("/content", middleware.ProjectIsAllowed(dto.Allow{View: true}), GetProjectContentHandler)
+		specific.GET("/export", middleware.ProjectIsAllowed(dto.Allow{View: true}), ExportProjectHandler)
 
 		specific.POST("/fork", middleware.ProjectIsAllowed(dto.Allow{View: true}), ForkProjectHandler)
+		specific.GET("/fork/diff", middleware.ProjectIsAllowed(dto.Allow{View: true}), DiffForkProjectHandler)
+		specific.POST("/fork/sync", middleware.ProjectIsAllowed(dto.Allow{Edit: true}), SyncForkProjectHandler)
+		specific.GET("/forks", middleware.ProjectIsAllowed(dto.Allow{View: true}), GetProjectForksHandler)
+
+		specific.GET("/live", middleware.ProjectIsAllowed(dto.Allow{Edit: true}), LiveProjectHandler)
 
 		specific.PUT("/content", middleware.ProjectIsAllowed(dto.Allow{Edit: true}), UpdateProjectContentHandler)
+		specific.PATCH("/content", middleware.ProjectIsAllowed(dto.Allow{Edit: true}), PatchProjectContentHandler)
 		specific.PUT("/content/clear", middleware.ProjectIsAllowed(dto.Allow{Edit: true}), ClearProjectContentHandler)
 
 		specific.PATCH("/update", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Metadata: true}}), UpdateProjectHandler)
@@ -65,6 +96,53 @@ func newProjectRoutes(handler *gin.RouterGroup) {
 			trashActions.DELETE("/force", middleware.ProjectIsAllowed(dto.Allow{Delete: true}), DeleteProjectForceHandler)
 		}
 
+		revisions := specific.Group("/revisions")
+		{
+			revisions.GET("", middleware.ProjectIsAllowed(dto.Allow{View: true}), GetProjectRevisionsHandler)
+			revisions.GET("/:revision", middleware.ProjectIsAllowed(dto.Allow{View: true}), GetProjectRevisionHandler)
+			revisions.GET("/:revision/diff", middleware.ProjectIsAllowed(dto.Allow{View: true}), DiffProjectRevisionsHandler)
+
+			revisions.POST("/:revision/restore", middleware.ProjectIsAllowed(dto.Allow{Edit: true}), RestoreProjectRevisionHandler)
+		}
+
+		shareLinks := specific.Group("/share")
+		{
+			shareLinks.GET("", middleware.ProjectIsAllowed(dto.Allow{Share: true}), GetProjectShareLinksHandler)
+
+			shareLinks.POST("", middleware.ProjectIsAllowed(dto.Allow{Share: true}), CreateProjectShareLinkHandler)
+
+			shareLinks.DELETE("/:link", middleware.ProjectIsAllowed(dto.Allow{Share: true}), RevokeProjectShareLinkHandler)
+		}
+
+		projectInvites := specific.Group("/invites")
+		{
+			projectInvites.GET("", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Users: true}}), GetProjectInvitesHandler)
+
+			projectInvites.POST("", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Users: true}}), InviteEmailToProjectHandler)
+
+			projectInvites.DELETE("/:invite", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Users: true}}), CancelProjectInviteHandler)
+		}
+
+		transfer := specific.Group("/transfer", middleware.ProjectTransferOwnership)
+		{
+			transfer.GET("", GetProjectTransferHandler)
+
+			transfer.POST("/:username", middleware.UserLookup, TransferProjectHandler)
+
+			transfer.DELETE("", CancelProjectTransferHandler)
+		}
+
+		roles := specific.Group("/roles")
+		{
+			roles.GET("", middleware.ProjectIsAllowed(dto.Allow{View: true}), GetProjectRolesHandler)
+
+			roles.POST("", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Users: true}}), CreateProjectRoleHandler)
+
+			roles.PATCH("/:role", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Users: true}}), UpdateProjectRoleHandler)
+
+			roles.DELETE("/:role", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Users: true}}), DeleteProjectRoleHandler)
+		}
+
 		assignActions := specific.Group("/assign/:username", middleware.UserLookup)
 		{
 			assignActions.PUT("", middleware.ProjectIsAllowed(dto.Allow{Manage: dto.AllowManage{Users: true}}), AssignProjectHandler)
@@ -151,33 +229,15 @@ func CreateProjectHandler(ctx *gin.Context) {
 
 	project.OwnerID = issuer.ID
 
-	if id, err := service.Project.Create(project); err != nil {
-		if errors.Is(err, aws.ErrUnsupportedFileType) {
-			log.Print(err)
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.UnsupportedFileType})
-			return
-		}
-
-		if errors.Is(err, aws.ErrUnableToDecode) {
-			log.Print(err)
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.UnableToDecodeFile})
-			return
-		}
-
-		if errors.Is(err, aws.ErrUnableToEncode) {
-			log.Print(err)
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.UnableToDecodeFile})
-			return
-		}
+	organizationID, ok := organizationFromQuery(ctx, dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin, dto.OrganizationRoleMember)
+	if !ok {
+		return
+	}
 
-		if errors.Is(err, aws.ErrFileTooLarge) {
-			log.Print(err)
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.FileTooLarge})
-			return
-		}
+	project.OrganizationID = organizationID
 
-		log.Print(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+	if id, err := service.Project.Create(project); err != nil {
+		projectCreationError(ctx, err)
 		return
 	} else {
 		service.CreateNotification(dto.CreateNotification{
@@ -187,10 +247,49 @@ func CreateProjectHandler(ctx *gin.Context) {
 			Redirect: utils.ToPtr(fmt.Sprintf(config.Redirects.Project, id)),
 		}, issuer.ID)
 
+		service.AwardXP(dict, issuer.ID, dto.XPProjectCreated, fmt.Sprintf("project:%d", id))
+		service.CheckAchievements(dict, issuer.ID, dto.MetricProjectsCreated)
+
 		ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectCreated, "project": id})
 	}
 }
 
+// Responds to errors from creating a project, which come from the content or the banner
+func projectCreationError(ctx *gin.Context, err error) {
+	dict := translations.GetTranslation(ctx)
+
+	if contentInvalid(ctx, err) {
+		return
+	}
+
+	if errors.Is(err, aws.ErrUnsupportedFileType) {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.UnsupportedFileType})
+		return
+	}
+
+	if errors.Is(err, aws.ErrUnableToDecode) {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.UnableToDecodeFile})
+		return
+	}
+
+	if errors.Is(err, aws.ErrUnableToEncode) {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.UnableToDecodeFile})
+		return
+	}
+
+	if errors.Is(err, aws.ErrFileTooLarge) {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.FileTooLarge})
+		return
+	}
+
+	log.Print(err)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+}
+
 func DeleteTrashProjectsHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
@@ -226,6 +325,36 @@ func GetProjectsByUserHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, projects)
 }
 
+// Members see every project of the organization, everyone else only the published ones
+func GetProjectsByOrganizationHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	onlyPublic := organization.Role == "" && !issuer.HasPermissions(config.Permissions.ManageProjects)
+
+	projects, err := service.Project.GetByOrganization(issuer.ID, organization.ID, onlyPublic, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, projects)
+}
+
 func GetFavoriteProjectsByUserHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
@@ -260,16 +389,88 @@ func GetProjectHandler(ctx *gin.Context) {
 func GetProjectContentHandler(ctx *gin.Context) {
 	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
 
-	content, err := service.Project.GetContent(project.ID)
+	content, version, err := service.Project.GetContent(project.ID)
 	if err != nil {
 		log.Print(err)
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	ctx.Header("ETag", utils.ETag(version))
+
+	if header := ctx.GetHeader("If-None-Match"); header != "" && utils.ETagMatches(header, version) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, content)
 }
 
+// Reads the version the client expects the content to be at from the If-Match header.
+// "*" and a missing header (when not required) both skip the check, returning 0.
+func expectedContentVersion(ctx *gin.Context, required bool) (uint, bool) {
+	dict := translations.GetTranslation(ctx)
+
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+
+	if header == "" {
+		if required {
+			ctx.JSON(http.StatusPreconditionRequired, gin.H{"error": dict.ProjectVersionRequired})
+			return 0, false
+		}
+
+		return 0, true
+	}
+
+	if header == "*" {
+		return 0, true
+	}
+
+	version, ok := utils.ParseETag(header)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectVersionInvalid})
+		return 0, false
+	}
+
+	// Versions start at 1, so "0" can never match. Letting it through would skip the check instead.
+	if version == 0 {
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"error": dict.ProjectVersionMismatch})
+		return 0, false
+	}
+
+	return version, true
+}
+
+// Replies with the path of every problem when the content does not match its schema
+func contentInvalid(ctx *gin.Context, err error) bool {
+	dict := translations.GetTranslation(ctx)
+
+	var validationErrs contentschema.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return false
+	}
+
+	ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": dict.ContentInvalid, "errors": validationErrs})
+	return true
+}
+
+// Handles the error of a content save, including the version conflicts caused by If-Match
+func contentSaveError(ctx *gin.Context, err error) {
+	dict := translations.GetTranslation(ctx)
+
+	if contentInvalid(ctx, err) {
+		return
+	}
+
+	if errors.Is(err, repository.ErrProjectVersionMismatch) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": dict.ProjectVersionMismatch})
+		return
+	}
+
+	log.Print(err)
+	ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+}
+
 func ForkProjectHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
@@ -300,6 +501,11 @@ func ForkProjectHandler(ctx *gin.Context) {
 			Redirect: utils.ToPtr(fmt.Sprintf(config.Redirects.Project, id)),
 		}, issuer.ID)
 
+		if issuer.ID != project.OwnerID {
+			service.AwardXP(dict, project.OwnerID, dto.XPProjectForked, fmt.Sprintf("project:%d:user:%d", project.ID, issuer.ID))
+			service.CheckAchievements(dict, project.OwnerID, dto.MetricForksReceived)
+		}
+
 		ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectForked, "project": id})
 	}
 }
@@ -323,6 +529,96 @@ func UnlinkProjectHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectUnlinked})
 }
 
+// Handles the errors of following the upstream of a fork
+func forkUpstreamError(ctx *gin.Context, err error) bool {
+	dict := translations.GetTranslation(ctx)
+
+	if errors.Is(err, repository.ErrProjectIsNotAFork) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectIsNotAFork})
+		return true
+	}
+
+	if errors.Is(err, repository.ErrUpstreamNotPublic) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.UpstreamNotPublic})
+		return true
+	}
+
+	return false
+}
+
+func DiffForkProjectHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	diff, err := service.Project.DiffFork(project.ID)
+	if err != nil {
+		if forkUpstreamError(ctx, err) {
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, diff)
+}
+
+func SyncForkProjectHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	expectedVersion, ok := expectedContentVersion(ctx, false)
+	if !ok {
+		return
+	}
+
+	version, skipped, err := service.Project.SyncFork(project.ID, issuer.ID, expectedVersion)
+	if err != nil {
+		if forkUpstreamError(ctx, err) {
+			return
+		}
+
+		contentSaveError(ctx, err)
+		return
+	}
+
+	live.Default.Reload(project.ID)
+
+	ctx.Header("ETag", utils.ETag(version))
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectForkSynced, "skipped": skipped})
+}
+
+func GetProjectForksHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	forks, err := service.Project.GetForks(issuer.ID, project.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, forks)
+}
+
 func LeaveProjectHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
@@ -335,14 +631,22 @@ func LeaveProjectHandler(ctx *gin.Context) {
 		return
 	}
 
+	live.Default.Recheck(project.ID)
+
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectUnassignedUser})
 }
 
 func UpdateProjectContentHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
 	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
 
+	expectedVersion, ok := expectedContentVersion(ctx, true)
+	if !ok {
+		return
+	}
+
 	var body any
 	if err := ctx.ShouldBindJSON(&body); err != nil {
 		log.Print(err)
@@ -350,26 +654,97 @@ func UpdateProjectContentHandler(ctx *gin.Context) {
 		return
 	}
 
-	if err := service.Project.SaveContent(project.ID, body); err != nil {
-		log.Print(err)
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+	version, err := service.Project.SaveContent(project.ID, issuer.ID, expectedVersion, body)
+	if err != nil {
+		contentSaveError(ctx, err)
+		return
+	}
+
+	live.Default.Reload(project.ID)
+
+	ctx.Header("ETag", utils.ETag(version))
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectUpdated})
+}
+
+// Accepts either a JSON Patch (application/json-patch+json) or a JSON Merge Patch (application/merge-patch+json).
+// Unlike PUT, If-Match is optional here, since patches only touch what they mention.
+func PatchProjectContentHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	expectedVersion, ok := expectedContentVersion(ctx, false)
+	if !ok {
+		return
+	}
+
+	var (
+		version uint
+		err     error
+	)
+
+	switch ctx.ContentType() {
+	case "application/json-patch+json":
+		var ops []jsonpatch.Operation
+		if err := ctx.ShouldBindJSON(&ops); err != nil {
+			log.Print(err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+			return
+		}
+
+		version, err = service.Project.PatchContent(project.ID, issuer.ID, expectedVersion, ops)
+	case "application/merge-patch+json":
+		var patch any
+		if err := ctx.ShouldBindJSON(&patch); err != nil {
+			log.Print(err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+			return
+		}
+
+		version, err = service.Project.MergeContent(project.ID, issuer.ID, expectedVersion, patch)
+	default:
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": dict.ProjectPatchUnsupportedType})
+		return
+	}
+
+	if err != nil {
+		var patchErr *jsonpatch.Error
+		if errors.As(err, &patchErr) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf(dict.ProjectPatchInvalid, patchErr.Operation.Path)})
+			return
+		}
+
+		contentSaveError(ctx, err)
 		return
 	}
 
+	live.Default.Reload(project.ID)
+
+	ctx.Header("ETag", utils.ETag(version))
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectUpdated})
 }
 
 func ClearProjectContentHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
 	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
 
-	if err := service.Project.ClearContent(project.ID); err != nil {
-		log.Print(err)
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+	expectedVersion, ok := expectedContentVersion(ctx, false)
+	if !ok {
+		return
+	}
+
+	version, err := service.Project.ClearContent(project.ID, issuer.ID, expectedVersion)
+	if err != nil {
+		contentSaveError(ctx, err)
 		return
 	}
 
+	live.Default.Reload(project.ID)
+
+	ctx.Header("ETag", utils.ETag(version))
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectUpdated})
 }
 
@@ -464,6 +839,9 @@ func PublishProjectHandler(ctx *gin.Context) {
 		Type:    notification.Warning,
 	}, ids...)
 
+	service.AwardXP(dict, project.OwnerID, dto.XPProjectPublished, fmt.Sprintf("project:%d", project.ID))
+	service.CheckAchievements(dict, project.OwnerID, dto.MetricProjectsPublished)
+
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectPublished})
 }
 
@@ -509,6 +887,11 @@ func FavoriteProjectHandler(ctx *gin.Context) {
 		Type:    notification.Information,
 	}, project.OwnerID)
 
+	if issuer.ID != project.OwnerID {
+		service.AwardXP(dict, project.OwnerID, dto.XPProjectFavorited, fmt.Sprintf("project:%d:user:%d", project.ID, issuer.ID))
+		service.CheckAchievements(dict, project.OwnerID, dto.MetricFavoritesReceived)
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectFavorited})
 }
 
@@ -605,6 +988,7 @@ func DeleteProjectForceHandler(ctx *gin.Context) {
 func AssignProjectHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
 	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
 	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
 
@@ -625,6 +1009,7 @@ func AssignProjectHandler(ctx *gin.Context) {
 	}
 
 	allowList := &dto.ProjectAssign{
+		Role:           body.Role,
 		View:           body.View,
 		Edit:           body.Edit,
 		Delete:         body.Delete,
@@ -634,11 +1019,48 @@ func AssignProjectHandler(ctx *gin.Context) {
 		ManageMetadata: body.ManageMetadata,
 	}
 
+	if !resolveProjectRole(ctx, project.ID, allowList) {
+		return
+	}
+
 	if allowList.IsEmpty() {
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": dict.ProjectEmptyAssign})
 		return
 	}
 
+	if project.OrganizationID == nil && user.ID == project.OwnerID {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": dict.ProjectCannotAssignOwner})
+		return
+	}
+
+	// People who are not part of the project yet have to accept an invite first,
+	// only the permissions of current members are changed right away
+	isMember := false
+	for _, allowedUser := range project.AllowedUsers {
+		if allowedUser.ID == user.ID {
+			isMember = true
+			break
+		}
+	}
+
+	if !isMember {
+		if err := service.ProjectInvite.Invite(project.ID, issuer.ID, user.ID, allowList); err != nil {
+			log.Print(err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+			return
+		}
+
+		service.CreateNotification(dto.CreateNotification{
+			Title:    dict.CategoryProject,
+			Message:  fmt.Sprintf(dict.NotificationInvitedYouToProject, issuer.FirstName+" "+issuer.LastName, project.Name),
+			Type:     notification.Information,
+			Redirect: utils.ToPtr(config.Redirects.Invites),
+		}, user.ID)
+
+		ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectInviteSent})
+		return
+	}
+
 	if err := service.Project.Assign(user.ID, project.ID, allowList); err != nil {
 		if errors.Is(err, repository.ErrCannotAssignOwner) {
 			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": dict.ProjectCannotAssignOwner})
@@ -650,6 +1072,8 @@ func AssignProjectHandler(ctx *gin.Context) {
 		return
 	}
 
+	live.Default.Recheck(project.ID)
+
 	service.CreateNotification(dto.CreateNotification{
 		Title:   dict.CategoryProject,
 		Message: fmt.Sprintf(dict.NotificationAddedUserToProject, user.FirstName+user.LastName, project.Name),
@@ -683,6 +1107,8 @@ func UnassignProjectHandler(ctx *gin.Context) {
 		return
 	}
 
+	live.Default.Recheck(project.ID)
+
 	service.CreateNotification(dto.CreateNotification{
 		Title:   dict.CategoryProject,
 		Message: fmt.Sprintf(dict.NotificationRemovedUserFromProject, user.FirstName+user.LastName, project.Name),
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/pkg/live"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+	"gorm.io/gorm"
+)
+
+func GetProjectRevisionsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	revisions, err := service.Project.GetRevisions(project.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revisions)
+}
+
+func GetProjectRevisionHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	revisionID, err := strconv.ParseUint(ctx.Param("revision"), 10, 64)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectRevisionInvalid})
+		return
+	}
+
+	revision, err := service.Project.GetRevision(project.ID, uint(revisionID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectRevisionNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, revision)
+}
+
+// Returns the JSON Patch (RFC 6902) that turns the revision into the one given by the `to` query param,
+// or into the current content of the project when `to` is omitted
+func DiffProjectRevisionsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	fromID, err := strconv.ParseUint(ctx.Param("revision"), 10, 64)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectRevisionInvalid})
+		return
+	}
+
+	var toID uint64
+
+	if ctx.Query("to") != "" {
+		if toID, err = strconv.ParseUint(ctx.Query("to"), 10, 64); err != nil {
+			log.Print(err)
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectRevisionInvalid})
+			return
+		}
+	}
+
+	operations, err := service.Project.DiffRevisions(project.ID, uint(fromID), uint(toID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectRevisionNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, operations)
+}
+
+func RestoreProjectRevisionHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	revisionID, err := strconv.ParseUint(ctx.Param("revision"), 10, 64)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectRevisionInvalid})
+		return
+	}
+
+	expectedVersion, ok := expectedContentVersion(ctx, false)
+	if !ok {
+		return
+	}
+
+	version, err := service.Project.RestoreRevision(project.ID, uint(revisionID), issuer.ID, expectedVersion)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectRevisionNotFound})
+			return
+		}
+
+		contentSaveError(ctx, err)
+		return
+	}
+
+	live.Default.Reload(project.ID)
+
+	ctx.Header("ETag", utils.ETag(version))
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectRevisionRestored})
+}
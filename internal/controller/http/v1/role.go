@@ -0,0 +1,134 @@
+package v1
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+)
+
+// Fills the assignment in with the permissions of the role it names. Aborts and returns false
+// when the role does not exist.
+func resolveProjectRole(ctx *gin.Context, projectID uint, assign *dto.ProjectAssign) bool {
+	dict := translations.GetTranslation(ctx)
+
+	if err := service.ProjectRole.Resolve(projectID, assign); err != nil {
+		if errors.Is(err, repository.ErrProjectRoleNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.ProjectRoleNotFound})
+			return false
+		}
+
+		log.Print(err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return false
+	}
+
+	return true
+}
+
+func projectRoleError(ctx *gin.Context, dict translations.Translation, err error) {
+	switch {
+	case errors.Is(err, repository.ErrProjectRoleNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectRoleNotFound})
+	case errors.Is(err, repository.ErrProjectRoleNameTaken):
+		ctx.JSON(http.StatusConflict, gin.H{"error": dict.ProjectRoleNameTaken})
+	case errors.Is(err, repository.ErrProjectRoleIsPreset):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectRoleIsPreset})
+	case errors.Is(err, repository.ErrProjectRoleEmpty):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectRoleEmpty})
+	default:
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+	}
+}
+
+func GetProjectRolesHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	roles, err := service.ProjectRole.GetByProject(project.ID)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, roles)
+}
+
+func CreateProjectRoleHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	var body dto.ProjectRoleCreation
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if err := service.ProjectRole.Create(project.ID, &body); err != nil {
+		projectRoleError(ctx, dict, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectRoleCreated})
+}
+
+// Every collaborator holding the role gets the new permissions right away
+func UpdateProjectRoleHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	var body dto.ProjectRoleUpdate
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if err := service.ProjectRole.Update(project.ID, ctx.Param("role"), &body); err != nil {
+		projectRoleError(ctx, dict, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectRoleUpdated})
+}
+
+func DeleteProjectRoleHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	if err := service.ProjectRole.Delete(project.ID, ctx.Param("role")); err != nil {
+		projectRoleError(ctx, dict, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectRoleDeleted})
+}
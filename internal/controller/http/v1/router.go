@@ -9,9 +9,14 @@ func NewRouter(handler *gin.Engine) {
 	{
 		newAuthRoutes(g)
 		newUserRoutes(g)
+		newOrganizationRoutes(g)
 		newSearchRoutes(g)
 		newProjectRoutes(g)
 		newComponentRoutes(g)
+		newWalletRoutes(g)
+		newGrantRoutes(g)
 		newNotificationRoutes(g)
+		newAPIKeyRoutes(g)
+		newPermissionRoutes(g)
 	}
 }
@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+)
+
+func GetProjectShareLinksHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	links, err := service.ProjectShare.GetByProject(project.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, links)
+}
+
+// The token is only part of this response. Links can never grant more than the issuer has,
+// so edit links also require the Edit permission.
+func CreateProjectShareLinkHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	var body dto.ProjectShareLinkCreation
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if body.Scope == dto.ShareScopeEdit && !middleware.HasProjectPermissions(ctx, dto.Allow{Edit: true}) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": dict.ProjectMissingPermissions})
+		return
+	}
+
+	link, token, err := service.ProjectShare.Create(project.ID, issuer.ID, &body)
+	if err != nil {
+		if errors.Is(err, repository.ErrShareLinkExpiry) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectShareLinkExpiry})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectShareLinkCreated, "link": link, "token": token})
+}
+
+func RevokeProjectShareLinkHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	linkID, err := strconv.ParseUint(ctx.Param("link"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectShareLinkNotFound})
+		return
+	}
+
+	if err := service.ProjectShare.Revoke(project.ID, uint(linkID)); err != nil {
+		if errors.Is(err, repository.ErrShareLinkNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectShareLinkNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectShareLinkRevoked})
+}
@@ -0,0 +1,221 @@
+package v1
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/pkg/ratelimit"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func LoginTOTPHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	var body dto.TOTPLogin
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	claims, err := utils.GetClaimsJWT(body.Challenge)
+	if err != nil || claims.Audience != utils.ChallengeAudience {
+		log.Print(err)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.TOTPInvalidChallenge})
+		return
+	}
+
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.TOTPInvalidChallenge})
+		return
+	}
+
+	account := "2fa:" + claims.Subject
+
+	if until, err := ratelimit.LockedUntil(account); err != nil {
+		log.Print(err)
+	} else if !until.IsZero() {
+		middleware.AbortRateLimited(ctx, until, dict.AccountLocked)
+		return
+	}
+
+	if err := service.TOTP.Verify(uint(id), body.Code); err != nil {
+		if errors.Is(err, repository.ErrTOTPInvalidCode) || errors.Is(err, repository.ErrTOTPNotEnabled) {
+			if until, err := ratelimit.RegisterFailure(account); err != nil {
+				log.Print(err)
+			} else if !until.IsZero() {
+				middleware.AbortRateLimited(ctx, until, dict.AccountLocked)
+				return
+			}
+
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.TOTPInvalidCode})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	if err := ratelimit.ClearFailures(account); err != nil {
+		log.Print(err)
+	}
+
+	completeLogin(ctx, uint(id))
+}
+
+func EnrollTOTPHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	enrollment, err := service.TOTP.Enroll(issuer)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPAlreadyEnabled) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": dict.TOTPAlreadyEnabled})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, enrollment)
+}
+
+func ConfirmTOTPHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	var body dto.TOTPConfirm
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	codes, err := service.TOTP.Confirm(issuer.ID, body.Code)
+	if err != nil {
+		if errors.Is(err, repository.ErrTOTPInvalidCode) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.TOTPInvalidCode})
+			return
+		}
+
+		if errors.Is(err, repository.ErrTOTPNotEnabled) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.TOTPNotEnabled})
+			return
+		}
+
+		if errors.Is(err, repository.ErrTOTPAlreadyEnabled) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": dict.TOTPAlreadyEnabled})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryAuth,
+		Message:  dict.NotificationTOTPEnabled,
+		Type:     notification.Warning,
+		Redirect: &config.Redirects.SecurityTab,
+	}, issuer.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.TOTPEnabled, "recovery_codes": codes})
+}
+
+func DisableTOTPHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	var body dto.TOTPDisable
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	user, err := service.User.UnsafeGetByID(issuer.ID)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(body.Password)); err != nil {
+		log.Print(err)
+
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": dict.AuthWrongCredentials})
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	if err := service.TOTP.Disable(issuer.ID); err != nil {
+		if errors.Is(err, repository.ErrTOTPNotEnabled) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.TOTPNotEnabled})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryAuth,
+		Message:  dict.NotificationTOTPDisabled,
+		Type:     notification.Danger,
+		Redirect: &config.Redirects.SecurityTab,
+	}, issuer.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.TOTPDisabled})
+}
@@ -0,0 +1,372 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/live"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+)
+
+// Whether the previous owner should keep access once the transfer is accepted, from ?keep_access=
+func transferKeepsAccess(ctx *gin.Context) bool {
+	keepAccess := strings.ToLower(ctx.Query("keep_access"))
+	return keepAccess == "true" || keepAccess == "t" || keepAccess == "1"
+}
+
+// The owner at the time the transfer was answered and whoever started it, without repeating anyone
+func transferNotifyIDs(ownerID uint, issuerID *uint) []uint {
+	ids := []uint{ownerID}
+
+	if issuerID != nil && *issuerID != ownerID {
+		ids = append(ids, *issuerID)
+	}
+
+	return ids
+}
+
+func GetProjectTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	transfer, err := service.ProjectTransfer.Get(project.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+func TransferProjectHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	if user.ID == project.OwnerID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ProjectTransferToOwner})
+		return
+	}
+
+	if err := service.ProjectTransfer.Start(project.ID, issuer.ID, user.ID, transferKeepsAccess(ctx)); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryProject,
+		Message:  fmt.Sprintf(dict.NotificationTransferProjectToYou, issuer.FirstName+" "+issuer.LastName, project.Name),
+		Type:     notification.Information,
+		Redirect: utils.ToPtr(config.Redirects.Transfers),
+	}, user.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectTransferSent})
+}
+
+func CancelProjectTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+
+	if err := service.ProjectTransfer.Cancel(project.ID); err != nil {
+		if errors.Is(err, repository.ErrProjectTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectTransferCanceled})
+}
+
+// The project transfers waiting for the issuer to accept or decline them
+func GetOwnProjectTransfersHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	transfers, err := service.ProjectTransfer.GetByRecipient(issuer.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+func AcceptProjectTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	transferID, err := strconv.ParseUint(ctx.Param("transfer"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectTransferNotFound})
+		return
+	}
+
+	transfer, err := service.ProjectTransfer.Accept(issuer.ID, uint(transferID))
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	live.Default.Recheck(transfer.ProjectID)
+
+	redirect := utils.ToPtr(fmt.Sprintf(config.Redirects.Project, transfer.ProjectID))
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryProject,
+		Message:  fmt.Sprintf(dict.NotificationUserAcceptedProjectTransfer, issuer.FirstName+" "+issuer.LastName, transfer.ProjectName),
+		Type:     notification.Information,
+		Redirect: redirect,
+	}, transferNotifyIDs(transfer.OwnerID, transfer.IssuerID)...)
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryProject,
+		Message:  fmt.Sprintf(dict.NotificationYouOwnProject, transfer.ProjectName),
+		Type:     notification.Information,
+		Redirect: redirect,
+	}, issuer.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectTransferAccepted, "project": transfer.ProjectID})
+}
+
+func DeclineProjectTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	transferID, err := strconv.ParseUint(ctx.Param("transfer"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectTransferNotFound})
+		return
+	}
+
+	transfer, err := service.ProjectTransfer.Decline(issuer.ID, uint(transferID))
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ProjectTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryProject,
+		Message:  fmt.Sprintf(dict.NotificationUserDeclinedProjectTransfer, issuer.FirstName+" "+issuer.LastName, transfer.ProjectName),
+		Type:     notification.Information,
+		Redirect: utils.ToPtr(fmt.Sprintf(config.Redirects.Project, transfer.ProjectID)),
+	}, transferNotifyIDs(transfer.OwnerID, transfer.IssuerID)...)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ProjectTransferDeclined})
+}
+
+func GetComponentTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	component := ctx.Keys["component_lookup"].(*dto.ComponentInfo)
+
+	transfer, err := service.ComponentTransfer.Get(component.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrComponentTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ComponentTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+func TransferComponentHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	component := ctx.Keys["component_lookup"].(*dto.ComponentInfo)
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	if user.ID == component.OwnerID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.ComponentTransferToOwner})
+		return
+	}
+
+	if err := service.ComponentTransfer.Start(component.ID, issuer.ID, user.ID, transferKeepsAccess(ctx)); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:    dict.CategoryComponent,
+		Message:  fmt.Sprintf(dict.NotificationTransferComponentToYou, issuer.FirstName+" "+issuer.LastName, component.Name),
+		Type:     notification.Information,
+		Redirect: utils.ToPtr(config.Redirects.Transfers),
+	}, user.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentTransferSent})
+}
+
+func CancelComponentTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	component := ctx.Keys["component_lookup"].(*dto.ComponentInfo)
+
+	if err := service.ComponentTransfer.Cancel(component.ID); err != nil {
+		if errors.Is(err, repository.ErrComponentTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ComponentTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentTransferCanceled})
+}
+
+// The component transfers waiting for the issuer to accept or decline them
+func GetOwnComponentTransfersHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	transfers, err := service.ComponentTransfer.GetByRecipient(issuer.ID, page, perPage)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+func AcceptComponentTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	transferID, err := strconv.ParseUint(ctx.Param("transfer"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ComponentTransferNotFound})
+		return
+	}
+
+	transfer, err := service.ComponentTransfer.Accept(issuer.ID, uint(transferID))
+	if err != nil {
+		if errors.Is(err, repository.ErrComponentTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ComponentTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:   dict.CategoryComponent,
+		Message: fmt.Sprintf(dict.NotificationUserAcceptedComponentTransfer, issuer.FirstName+" "+issuer.LastName, transfer.ComponentName),
+		Type:    notification.Information,
+	}, transferNotifyIDs(transfer.OwnerID, transfer.IssuerID)...)
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:   dict.CategoryComponent,
+		Message: fmt.Sprintf(dict.NotificationYouOwnComponent, transfer.ComponentName),
+		Type:    notification.Information,
+	}, issuer.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentTransferAccepted, "component": transfer.ComponentID})
+}
+
+func DeclineComponentTransferHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	transferID, err := strconv.ParseUint(ctx.Param("transfer"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ComponentTransferNotFound})
+		return
+	}
+
+	transfer, err := service.ComponentTransfer.Decline(issuer.ID, uint(transferID))
+	if err != nil {
+		if errors.Is(err, repository.ErrComponentTransferNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": dict.ComponentTransferNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	service.CreateNotification(dto.CreateNotification{
+		Title:   dict.CategoryComponent,
+		Message: fmt.Sprintf(dict.NotificationUserDeclinedComponentTransfer, issuer.FirstName+" "+issuer.LastName, transfer.ComponentName),
+		Type:    notification.Information,
+	}, transferNotifyIDs(transfer.OwnerID, transfer.IssuerID)...)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.ComponentTransferDeclined})
+}
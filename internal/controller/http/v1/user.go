@@ -22,6 +22,7 @@ func newUserRoutes(handler *gin.RouterGroup) {
 		h.GET("/profile", middleware.UserPrivacy(dto.UserShow{Profile: true}), GetProfileHandler)
 		h.GET("/followers", middleware.UserPrivacy(dto.UserShow{Followers: true}), GetFollowersHandler)
 		h.GET("/following", middleware.UserPrivacy(dto.UserShow{Following: true}), GetFollowingHandler)
+		h.GET("/achievements", middleware.UserPrivacy(dto.UserShow{Achievements: true}), GetAchievementsHandler)
 	}
 
 	actions := h.Group("", middleware.APIKeyHasEnabledUserActions)
@@ -30,6 +31,10 @@ func newUserRoutes(handler *gin.RouterGroup) {
 		actions.POST("/unfollow", UnfollowUserHandler)
 
 		actions.GET("/amifollowing", IsFollowingHandler)
+
+		actions.PATCH("/verified", middleware.HasPermissions(config.Permissions.ManageUser), SetUserVerifiedHandler)
+
+		actions.POST("/grant", GrantUserHandler)
 	}
 }
 
@@ -65,6 +70,22 @@ func GetFollowersHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, pagination)
 }
 
+// Every achievement, with when the user unlocked it and how close they are to the ones they did not
+func GetAchievementsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	achievements, err := service.Achievement.GetByUser(user.ID)
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, achievements)
+}
+
 func GetFollowingHandler(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
@@ -126,6 +147,9 @@ func FollowUserHandler(ctx *gin.Context) {
 		Redirect: utils.ToPtr(fmt.Sprintf(config.Redirects.Profile, issuer.Username)),
 	}, receiver.ID)
 
+	service.AwardXP(dict, receiver.ID, dto.XPFollowerGained, fmt.Sprintf("user:%d", issuer.ID))
+	service.CheckAchievements(dict, receiver.ID, dto.MetricFollowers)
+
 	ctx.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf(dict.UserFollowingStarted, receiver.Username)})
 }
 
@@ -183,3 +207,33 @@ func IsFollowingHandler(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf(dict.UserFollowingNot, receiver.Username)})
 	}
 }
+
+func SetUserVerifiedHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	user := ctx.Keys["user_lookup"].(*dto.UserProfile)
+
+	var body dto.UserVerify
+
+	if err := ctx.BindJSON(&body); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": dict.InvalidBody})
+		return
+	}
+
+	if errs := utils.ValidateStruct(&body); errs != nil {
+		err := utils.ValidateErrorMessage(ctx, errs[0])
+
+		log.Print(err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": gin.H{err.Param: err.Message}})
+		return
+	}
+
+	if err := service.User.Update(user.ID, &dto.UserUpdate{Verified: body.Verified}); err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": dict.AuthUserUpdated})
+}
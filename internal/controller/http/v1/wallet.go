@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/translations"
+)
+
+func newWalletRoutes(handler *gin.RouterGroup) {
+	h := handler.Group("/wallet", middleware.APIKeyHasEnabledUserFetch, middleware.Auth)
+	{
+		h.GET("/transactions", GetWalletTransactionsHandler)
+
+		h.GET("/reconcile", middleware.HasPermissions(config.Permissions.ManageStore), ReconcileWalletsHandler)
+	}
+}
+
+// History of the wallet of the issuer, newest first. Owners and admins of an organization can see the
+// history of its wallet with ?organization=
+func GetWalletTransactionsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	organizationID, ok := organizationFromQuery(ctx, dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin)
+	if !ok {
+		return
+	}
+
+	page := 1
+	perPage := 10
+
+	if i, e := strconv.Atoi(ctx.Query("page")); e == nil && ctx.Query("page") != "" {
+		page = i
+	}
+
+	if i, e := strconv.Atoi(ctx.Query("perpage")); e == nil && ctx.Query("perpage") != "" {
+		perPage = i
+	}
+
+	var (
+		transactions *dto.Pagination[dto.ArkhoinTransactionInfo]
+		err          error
+	)
+
+	if organizationID != nil {
+		transactions, err = service.Wallet.GetByOrganization(*organizationID, page, perPage)
+	} else {
+		transactions, err = service.Wallet.GetByUser(issuer.ID, page, perPage)
+	}
+
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transactions)
+}
+
+// Lists the wallets whose balance does not match the sum of their ledger entries
+func ReconcileWalletsHandler(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	reconciliation, err := service.Wallet.Reconcile()
+	if err != nil {
+		log.Print(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reconciliation)
+}
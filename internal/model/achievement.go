@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Definitions come from config/achievements.yaml, see db.Load
+type Achievement struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Key         string `gorm:"uniqueIndex;not null"`
+	Name        string `gorm:"not null"`
+	Description string
+
+	Metric    string `gorm:"index;not null"`
+	Threshold uint64 `gorm:"not null"`
+
+	ArkhoinReward uint64 `gorm:"default:0"`
+	XPReward      uint64 `gorm:"default:0"`
+}
+
+type UserAchievement struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time // When it was unlocked
+
+	UserID        uint `gorm:"uniqueIndex:idx_user_achievement;not null;constraint:OnDelete:CASCADE;"`
+	AchievementID uint `gorm:"uniqueIndex:idx_user_achievement;not null;constraint:OnDelete:CASCADE;"`
+}
@@ -7,8 +7,10 @@ type APIKey struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	Key   string `json:"key"`
-	Owner string `json:"owner"`
+	// SHA-256 digest of the secret, the secret itself is only shown when the key is created or regenerated
+	Key    string `json:"-"      gorm:"uniqueIndex"`
+	Prefix string `json:"prefix" gorm:"uniqueIndex"`
+	Owner  string `json:"owner"`
 
 	EnabledKeyManage   int `json:"enabled_key_manage"   gorm:"default:-1"`
 	EnabledAuth        int `json:"enabled_auth"         gorm:"default:-1"`
@@ -19,4 +21,13 @@ type APIKey struct {
 
 	TimesUsed uint `json:"times_used" gorm:"default:0"`
 	MaxUsage  uint `json:"max_usage"  gorm:"default:0"`
+
+	// Requests allowed per window, 0 means unlimited
+	RateLimitMinute uint `json:"rate_limit_minute" gorm:"default:0"`
+	RateLimitHour   uint `json:"rate_limit_hour"   gorm:"default:0"`
+	RateLimitDay    uint `json:"rate_limit_day"    gorm:"default:0"`
+
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
 }
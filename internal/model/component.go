@@ -28,6 +28,9 @@ type ComponentOwner struct {
 
 	ComponentID uint  `gorm:"index;unique;not null;constraint:OnDelete:CASCADE;"`
 	UserID      *uint `gorm:"index;constraint:OnDelete:CASCADE;"`
+
+	// Set when the component belongs to an organization, sales then go to its wallet
+	OrganizationID *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
 }
 
 type ComponentPublication struct {
@@ -47,4 +50,23 @@ type ComponentHolder struct {
 	UserID      uint `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
 
 	PricePaid int `gorm:"default:0"`
+
+	// The organization whose wallet paid for the component, refunds go back to it
+	OrganizationID *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+}
+
+// A pending change of the ComponentOwner, only applied once the recipient accepts it
+type ComponentTransfer struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ComponentID uint  `gorm:"unique;index;not null;constraint:OnDelete:CASCADE;"`
+	IssuerID    *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+	RecipientID uint  `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
+
+	// Whether the previous owner keeps holding the component, as if they had bought it for free
+	KeepAccess bool `gorm:"not null;default:false"`
+
+	ExpiresAt time.Time `gorm:"not null"`
 }
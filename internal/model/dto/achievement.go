@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// What achievements count, as named in config/achievements.yaml
+const (
+	MetricProjectsCreated   = "projects_created"
+	MetricProjectsPublished = "projects_published"
+	MetricFavoritesReceived = "favorites_received"
+	MetricForksReceived     = "forks_received"
+	MetricComponentsSold    = "components_sold"
+	MetricFollowers         = "followers"
+)
+
+type AchievementInfo struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	Metric    string `json:"metric"`
+	Threshold uint64 `json:"threshold"`
+	Progress  uint64 `json:"progress"` // Capped at the threshold
+
+	ArkhoinReward uint64 `json:"arkhoin_reward"`
+	XPReward      uint64 `json:"xp_reward"`
+
+	UnlockedAt *time.Time `json:"unlocked_at"`
+}
@@ -1,5 +1,18 @@
 package dto
 
+import "time"
+
+type CreateAPIKey struct {
+	Owner    string `validate:"omitempty" json:"owner"`
+	MaxUsage uint   `validate:"omitempty" json:"max_usage"`
+
+	RateLimitMinute uint `validate:"omitempty" json:"rate_limit_minute"`
+	RateLimitHour   uint `validate:"omitempty" json:"rate_limit_hour"`
+	RateLimitDay    uint `validate:"omitempty" json:"rate_limit_day"`
+
+	ExpiresAt *time.Time `validate:"omitempty" json:"expires_at"`
+}
+
 type UpdateAPIKey struct {
 	Owner string `validate:"omitempty" json:"owner"`
 
@@ -8,14 +21,26 @@ type UpdateAPIKey struct {
 	EnabledSearch      int `validate:"omitempty,mustbenumericalboolean" json:"enabled_search"`
 	EnabledUserFetch   int `validate:"omitempty,mustbenumericalboolean" json:"enabled_user_fetch"`
 	EnabledUserActions int `validate:"omitempty,mustbenumericalboolean" json:"enabled_user_actions"`
+	EnabledProjects    int `validate:"omitempty,mustbenumericalboolean" json:"enabled_projects"`
 
 	TimesUsed uint `validate:"omitempty" json:"times_used"`
 	MaxUsage  uint `validate:"omitempty" json:"max_usage"`
+
+	RateLimitMinute uint `validate:"omitempty" json:"rate_limit_minute"`
+	RateLimitHour   uint `validate:"omitempty" json:"rate_limit_hour"`
+	RateLimitDay    uint `validate:"omitempty" json:"rate_limit_day"`
+
+	ExpiresAt *time.Time `validate:"omitempty" json:"expires_at"`
 }
 
 type ReadAPIKey struct {
-	Key   string `json:"key"`
-	Owner string `json:"owner"`
+	ID     uint   `json:"id"`
+	Key    string `json:"-"` // Hash of the secret
+	Prefix string `json:"prefix"`
+	Owner  string `json:"owner"`
+
+	// Only filled right after the key is created
+	Secret string `json:"secret,omitempty" gorm:"-"`
 
 	EnabledKeyManage   int `json:"enabled_key_manage"`
 	EnabledAuth        int `json:"enabled_auth"`
@@ -26,4 +51,16 @@ type ReadAPIKey struct {
 
 	TimesUsed uint `json:"times_used"`
 	MaxUsage  uint `json:"max_usage"`
+
+	RateLimitMinute uint `json:"rate_limit_minute"`
+	RateLimitHour   uint `json:"rate_limit_hour"`
+	RateLimitDay    uint `json:"rate_limit_day"`
+
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+}
+
+func (k *ReadAPIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && k.ExpiresAt.Before(time.Now())
 }
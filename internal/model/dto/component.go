@@ -16,7 +16,8 @@ type ComponentCreation struct {
 	Price  int `validate:"omitempty" json:"price"`
 	Budget int `validate:"omitempty" json:"budget"`
 
-	OwnerID uint `json:"-"` // Set using JWT
+	OwnerID        uint  `json:"-"` // Set using JWT
+	OrganizationID *uint `json:"-"` // Set in an URL query param
 
 	Public bool `json:"-"` // Set in an URL query param
 }
@@ -51,6 +52,9 @@ type ComponentInfoJSON struct {
 	OwnerProfilePicture string `json:"owner_pfp"`
 	OwnerVerified       bool   `json:"owner_verified"`
 
+	OrganizationID   *uint  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+
 	Budget    int  `json:"budget"`
 	Price     int  `json:"price"`
 	PaidPrice *int `json:"paid_price"`
@@ -81,6 +85,9 @@ type ComponentInfo struct {
 	OwnerProfilePicture string `json:"owner_pfp"`
 	OwnerVerified       bool   `json:"owner_verified"`
 
+	OrganizationID   *uint  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+
 	Budget    int  `json:"budget"`
 	Price     int  `json:"price"`
 	PaidPrice *int `json:"paid_price"`
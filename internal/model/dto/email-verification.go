@@ -0,0 +1,5 @@
+package dto
+
+type UserVerify struct {
+	Verified *bool `validate:"required" json:"verified"`
+}
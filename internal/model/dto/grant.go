@@ -0,0 +1,66 @@
+package dto
+
+import "time"
+
+const (
+	GrantArkhoin = "arkhoin"
+	GrantXP      = "xp"
+)
+
+type GrantCreation struct {
+	Currency string `validate:"required,oneof=arkhoin xp"                json:"currency"`
+	Amount   int64  `validate:"required,min=-1000000000,max=1000000000" json:"amount"` // Negative deducts
+	Reason   string `validate:"required,min=3,max=255"                   json:"reason"`
+}
+
+type BulkGrantCreation struct {
+	GrantCreation
+
+	Usernames []string `validate:"required,min=1,max=1000,dive,required" json:"usernames"`
+}
+
+type AirdropCreation struct {
+	Currency string `validate:"required,oneof=arkhoin xp"      json:"currency"`
+	Amount   int64  `validate:"required,min=1,max=1000000000" json:"amount"`
+	Reason   string `validate:"required,min=3,max=255"         json:"reason"`
+
+	Usernames []string `validate:"required_without=Everyone,max=1000,dive,required" json:"usernames"`
+	Everyone  bool     `json:"everyone"`
+
+	ScheduledAt time.Time `validate:"required" json:"scheduled_at"`
+}
+
+type GrantInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	IssuerID       *uint  `json:"issuer_id"`
+	IssuerUsername string `json:"issuer_username"`
+
+	UserID   *uint  `json:"user_id"`
+	Username string `json:"username"`
+
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+	Reason   string `json:"reason"`
+
+	AirdropID *uint `json:"airdrop_id"`
+}
+
+type AirdropInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	IssuerID       *uint  `json:"issuer_id"`
+	IssuerUsername string `json:"issuer_username"`
+
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+	Reason   string `json:"reason"`
+
+	Everyone   bool  `json:"everyone"`
+	Recipients int64 `json:"recipients"`
+
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	ExecutedAt  *time.Time `json:"executed_at"`
+}
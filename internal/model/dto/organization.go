@@ -0,0 +1,81 @@
+package dto
+
+import "time"
+
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+type OrganizationCreation struct {
+	Name        string `validate:"required,min=3,max=32,username" json:"name"`
+	DisplayName string `validate:"required,min=3,max=64"          json:"display_name"`
+	Bio         string `validate:"omitempty,max=480"              json:"bio"`
+}
+
+type OrganizationUpdate struct {
+	Name        *string `validate:"omitempty,min=3,max=32,username" json:"name"`
+	DisplayName *string `validate:"omitempty,min=3,max=64"          json:"display_name"`
+	Bio         *string `validate:"omitempty,max=480"               json:"bio"`
+}
+
+type OrganizationMemberAssign struct {
+	Role string `validate:"required,oneof=owner admin member" json:"role"`
+}
+
+type OrganizationDeposit struct {
+	Amount uint64 `validate:"required,min=1" json:"amount"`
+}
+
+type OrganizationInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+
+	Arkhoin uint64 `json:"arkhoins"`
+
+	Members int64 `json:"members"`
+
+	// Role of the issuer in the organization, empty when they are not a member
+	Role string `json:"role"`
+}
+
+type OrganizationMemberInfo struct {
+	ID             uint      `json:"id"`
+	FirstName      string    `json:"firstname"`
+	LastName       string    `json:"lastname"`
+	Username       string    `json:"username"`
+	ProfilePicture string    `json:"pfp"`
+	Verified       bool      `json:"verified"`
+	Role           string    `json:"role"`
+	Since          time.Time `json:"since"`
+}
+
+// Owners and admins run the organization
+func IsOrganizationManager(role string) bool {
+	return role == OrganizationRoleOwner || role == OrganizationRoleAdmin
+}
+
+// The permissions members get on every project of the organization, without being assigned to them
+func OrganizationRoleAllow(role string) Allow {
+	switch role {
+	case OrganizationRoleOwner, OrganizationRoleAdmin:
+		return Allow{
+			View:    true,
+			Edit:    true,
+			Delete:  true,
+			Publish: true,
+			Share:   true,
+			Manage:  AllowManage{Users: true, Metadata: true},
+		}
+	case OrganizationRoleMember:
+		return Allow{View: true, Edit: true}
+	}
+
+	return Allow{}
+}
@@ -4,6 +4,7 @@ import (
 	"mime/multipart"
 	"time"
 
+	"github.com/swibly/swibly-api/pkg/jsonpatch"
 	"github.com/swibly/swibly-api/pkg/utils"
 	"gorm.io/gorm"
 )
@@ -13,6 +14,7 @@ type ProjectCreation struct {
 	Description string `validate:"omitempty,min=3,max=5000" form:"description"`
 
 	BannerImage *multipart.FileHeader `validate:"omitempty" form:"banner"`
+	BannerData  []byte                `form:"-"` // Set in code when importing an archive
 
 	Content any `validate:"omitempty" form:"content"`
 	Budget  int `validate:"omitempty,max=1000000000000" form:"budget"`
@@ -20,7 +22,8 @@ type ProjectCreation struct {
 	Width  int `validate:"omitempty,min=1,max=1000" form:"width"`
 	Height int `validate:"omitempty,min=1,max=1000" form:"height"`
 
-	OwnerID uint `form:"-"` // Set using JWT
+	OwnerID        uint  `form:"-"` // Set using JWT
+	OrganizationID *uint `form:"-"` // Set in an URL query param
 
 	Public bool `form:"-"` // Set in an URL query param
 
@@ -43,6 +46,9 @@ type ProjectUpdate struct {
 }
 
 type ProjectAssign struct {
+	// Name of a preset or of a custom role of the project, its permissions replace the flags below
+	Role *string `validate:"omitempty,max=32" json:"role"`
+
 	View           *bool `validate:"omitempty" json:"view"`
 	Edit           *bool `validate:"omitempty" json:"edit"`
 	Delete         *bool `validate:"omitempty" json:"delete"`
@@ -73,6 +79,7 @@ type ProjectUserPermissions struct {
 	Username       string `json:"username"`
 	ProfilePicture string `json:"pfp"`
 	Verified       bool   `json:"verified"`
+	Role           string `json:"role"`
 	View           bool   `json:"allow_view"`
 	Edit           bool   `json:"allow_edit"`
 	Delete         bool   `json:"allow_delete"`
@@ -108,6 +115,9 @@ type ProjectInfoJSON struct {
 	OwnerProfilePicture string `json:"owner_pfp"`
 	OwnerVerified       bool   `json:"owner_verified"`
 
+	OrganizationID   *uint  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+
 	AllowedUsers utils.JSON `gorm:"type:jsonb" json:"allowed_users"`
 
 	IsFavorited    bool `json:"is_favorited"`
@@ -141,6 +151,9 @@ type ProjectInfo struct {
 	OwnerProfilePicture string `json:"owner_pfp"`
 	OwnerVerified       bool   `json:"owner_verified"`
 
+	OrganizationID   *uint  `json:"organization_id"`
+	OrganizationName string `json:"organization_name"`
+
 	AllowedUsers []ProjectUserPermissions `json:"allowed_users"`
 
 	IsFavorited    bool `json:"is_favorited"`
@@ -152,6 +165,17 @@ func (a Allow) IsEmpty() bool {
 	return !a.View && !a.Edit && !a.Delete && !a.Publish && !a.Share && !a.Manage.Users && !a.Manage.Metadata
 }
 
+// Reports whether every permission in `required` is also in `a`
+func (a Allow) Covers(required Allow) bool {
+	return (!required.View || a.View) &&
+		(!required.Edit || a.Edit) &&
+		(!required.Delete || a.Delete) &&
+		(!required.Publish || a.Publish) &&
+		(!required.Share || a.Share) &&
+		(!required.Manage.Users || a.Manage.Users) &&
+		(!required.Manage.Metadata || a.Manage.Metadata)
+}
+
 func (a ProjectAssign) IsEmpty() bool {
 	return ((a.View != nil && !*a.View) || a.View == nil) &&
 		((a.Edit != nil && !*a.Edit) || a.Edit == nil) &&
@@ -161,3 +185,278 @@ func (a ProjectAssign) IsEmpty() bool {
 		((a.ManageUsers != nil && !*a.ManageUsers) || a.ManageUsers == nil) &&
 		((a.ManageMetadata != nil && !*a.ManageMetadata) || a.ManageMetadata == nil)
 }
+
+type ProjectRevisionInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ProjectID uint `json:"project_id"`
+
+	AuthorID             *uint  `json:"author_id"`
+	AuthorFirstName      string `json:"author_firstname"`
+	AuthorLastName       string `json:"author_lastname"`
+	AuthorUsername       string `json:"author_username"`
+	AuthorProfilePicture string `json:"author_pfp"`
+}
+
+type ProjectRevision struct {
+	ProjectRevisionInfo
+
+	Content any `json:"content"`
+}
+
+type ProjectForkDiff struct {
+	Upstream        uint `json:"upstream"`
+	UpstreamVersion uint `json:"upstream_version"`
+
+	// Operations that turn the content of the fork into the content of the upstream
+	Diff []jsonpatch.Operation `json:"diff"`
+
+	// What changed upstream since the fork was made or last synced, which is what syncing pulls
+	Incoming []jsonpatch.Operation `json:"incoming"`
+}
+
+const (
+	ShareScopeView = "view"
+	ShareScopeEdit = "edit"
+)
+
+type ProjectShareLinkCreation struct {
+	Scope string `validate:"required,oneof=view edit" json:"scope"`
+
+	// Defaults to the expiry configured in config/projects.yaml
+	ExpiresAt *time.Time `validate:"omitempty" json:"expires_at"`
+
+	MaxUses uint `validate:"omitempty,max=1000000" json:"max_uses"`
+}
+
+type ProjectShareLinkInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ProjectID uint `json:"project_id"`
+
+	CreatorID       *uint  `json:"creator_id"`
+	CreatorUsername string `json:"creator_username"`
+
+	Scope     string    `json:"scope"`
+	ExpiresAt time.Time `json:"expires_at"`
+	MaxUses   uint      `json:"max_uses"`
+	Uses      uint      `json:"uses"`
+}
+
+// The permissions the link grants to whoever uses it
+func (l ProjectShareLinkInfo) Allow() Allow {
+	switch l.Scope {
+	case ShareScopeEdit:
+		return Allow{View: true, Edit: true}
+	case ShareScopeView:
+		return Allow{View: true}
+	}
+
+	return Allow{}
+}
+
+type ProjectInviteByEmail struct {
+	ProjectAssign
+
+	Email string `validate:"required,email" json:"email"`
+}
+
+type ProjectInviteInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	ProjectID   uint   `json:"project_id"`
+	ProjectName string `json:"project_name"`
+
+	InviterID       *uint  `json:"inviter_id"`
+	InviterUsername string `json:"inviter_username"`
+
+	UserID   *uint  `json:"user_id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+
+	Role string `json:"role"`
+
+	View           bool `json:"allow_view"`
+	Edit           bool `json:"allow_edit"`
+	Delete         bool `json:"allow_delete"`
+	Publish        bool `json:"allow_publish"`
+	Share          bool `json:"allow_share"`
+	ManageUsers    bool `json:"allow_manage_users"`
+	ManageMetadata bool `json:"allow_manage_metadata"`
+}
+
+// The permissions the invite turns into once accepted
+func (i ProjectInviteInfo) Assign() *ProjectAssign {
+	assign := &ProjectAssign{
+		View:           &i.View,
+		Edit:           &i.Edit,
+		Delete:         &i.Delete,
+		Publish:        &i.Publish,
+		Share:          &i.Share,
+		ManageUsers:    &i.ManageUsers,
+		ManageMetadata: &i.ManageMetadata,
+	}
+
+	if i.Role != "" {
+		assign.Role = &i.Role
+	}
+
+	return assign
+}
+
+// Empty when the permissions were set one by one
+func (a ProjectAssign) RoleName() string {
+	if a.Role == nil {
+		return ""
+	}
+
+	return *a.Role
+}
+
+func (a ProjectAssign) Allow() Allow {
+	deref := func(b *bool) bool { return b != nil && *b }
+
+	return Allow{
+		View:    deref(a.View),
+		Edit:    deref(a.Edit),
+		Delete:  deref(a.Delete),
+		Publish: deref(a.Publish),
+		Share:   deref(a.Share),
+		Manage: AllowManage{
+			Users:    deref(a.ManageUsers),
+			Metadata: deref(a.ManageMetadata),
+		},
+	}
+}
+
+const (
+	ProjectRoleViewer    = "viewer"
+	ProjectRoleEditor    = "editor"
+	ProjectRolePublisher = "publisher"
+	ProjectRoleManager   = "manager"
+)
+
+// Roles every project has, they cannot be changed or deleted
+var ProjectRolePresets = []string{ProjectRoleViewer, ProjectRoleEditor, ProjectRolePublisher, ProjectRoleManager}
+
+// The permissions of a preset, false when the name is not one
+func ProjectRolePreset(name string) (Allow, bool) {
+	switch name {
+	case ProjectRoleViewer:
+		return Allow{View: true}, true
+	case ProjectRoleEditor:
+		return Allow{View: true, Edit: true}, true
+	case ProjectRolePublisher:
+		return Allow{View: true, Edit: true, Publish: true, Share: true}, true
+	case ProjectRoleManager:
+		return Allow{
+			View:    true,
+			Edit:    true,
+			Delete:  true,
+			Publish: true,
+			Share:   true,
+			Manage:  AllowManage{Users: true, Metadata: true},
+		}, true
+	}
+
+	return Allow{}, false
+}
+
+// Turns the permissions into an assignment of every flag, so applying it also revokes what is missing
+func (a Allow) Assign(role string) *ProjectAssign {
+	return &ProjectAssign{
+		Role:           &role,
+		View:           &a.View,
+		Edit:           &a.Edit,
+		Delete:         &a.Delete,
+		Publish:        &a.Publish,
+		Share:          &a.Share,
+		ManageUsers:    &a.Manage.Users,
+		ManageMetadata: &a.Manage.Metadata,
+	}
+}
+
+type ProjectRoleCreation struct {
+	Name string `validate:"required,min=2,max=32,username" json:"name"`
+
+	View           bool `json:"view"`
+	Edit           bool `json:"edit"`
+	Delete         bool `json:"delete"`
+	Publish        bool `json:"publish"`
+	Share          bool `json:"share"`
+	ManageUsers    bool `json:"manage_users"`
+	ManageMetadata bool `json:"manage_metadata"`
+}
+
+type ProjectRoleUpdate struct {
+	Name *string `validate:"omitempty,min=2,max=32,username" json:"name"`
+
+	View           *bool `validate:"omitempty" json:"view"`
+	Edit           *bool `validate:"omitempty" json:"edit"`
+	Delete         *bool `validate:"omitempty" json:"delete"`
+	Publish        *bool `validate:"omitempty" json:"publish"`
+	Share          *bool `validate:"omitempty" json:"share"`
+	ManageUsers    *bool `validate:"omitempty" json:"manage_users"`
+	ManageMetadata *bool `validate:"omitempty" json:"manage_metadata"`
+}
+
+type ProjectRoleInfo struct {
+	Name   string `json:"name"`
+	Preset bool   `json:"preset"`
+
+	View           bool `json:"allow_view"`
+	Edit           bool `json:"allow_edit"`
+	Delete         bool `json:"allow_delete"`
+	Publish        bool `json:"allow_publish"`
+	Share          bool `json:"allow_share"`
+	ManageUsers    bool `json:"allow_manage_users"`
+	ManageMetadata bool `json:"allow_manage_metadata"`
+
+	// How many collaborators currently hold the role
+	Holders int64 `json:"holders"`
+}
+
+func (c ProjectRoleCreation) Allow() Allow {
+	return Allow{
+		View:    c.View,
+		Edit:    c.Edit,
+		Delete:  c.Delete,
+		Publish: c.Publish,
+		Share:   c.Share,
+		Manage:  AllowManage{Users: c.ManageUsers, Metadata: c.ManageMetadata},
+	}
+}
+
+func (r ProjectRoleInfo) Allow() Allow {
+	return Allow{
+		View:    r.View,
+		Edit:    r.Edit,
+		Delete:  r.Delete,
+		Publish: r.Publish,
+		Share:   r.Share,
+		Manage:  AllowManage{Users: r.ManageUsers, Metadata: r.ManageMetadata},
+	}
+}
+
+// Applies the flags that were sent on top of the current permissions of the role
+func (u ProjectRoleUpdate) Apply(current Allow) Allow {
+	set := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+
+	set(&current.View, u.View)
+	set(&current.Edit, u.Edit)
+	set(&current.Delete, u.Delete)
+	set(&current.Publish, u.Publish)
+	set(&current.Share, u.Share)
+	set(&current.Manage.Users, u.ManageUsers)
+	set(&current.Manage.Metadata, u.ManageMetadata)
+
+	return current
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type SessionMetadata struct {
+	Device    string
+	IP        string
+	UserAgent string
+}
+
+type RefreshSession struct {
+	RefreshToken string `validate:"required" json:"refresh_token"`
+}
+
+type SessionTokens struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type SessionInfo struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+
+	Device    string `json:"device"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+
+	Current bool `json:"current"`
+}
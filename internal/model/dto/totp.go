@@ -0,0 +1,19 @@
+package dto
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type TOTPConfirm struct {
+	Code string `validate:"required" json:"code"`
+}
+
+type TOTPDisable struct {
+	Password string `validate:"required" json:"password"`
+}
+
+type TOTPLogin struct {
+	Challenge string `validate:"required" json:"challenge"`
+	Code      string `validate:"required" json:"code"`
+}
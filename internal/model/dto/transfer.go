@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+type ProjectTransferInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	ProjectID   uint   `json:"project_id"`
+	ProjectName string `json:"project_name"`
+
+	OwnerID       uint   `json:"owner_id"`
+	OwnerUsername string `json:"owner_username"`
+
+	IssuerID       *uint  `json:"issuer_id"`
+	IssuerUsername string `json:"issuer_username"`
+
+	RecipientID       uint   `json:"recipient_id"`
+	RecipientUsername string `json:"recipient_username"`
+
+	KeepAccess bool `json:"keep_access"`
+}
+
+type ComponentTransferInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	ComponentID   uint   `json:"component_id"`
+	ComponentName string `json:"component_name"`
+
+	OwnerID       uint   `json:"owner_id"`
+	OwnerUsername string `json:"owner_username"`
+
+	IssuerID       *uint  `json:"issuer_id"`
+	IssuerUsername string `json:"issuer_username"`
+
+	RecipientID       uint   `json:"recipient_id"`
+	RecipientUsername string `json:"recipient_username"`
+
+	KeepAccess bool `json:"keep_access"`
+}
@@ -28,7 +28,7 @@ type UserUpdate struct {
 	Username  *string `validate:"omitempty,min=3,max=32,username" json:"username"`
 
 	Bio      *string `validate:"omitempty,max=480" json:"bio"`
-	Verified *bool   `validate:"omitempty"         json:"verified"`
+	Verified *bool   `validate:"omitempty"         json:"-"` // Only set internally, see `PATCH /user/:username/verified`
 
 	Email    *string `validate:"omitempty,email"    json:"email"`
 	Password *string `validate:"omitempty,password" json:"password"`
@@ -39,16 +39,17 @@ type UserUpdate struct {
 	} `validate:"omitempty" json:"notify" gorm:"embedded;embeddedPrefix:notify_"`
 
 	Show struct {
-		Profile    *bool `validate:"omitempty" json:"profile"`
-		Image      *bool `validate:"omitempty" json:"image"`
-		Comments   *bool `validate:"omitempty" json:"comments"`
-		Favorites  *bool `validate:"omitempty" json:"favorites"`
-		Projects   *bool `validate:"omitempty" json:"projects"`
-		Components *bool `validate:"omitempty" json:"components"`
-		Followers  *bool `validate:"omitempty" json:"followers"`
-		Following  *bool `validate:"omitempty" json:"following"`
-		Inventory  *bool `validate:"omitempty" json:"inventory"`
-		Formations *bool `validate:"omitempty" json:"formations"`
+		Profile      *bool `validate:"omitempty" json:"profile"`
+		Image        *bool `validate:"omitempty" json:"image"`
+		Comments     *bool `validate:"omitempty" json:"comments"`
+		Favorites    *bool `validate:"omitempty" json:"favorites"`
+		Projects     *bool `validate:"omitempty" json:"projects"`
+		Components   *bool `validate:"omitempty" json:"components"`
+		Followers    *bool `validate:"omitempty" json:"followers"`
+		Following    *bool `validate:"omitempty" json:"following"`
+		Inventory    *bool `validate:"omitempty" json:"inventory"`
+		Formations   *bool `validate:"omitempty" json:"formations"`
+		Achievements *bool `validate:"omitempty" json:"achievements"`
 	} `validate:"omitempty" json:"show" gorm:"embedded;embeddedPrefix:show_"`
 
 	Country *string `validate:"omitempty,max=40" json:"country"`
@@ -72,6 +73,7 @@ type UserProfile struct {
 	Verified bool   `json:"verified"`
 
 	XP      uint64 `json:"xp"`
+	Level   Level  `gorm:"-" json:"level"`
 	Arkhoin uint64 `json:"arkhoins"`
 
 	Followers int64 `gorm:"-" json:"followers"`
@@ -83,16 +85,17 @@ type UserProfile struct {
 	} `gorm:"embedded;embeddedPrefix:notify_" json:"notification"`
 
 	Show struct {
-		Profile    bool `json:"profile"`
-		Image      bool `json:"image"`
-		Comments   bool `json:"comments"`
-		Favorites  bool `json:"favorites"`
-		Projects   bool `json:"projects"`
-		Components bool `json:"components"`
-		Followers  bool `json:"followers"`
-		Following  bool `json:"following"`
-		Inventory  bool `json:"inventory"`
-		Formations bool `json:"formations"`
+		Profile      bool `json:"profile"`
+		Image        bool `json:"image"`
+		Comments     bool `json:"comments"`
+		Favorites    bool `json:"favorites"`
+		Projects     bool `json:"projects"`
+		Components   bool `json:"components"`
+		Followers    bool `json:"followers"`
+		Following    bool `json:"following"`
+		Inventory    bool `json:"inventory"`
+		Formations   bool `json:"formations"`
+		Achievements bool `json:"achievements"`
 	} `gorm:"embedded;embeddedPrefix:show_" json:"show"`
 
 	Country  string `json:"country"`
@@ -114,16 +117,17 @@ type UserInfoLite struct {
 }
 
 type UserShow struct {
-	Profile    bool
-	Image      bool
-	Comments   bool
-	Favorites  bool
-	Projects   bool
-	Components bool
-	Followers  bool
-	Following  bool
-	Inventory  bool
-	Formations bool
+	Profile      bool
+	Image        bool
+	Comments     bool
+	Favorites    bool
+	Projects     bool
+	Components   bool
+	Followers    bool
+	Following    bool
+	Inventory    bool
+	Formations   bool
+	Achievements bool
 }
 
 type UserProfilePicture struct {
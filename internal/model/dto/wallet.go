@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+const (
+	ArkhoinPurchase = "purchase"
+	ArkhoinSale     = "sale"
+	ArkhoinRefund   = "refund"
+	ArkhoinGrant    = "grant"
+	ArkhoinReward   = "reward"
+	ArkhoinTransfer = "transfer"
+)
+
+// Reason of the grant every wallet starts its history with
+const ArkhoinOpeningBalance = "opening balance"
+
+type ArkhoinTransactionInfo struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Type string `json:"type"`
+
+	Amount       int64  `json:"amount"`
+	BalanceAfter uint64 `json:"balance_after"`
+
+	UserID         *uint  `json:"user_id"`
+	Username       string `json:"username"`
+	OrganizationID *uint  `json:"organization_id"`
+
+	CounterpartyUserID           *uint  `json:"counterparty_user_id"`
+	CounterpartyUsername         string `json:"counterparty_username"`
+	CounterpartyOrganizationID   *uint  `json:"counterparty_organization_id"`
+	CounterpartyOrganizationName string `json:"counterparty_organization_name"`
+
+	ComponentID   *uint  `json:"component_id"`
+	ComponentName string `json:"component_name"`
+
+	Reason string `json:"reason"`
+}
+
+// A wallet whose stored balance is not the sum of its ledger entries
+type WalletMismatch struct {
+	Wallet string `json:"wallet"` // Either "user" or "organization"
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+
+	Balance uint64 `json:"balance"`
+	Ledger  int64  `json:"ledger"`
+}
+
+type WalletReconciliation struct {
+	Wallets    int64            `json:"wallets"`
+	Mismatches []WalletMismatch `json:"mismatches"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+// Actions that earn XP, as named in config/xp.yaml
+const (
+	XPProjectCreated   = "project_created"
+	XPProjectPublished = "project_published"
+	XPProjectFavorited = "project_favorited"
+	XPProjectForked    = "project_forked"
+	XPComponentSold    = "component_sold"
+	XPFollowerGained   = "follower_gained"
+)
+
+type Level struct {
+	Level    int     `json:"level"`
+	Current  uint64  `json:"current"`  // XP the level starts at
+	Next     uint64  `json:"next"`     // XP the next level starts at
+	Progress float64 `json:"progress"` // From 0 to 1, towards the next level
+}
+
+// Works out the level of the XP. Thresholds are the XP each level starts at, from level 1, and every level
+// after the last threshold takes step more XP.
+func NewLevel(xp uint64, thresholds []uint64, step uint64) Level {
+	if step == 0 {
+		step = 1
+	}
+
+	level := Level{Level: 1}
+
+	for i, threshold := range thresholds {
+		if xp < threshold {
+			level.Next = threshold
+			break
+		}
+
+		level.Level = i + 1
+		level.Current = threshold
+	}
+
+	if level.Next == 0 {
+		extra := (xp - level.Current) / step
+
+		level.Level += int(extra)
+		level.Current += extra * step
+		level.Next = level.Current + step
+	}
+
+	level.Progress = float64(xp-level.Current) / float64(level.Next-level.Current)
+
+	return level
+}
+
+type XPChange struct {
+	UserID uint
+	Before uint64
+	After  uint64
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationKey struct {
+	Key    uuid.UUID `gorm:"primarykey;type:uuid;default:gen_random_uuid()"`
+	UserID uint      `gorm:"unique;not null"`
+
+	// The address being verified. If the user changes their email in the meantime, the key is no longer valid
+	Email string `gorm:"not null"`
+
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastSentAt time.Time `gorm:"not null"`
+}
+
+func (e *EmailVerificationKey) BeforeCreate(tx *gorm.DB) (err error) {
+	e.ExpiresAt = time.Now().Add(24 * time.Hour)
+	return
+}
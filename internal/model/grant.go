@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Audit entry of an Arkhoin or XP change made by an admin, either directly or through an airdrop.
+// Usernames are kept on the entry so it still reads the same once either user is deleted.
+type Grant struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	IssuerID       *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+	IssuerUsername string
+
+	UserID   *uint  `gorm:"index;constraint:OnDelete:SET NULL;"`
+	Username string `gorm:"not null;default:''"`
+
+	Currency string `gorm:"not null"`
+	Amount   int64  `gorm:"not null"` // Negative when deducted
+	Reason   string `gorm:"not null"`
+
+	AirdropID *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+}
+
+// A grant to many users that runs once ScheduledAt is reached
+type Airdrop struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	IssuerID *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+
+	Currency string `gorm:"not null"`
+	Amount   int64  `gorm:"not null"`
+	Reason   string `gorm:"not null"`
+
+	// Every user at the time it runs gets it, instead of the AirdropRecipient rows
+	Everyone bool `gorm:"not null;default:false"`
+
+	// Language of the issuer, used for the notifications sent once it runs
+	Language string `gorm:"not null"`
+
+	ScheduledAt time.Time  `gorm:"index;not null"`
+	ExecutedAt  *time.Time `gorm:"index"`
+}
+
+type AirdropRecipient struct {
+	ID uint `gorm:"primarykey"`
+
+	AirdropID uint `gorm:"uniqueIndex:idx_airdrop_recipient;not null;constraint:OnDelete:CASCADE;"`
+	UserID    uint `gorm:"uniqueIndex:idx_airdrop_recipient;index;not null;constraint:OnDelete:CASCADE;"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// Lets several users own projects and components together, and pay for components from a shared wallet
+type Organization struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	// Used in routes the same way usernames are
+	Name        string `gorm:"unique;not null"`
+	DisplayName string `gorm:"not null"`
+	Bio         string `gorm:"default:''"`
+
+	Arkhoin uint64 `gorm:"default:0"`
+}
+
+type OrganizationMember struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	OrganizationID uint `gorm:"uniqueIndex:idx_organization_member;not null;constraint:OnDelete:CASCADE;"`
+	UserID         uint `gorm:"uniqueIndex:idx_organization_member;index;not null;constraint:OnDelete:CASCADE;"`
+
+	Role string `gorm:"not null"`
+}
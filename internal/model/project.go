@@ -24,7 +24,13 @@ type Project struct {
 	Content any `gorm:"type:jsonb;not null;default:'{}'"`
 	Budget  int `gorm:"default:0"`
 
+	// Incremented on every content save, sent to clients as the ETag of the content
+	Version uint `gorm:"not null;default:1"`
+
 	Fork *uint `gorm:"index"`
+
+	// Content of the upstream when the fork was made or last synced, the common ancestor used to pull upstream changes
+	ForkBase any `gorm:"type:jsonb"`
 }
 
 type ProjectOwner struct {
@@ -32,8 +38,11 @@ type ProjectOwner struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
-	ProjectID uint `gorm:"unique;index;not null;constraint:OnDelete:CASCADE;"`
-	UserID    uint `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
+	ProjectID uint  `gorm:"unique;index;not null;constraint:OnDelete:CASCADE;"`
+	UserID    *uint `gorm:"index;constraint:OnDelete:CASCADE;"`
+
+	// Set when the project belongs to an organization, UserID is then the member who created it, if they still exist
+	OrganizationID *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
 }
 
 type ProjectPublication struct {
@@ -61,5 +70,97 @@ type ProjectUserPermission struct {
 	ProjectID uint `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
 	UserID    uint `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
 
+	// Preset or ProjectRole the permissions come from, empty when they were set one by one
+	Role string `gorm:"index;not null;default:''"`
+
+	Allow dto.Allow `gorm:"embedded;embeddedPrefix:allow_"`
+}
+
+// A custom role of the project, its permissions are copied to every ProjectUserPermission holding it
+type ProjectRole struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ProjectID uint   `gorm:"uniqueIndex:idx_project_role;not null;constraint:OnDelete:CASCADE;"`
+	Name      string `gorm:"uniqueIndex:idx_project_role;not null"`
+
 	Allow dto.Allow `gorm:"embedded;embeddedPrefix:allow_"`
 }
+
+type ProjectRevision struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	ProjectID uint  `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
+	AuthorID  *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+
+	Content any `gorm:"type:jsonb;not null;default:'{}'"`
+}
+
+// Gives whoever has the token access to the project without being assigned to it
+type ProjectShareLink struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ProjectID uint  `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
+	CreatorID *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+
+	// SHA-256 digest of the token, the token itself is only shown when the link is created
+	Token string `gorm:"unique;not null"`
+
+	Scope string `gorm:"not null"`
+
+	ExpiresAt time.Time `gorm:"not null"`
+
+	// 0 means unlimited. Each user counts once, however many requests they send with the token
+	MaxUses uint `gorm:"not null;default:0"`
+	Uses    uint `gorm:"not null;default:0"`
+}
+
+// A user who used a ProjectShareLink, so that their later requests do not count as new uses
+type ProjectShareLinkRedemption struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	LinkID uint `gorm:"uniqueIndex:idx_share_link_redemption;not null;constraint:OnDelete:CASCADE;"`
+	UserID uint `gorm:"uniqueIndex:idx_share_link_redemption;index;not null;constraint:OnDelete:CASCADE;"`
+}
+
+// A pending ProjectUserPermission, only created once the invitee accepts it
+type ProjectInvite struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ProjectID uint  `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
+	InviterID *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+
+	// Either the invited user, or the address of someone without an account. Email invites
+	// go to whoever verifies that address after signing up.
+	UserID *uint  `gorm:"index;constraint:OnDelete:CASCADE;"`
+	Email  string `gorm:"index;default:''"`
+
+	Role  string    `gorm:"not null;default:''"`
+	Allow dto.Allow `gorm:"embedded;embeddedPrefix:allow_"`
+
+	ExpiresAt time.Time `gorm:"not null"`
+}
+
+// A pending change of the ProjectOwner, only applied once the recipient accepts it
+type ProjectTransfer struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	ProjectID   uint  `gorm:"unique;index;not null;constraint:OnDelete:CASCADE;"`
+	IssuerID    *uint `gorm:"index;constraint:OnDelete:SET NULL;"`
+	RecipientID uint  `gorm:"index;not null;constraint:OnDelete:CASCADE;"`
+
+	// Whether the previous owner stays on the project as a collaborator allowed to do everything
+	KeepAccess bool `gorm:"not null;default:false"`
+
+	ExpiresAt time.Time `gorm:"not null"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type Session struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	UserID uint `gorm:"not null;index"`
+
+	// SHA-256 digest of the refresh token, the token itself is only known by the client
+	RefreshToken string `gorm:"unique;not null"`
+
+	Device    string
+	IP        string
+	UserAgent string
+
+	ExpiresAt  time.Time `gorm:"not null"`
+	LastUsedAt time.Time
+	RevokedAt  *time.Time
+}
+
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(time.Now())
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+type UserTOTP struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	UserID uint   `gorm:"unique;not null"`
+	Secret string `gorm:"not null"`
+
+	// Stays false until the user confirms the enrollment with a valid code
+	Enabled bool `gorm:"default:false"`
+
+	// Last accepted time step, used to reject replayed codes
+	LastUsedStep int64 `gorm:"default:0"`
+}
+
+type TOTPRecoveryCode struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	UserID uint   `gorm:"not null;index"`
+	Code   string `gorm:"not null"` // SHA-256 digest of the code
+
+	UsedAt *time.Time
+}
@@ -29,16 +29,17 @@ type User struct {
 	} `gorm:"embedded;embeddedPrefix:notify_"`
 
 	Show struct {
-		Profile    bool `gorm:"default:true"`
-		Image      bool `gorm:"default:true"`
-		Comments   bool `gorm:"default:true"`
-		Favorites  bool `gorm:"default:true"`
-		Projects   bool `gorm:"default:true"`
-		Components bool `gorm:"default:true"`
-		Followers  bool `gorm:"default:true"`
-		Following  bool `gorm:"default:true"`
-		Inventory  bool `gorm:"default:false"`
-		Formations bool `gorm:"default:true"`
+		Profile      bool `gorm:"default:true"`
+		Image        bool `gorm:"default:true"`
+		Comments     bool `gorm:"default:true"`
+		Favorites    bool `gorm:"default:true"`
+		Projects     bool `gorm:"default:true"`
+		Components   bool `gorm:"default:true"`
+		Followers    bool `gorm:"default:true"`
+		Following    bool `gorm:"default:true"`
+		Inventory    bool `gorm:"default:false"`
+		Formations   bool `gorm:"default:true"`
+		Achievements bool `gorm:"default:true"`
 	} `gorm:"embedded;embeddedPrefix:show_"`
 
 	Country string
@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// An entry of the Arkhoin ledger. Entries are only ever inserted, every change to a wallet balance writes one.
+type ArkhoinTransaction struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	Type string `gorm:"index;not null"`
+
+	// The wallet is the one of the organization when it is set, UserID is then the member behind the change
+	UserID         *uint `gorm:"index;uniqueIndex:idx_arkhoin_idempotency"`
+	OrganizationID *uint `gorm:"index"`
+
+	// The other side of the exchange, if any
+	CounterpartyUserID         *uint `gorm:"index"`
+	CounterpartyOrganizationID *uint `gorm:"index"`
+
+	ComponentID *uint `gorm:"index"`
+
+	// Negative when Arkhoins left the wallet
+	Amount       int64  `gorm:"not null"`
+	BalanceAfter uint64 `gorm:"not null"`
+
+	Reason string `gorm:"default:''"`
+
+	// Sent by clients along with purchases and sales, a retry with the same key is not charged twice
+	IdempotencyKey *string `gorm:"uniqueIndex:idx_arkhoin_idempotency"`
+}
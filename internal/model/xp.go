@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// XP a user earned from an action. The reference identifies what earned it, so the same thing is only
+// rewarded once, e.g. a favorite that is removed and given again.
+type XPEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+
+	UserID    uint   `gorm:"uniqueIndex:idx_xp_event;not null;constraint:OnDelete:CASCADE;"`
+	Rule      string `gorm:"uniqueIndex:idx_xp_event;not null"`
+	Reference string `gorm:"uniqueIndex:idx_xp_event;not null"`
+
+	Amount uint64 `gorm:"not null"`
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/translations"
+)
+
+// Unlocks the achievements of the metric the user reached and tells them about it. Failures are only logged, the
+// action that changed the metric already went through.
+func CheckAchievements(dict translations.Translation, userID uint, metric string) {
+	unlocked, change, err := Achievement.Evaluate(userID, metric)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	for _, achievement := range unlocked {
+		CreateNotification(dto.CreateNotification{
+			Title:   dict.CategoryAchievement,
+			Message: fmt.Sprintf(dict.NotificationAchievementUnlocked, achievement.Name),
+			Type:    notification.Information,
+		}, userID)
+	}
+
+	NotifyLevelUps(dict, *change)
+}
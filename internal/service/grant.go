@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/language"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/translations"
+)
+
+// Tells the users what they were granted, or what was deducted from them, and why
+func NotifyGrant(dict translations.Translation, grant *dto.GrantCreation, ids ...uint) error {
+	var message string
+	var kind notification.NotificationType = notification.Information
+
+	amount := grant.Amount
+	if amount < 0 {
+		amount = -amount
+		kind = notification.Warning
+	}
+
+	switch {
+	case grant.Currency == dto.GrantArkhoin && grant.Amount > 0:
+		message = dict.NotificationArkhoinGranted
+	case grant.Currency == dto.GrantArkhoin:
+		message = dict.NotificationArkhoinDeducted
+	case grant.Amount > 0:
+		message = dict.NotificationXPGranted
+	default:
+		message = dict.NotificationXPDeducted
+	}
+
+	return CreateNotification(dto.CreateNotification{
+		Title:   dict.CategoryWallet,
+		Message: fmt.Sprintf(message, amount, grant.Reason),
+		Type:    kind,
+	}, ids...)
+}
+
+// Runs the airdrops that are due every interval, notifying their recipients in the language of whoever scheduled them
+func RunAirdropsEvery(interval time.Duration) {
+	for range time.Tick(interval) {
+		Grant.RunDueAirdrops(func(airdrop *model.Airdrop, recipients []uint, changes []dto.XPChange) {
+			if len(recipients) == 0 {
+				return
+			}
+
+			dict, ok := translations.Translations[airdrop.Language]
+			if !ok {
+				dict = translations.Translations[string(language.PT)]
+			}
+
+			NotifyGrant(dict, &dto.GrantCreation{
+				Currency: airdrop.Currency,
+				Amount:   airdrop.Amount,
+				Reason:   airdrop.Reason,
+			}, recipients...)
+
+			NotifyLevelUps(dict, changes...)
+		})
+	}
+}
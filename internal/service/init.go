@@ -3,23 +3,50 @@ package service
 import "github.com/swibly/swibly-api/internal/service/usecase"
 
 var (
-	APIKey        usecase.APIKeyUseCase
-	User          usecase.UserUseCase
-	Follow        usecase.FollowUseCase
-	Permission    usecase.PermissionUseCase
-	Project       usecase.ProjectUseCase
-	Component     usecase.ComponentUseCase
-	PasswordReset usecase.PasswordResetUseCase
-	Notification  usecase.NotificationUseCase
+	APIKey            usecase.APIKeyUseCase
+	User              usecase.UserUseCase
+	Follow            usecase.FollowUseCase
+	Organization      usecase.OrganizationUseCase
+	Permission        usecase.PermissionUseCase
+	Project           usecase.ProjectUseCase
+	ProjectShare      usecase.ProjectShareUseCase
+	ProjectRole       usecase.ProjectRoleUseCase
+	ProjectInvite     usecase.ProjectInviteUseCase
+	ProjectTransfer   usecase.ProjectTransferUseCase
+	Component         usecase.ComponentUseCase
+	ComponentTransfer usecase.ComponentTransferUseCase
+	Wallet            usecase.WalletUseCase
+	Grant             usecase.GrantUseCase
+	XP                usecase.XPUseCase
+	Achievement       usecase.AchievementUseCase
+	PasswordReset     usecase.PasswordResetUseCase
+	Notification      usecase.NotificationUseCase
+	Session           usecase.SessionUseCase
+
+	EmailVerification usecase.EmailVerificationUseCase
+	TOTP              usecase.TOTPUseCase
 )
 
 func Init() {
 	APIKey = usecase.NewAPIKeyUseCase()
 	User = usecase.NewUserUseCase()
 	Follow = usecase.NewFollowUseCase()
+	Organization = usecase.NewOrganizationUseCase()
 	Permission = usecase.NewPermissionUseCase()
 	Project = usecase.NewProjectUseCase()
+	ProjectShare = usecase.NewProjectShareUseCase()
+	ProjectRole = usecase.NewProjectRoleUseCase()
+	ProjectInvite = usecase.NewProjectInviteUseCase()
+	ProjectTransfer = usecase.NewProjectTransferUseCase()
 	Component = usecase.NewComponentUseCase()
+	ComponentTransfer = usecase.NewComponentTransferUseCase()
+	Wallet = usecase.NewWalletUseCase()
+	Grant = usecase.NewGrantUseCase()
+	XP = usecase.NewXPUseCase()
+	Achievement = usecase.NewAchievementUseCase()
 	PasswordReset = usecase.NewPasswordResetUseCase()
 	Notification = usecase.NewNotificationUseCase()
+	Session = usecase.NewSessionUseCase()
+	EmailVerification = usecase.NewEmailVerificationUseCase()
+	TOTP = usecase.NewTOTPUseCase()
 }
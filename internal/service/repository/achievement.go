@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"gorm.io/gorm"
+)
+
+type achievementRepository struct {
+	db *gorm.DB
+}
+
+type AchievementRepository interface {
+	Evaluate(userID uint, metric string) ([]model.Achievement, *dto.XPChange, error)
+	GetByUser(userID uint) ([]dto.AchievementInfo, error)
+}
+
+var ErrUnknownMetric = errors.New("unknown achievement metric")
+
+// How much of each metric the user has. Favorites and forks users give their own projects do not count
+var metricQueries = map[string]string{
+	dto.MetricProjectsCreated: `
+		SELECT COUNT(*) FROM project_owners WHERE user_id = @user`,
+	dto.MetricProjectsPublished: `
+		SELECT COUNT(*)
+		FROM project_publications pp
+		JOIN project_owners po ON po.project_id = pp.project_id
+		WHERE po.user_id = @user`,
+	dto.MetricFavoritesReceived: `
+		SELECT COUNT(*)
+		FROM project_user_favorites f
+		JOIN project_owners po ON po.project_id = f.project_id
+		WHERE po.user_id = @user AND f.user_id <> @user`,
+	dto.MetricForksReceived: `
+		SELECT COUNT(*)
+		FROM projects p
+		JOIN project_owners po ON po.project_id = p.fork
+		JOIN project_owners fo ON fo.project_id = p.id
+		WHERE po.user_id = @user AND fo.user_id <> @user`,
+	dto.MetricComponentsSold: `
+		SELECT COUNT(*)
+		FROM component_holders ch
+		JOIN component_owners co ON co.component_id = ch.component_id
+		WHERE co.user_id = @user`,
+	dto.MetricFollowers: `
+		SELECT COUNT(*) FROM followers WHERE following_id = @user`,
+}
+
+func NewAchievementRepository() AchievementRepository {
+	return &achievementRepository{db: db.Postgres}
+}
+
+func metricCount(tx *gorm.DB, userID uint, metric string) (uint64, error) {
+	query, ok := metricQueries[metric]
+	if !ok {
+		return 0, ErrUnknownMetric
+	}
+
+	var count uint64
+	err := tx.Raw(query, map[string]any{"user": userID}).Scan(&count).Error
+	return count, err
+}
+
+// Unlocks the achievements of the metric the user reached and gives out their rewards, returning the ones that
+// were unlocked and how the XP of the user changed
+func (ar *achievementRepository) Evaluate(userID uint, metric string) ([]model.Achievement, *dto.XPChange, error) {
+	var unlocked []model.Achievement
+	change := &dto.XPChange{UserID: userID}
+
+	err := ar.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the user first keeps two evaluations from rewarding the same achievement twice
+		if err := lockWallets(tx, wallet{UserID: &userID}); err != nil {
+			return err
+		}
+
+		count, err := metricCount(tx, userID, metric)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.
+			Where("metric = ? AND threshold <= ?", metric, count).
+			Where("id NOT IN (?)", tx.Model(&model.UserAchievement{}).Select("achievement_id").Where("user_id = ?", userID)).
+			Order("threshold").
+			Find(&unlocked).Error; err != nil {
+			return err
+		}
+
+		var user model.User
+		if err := tx.Select("id", "xp").Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+
+		change.Before = user.XP
+		change.After = user.XP
+
+		for _, achievement := range unlocked {
+			if err := tx.Create(&model.UserAchievement{UserID: userID, AchievementID: achievement.ID}).Error; err != nil {
+				return err
+			}
+
+			if achievement.ArkhoinReward > 0 {
+				if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+					Type:   dto.ArkhoinReward,
+					UserID: &userID,
+					Amount: int64(achievement.ArkhoinReward),
+					Reason: fmt.Sprintf("achievement: %s", achievement.Key),
+				}); err != nil {
+					return err
+				}
+			}
+
+			change.After += achievement.XPReward
+		}
+
+		if change.After == change.Before {
+			return nil
+		}
+
+		return tx.Model(&model.User{}).Where("id = ?", userID).Update("xp", change.After).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return unlocked, change, nil
+}
+
+// Every achievement with how far the user is from it, the ones they unlocked first
+func (ar *achievementRepository) GetByUser(userID uint) ([]dto.AchievementInfo, error) {
+	achievements := []dto.AchievementInfo{}
+
+	if err := ar.db.Table("achievements a").
+		Select(`
+			a.key AS key,
+			a.name AS name,
+			a.description AS description,
+			a.metric AS metric,
+			a.threshold AS threshold,
+			a.arkhoin_reward AS arkhoin_reward,
+			a.xp_reward AS xp_reward,
+			ua.created_at AS unlocked_at
+		`).
+		Joins("LEFT JOIN user_achievements ua ON ua.achievement_id = a.id AND ua.user_id = ?", userID).
+		Order("ua.created_at ASC NULLS LAST, a.metric, a.threshold").
+		Scan(&achievements).Error; err != nil {
+		return nil, err
+	}
+
+	counts := map[string]uint64{}
+	for i, achievement := range achievements {
+		// Unlocked achievements stay complete even if the count goes down later on
+		if achievement.UnlockedAt != nil {
+			achievements[i].Progress = achievement.Threshold
+			continue
+		}
+
+		count, ok := counts[achievement.Metric]
+		if !ok {
+			var err error
+			count, err = metricCount(ar.db, userID, achievement.Metric)
+			if errors.Is(err, ErrUnknownMetric) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+
+			counts[achievement.Metric] = count
+		}
+
+		achievements[i].Progress = min(count, achievement.Threshold)
+	}
+
+	return achievements, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/swibly/swibly-api/internal/model"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/pkg/db"
@@ -14,15 +16,16 @@ type apiKeyRepository struct {
 
 type APIKeyRepository interface {
 	Create(createModel *model.APIKey) error
-	Update(key string, updateModel *model.APIKey) error
-	Delete(key string) error
+	Update(prefix string, updateModel *model.APIKey) error
+	Delete(prefix string) error
 
 	GetAll(page, perPage int) (*dto.Pagination[dto.ReadAPIKey], error)
-	GetByKey(key string) (*dto.ReadAPIKey, error)
+	GetByKey(hash string) (*dto.ReadAPIKey, error)
+	GetByPrefix(prefix string) (*dto.ReadAPIKey, error)
 	GetByOwner(owner string, page, perPage int) (*dto.Pagination[dto.ReadAPIKey], error)
 
-	RegisterUse(key string) error
-	Regenerate(oldKey, newKey string) error
+	AddUses(id, amount uint, lastUsedAt time.Time, lastUsedIP string) error
+	Regenerate(prefix, newHash string) error
 }
 
 func NewAPIKeyRepository() APIKeyRepository {
@@ -33,22 +36,32 @@ func (akr *apiKeyRepository) Create(createModel *model.APIKey) error {
 	return akr.db.Create(&createModel).Error
 }
 
-func (akr *apiKeyRepository) Update(key string, updateModel *model.APIKey) error {
-	return akr.db.Model(&model.APIKey{}).Where("key = ?", key).Updates(updateModel).Error
+func (akr *apiKeyRepository) Update(prefix string, updateModel *model.APIKey) error {
+	return akr.db.Model(&model.APIKey{}).Where("prefix = ?", prefix).Updates(updateModel).Error
 }
 
-func (akr *apiKeyRepository) Delete(key string) error {
-	return akr.db.Unscoped().Delete(&model.APIKey{}, &model.APIKey{Key: key}).Error
+func (akr *apiKeyRepository) Delete(prefix string) error {
+	return akr.db.Unscoped().Where("prefix = ?", prefix).Delete(&model.APIKey{}).Error
 }
 
 func (akr *apiKeyRepository) GetAll(page, perPage int) (*dto.Pagination[dto.ReadAPIKey], error) {
 	return pagination.Generate[dto.ReadAPIKey](akr.db.Model(&model.APIKey{}).Select("*"), page, perPage)
 }
 
-func (akr *apiKeyRepository) GetByKey(key string) (*dto.ReadAPIKey, error) {
+func (akr *apiKeyRepository) GetByKey(hash string) (*dto.ReadAPIKey, error) {
+	var apikey *dto.ReadAPIKey
+
+	if err := akr.db.Model(&model.APIKey{}).First(&apikey, &model.APIKey{Key: hash}).Error; err != nil {
+		return nil, err
+	}
+
+	return apikey, nil
+}
+
+func (akr *apiKeyRepository) GetByPrefix(prefix string) (*dto.ReadAPIKey, error) {
 	var apikey *dto.ReadAPIKey
 
-	if err := akr.db.Model(&model.APIKey{}).First(&apikey, &model.APIKey{Key: key}).Error; err != nil {
+	if err := akr.db.Model(&model.APIKey{}).First(&apikey, &model.APIKey{Prefix: prefix}).Error; err != nil {
 		return nil, err
 	}
 
@@ -59,16 +72,20 @@ func (akr *apiKeyRepository) GetByOwner(owner string, page, perPage int) (*dto.P
 	return pagination.Generate[dto.ReadAPIKey](akr.db.Model(&model.APIKey{}).Where(&model.APIKey{Owner: owner}), page, perPage)
 }
 
-func (akr *apiKeyRepository) RegisterUse(key string) error {
-	return akr.db.Model(&model.APIKey{}).Where(&model.APIKey{Key: key}).Update("times_used", gorm.Expr("times_used + ?", 1)).Error
+func (akr *apiKeyRepository) AddUses(id, amount uint, lastUsedAt time.Time, lastUsedIP string) error {
+	return akr.db.Model(&model.APIKey{}).Where("id = ?", id).Updates(map[string]any{
+		"times_used":   gorm.Expr("times_used + ?", amount),
+		"last_used_at": lastUsedAt,
+		"last_used_ip": lastUsedIP,
+	}).Error
 }
 
-func (akr *apiKeyRepository) Regenerate(oldKey, newKey string) error {
-	if err := akr.db.Where(&model.APIKey{Key: oldKey}).First(&model.APIKey{}).Error; err != nil {
+func (akr *apiKeyRepository) Regenerate(prefix, newHash string) error {
+	if err := akr.db.Where(&model.APIKey{Prefix: prefix}).First(&model.APIKey{}).Error; err != nil {
 		return err
 	}
 
-	if err := akr.db.Model(&model.APIKey{}).Updates(&model.APIKey{Key: newKey}).Error; err != nil {
+	if err := akr.db.Model(&model.APIKey{}).Where(&model.APIKey{Prefix: prefix}).Updates(&model.APIKey{Key: newHash}).Error; err != nil {
 		return err
 	}
 
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type componentTransferRepository struct {
+	db *gorm.DB
+}
+
+type ComponentTransferRepository interface {
+	Create(createModel *model.ComponentTransfer) error
+
+	Get(componentID uint) (*dto.ComponentTransferInfo, error)
+	GetByRecipient(recipientID uint, page, perPage int) (*dto.Pagination[dto.ComponentTransferInfo], error)
+	GetForRecipient(recipientID, transferID uint) (*dto.ComponentTransferInfo, error)
+
+	Accept(transfer *dto.ComponentTransferInfo) error
+
+	Delete(componentID uint) error
+}
+
+var ErrComponentTransferNotFound = errors.New("component transfer not found or expired")
+
+func NewComponentTransferRepository() ComponentTransferRepository {
+	return &componentTransferRepository{db: db.Postgres}
+}
+
+// A component has at most one pending transfer, starting a new one replaces it. Expired transfers are dropped along the way.
+func (ctr *componentTransferRepository) Create(createModel *model.ComponentTransfer) error {
+	return ctr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("component_id = ? OR expires_at <= ?", createModel.ComponentID, time.Now()).Delete(&model.ComponentTransfer{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(createModel).Error
+	})
+}
+
+func (ctr *componentTransferRepository) baseTransferQuery() *gorm.DB {
+	return ctr.db.Table("component_transfers ct").
+		Select(`
+			ct.id AS id,
+			ct.created_at AS created_at,
+			ct.expires_at AS expires_at,
+			ct.component_id AS component_id,
+			c.name AS component_name,
+			COALESCE(co.user_id, 0) AS owner_id,
+			COALESCE(ou.username, '') AS owner_username,
+			ct.issuer_id AS issuer_id,
+			COALESCE(iu.username, '') AS issuer_username,
+			ct.recipient_id AS recipient_id,
+			ru.username AS recipient_username,
+			ct.keep_access AS keep_access
+		`).
+		Joins("JOIN components c ON c.id = ct.component_id AND c.deleted_at IS NULL").
+		Joins("JOIN component_owners co ON co.component_id = ct.component_id").
+		Joins("LEFT JOIN users ou ON ou.id = co.user_id").
+		Joins("LEFT JOIN users iu ON iu.id = ct.issuer_id").
+		Joins("JOIN users ru ON ru.id = ct.recipient_id").
+		Where("ct.expires_at > ?", time.Now())
+}
+
+func (ctr *componentTransferRepository) first(query *gorm.DB) (*dto.ComponentTransferInfo, error) {
+	var transfer dto.ComponentTransferInfo
+
+	result := query.Scan(&transfer)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrComponentTransferNotFound
+	}
+
+	return &transfer, nil
+}
+
+func (ctr *componentTransferRepository) Get(componentID uint) (*dto.ComponentTransferInfo, error) {
+	return ctr.first(ctr.baseTransferQuery().Where("ct.component_id = ?", componentID))
+}
+
+func (ctr *componentTransferRepository) GetByRecipient(recipientID uint, page, perPage int) (*dto.Pagination[dto.ComponentTransferInfo], error) {
+	return pagination.Generate[dto.ComponentTransferInfo](ctr.baseTransferQuery().Where("ct.recipient_id = ?", recipientID).Order("ct.created_at DESC"), page, perPage)
+}
+
+func (ctr *componentTransferRepository) GetForRecipient(recipientID, transferID uint) (*dto.ComponentTransferInfo, error) {
+	return ctr.first(ctr.baseTransferQuery().Where("ct.recipient_id = ? AND ct.id = ?", recipientID, transferID))
+}
+
+// Hands the component to the recipient, who stops being one of its holders, taking it away from the organization owning
+// it if any. When asked for, the previous owner keeps holding the component as if they had bought it for free.
+// transfer.OwnerID is set to whoever owned the component right before.
+func (ctr *componentTransferRepository) Accept(transfer *dto.ComponentTransferInfo) error {
+	return ctr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", transfer.ID).Delete(&model.ComponentTransfer{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrComponentTransferNotFound
+		}
+
+		var owner model.ComponentOwner
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("component_id = ?", transfer.ComponentID).First(&owner).Error; err != nil {
+			return err
+		}
+
+		if owner.UserID != nil {
+			transfer.OwnerID = *owner.UserID
+		}
+
+		if err := tx.Model(&owner).Updates(map[string]any{"user_id": transfer.RecipientID, "organization_id": nil}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("component_id = ? AND user_id IN ?", transfer.ComponentID, []uint{transfer.RecipientID, transfer.OwnerID}).Delete(&model.ComponentHolder{}).Error; err != nil {
+			return err
+		}
+
+		if !transfer.KeepAccess || owner.UserID == nil || transfer.OwnerID == transfer.RecipientID {
+			return nil
+		}
+
+		return tx.Create(&model.ComponentHolder{
+			ComponentID: transfer.ComponentID,
+			UserID:      transfer.OwnerID,
+		}).Error
+	})
+}
+
+func (ctr *componentTransferRepository) Delete(componentID uint) error {
+	result := ctr.db.Where("component_id = ? AND expires_at > ?", componentID, time.Now()).Delete(&model.ComponentTransfer{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrComponentTransferNotFound
+	}
+
+	return nil
+}
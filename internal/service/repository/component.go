@@ -6,6 +6,7 @@ import (
 
 	"github.com/swibly/swibly-api/internal/model"
 	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/contentschema"
 	"github.com/swibly/swibly-api/pkg/db"
 	"github.com/swibly/swibly-api/pkg/pagination"
 	"github.com/swibly/swibly-api/pkg/utils"
@@ -27,13 +28,14 @@ type ComponentRepository interface {
 	GetPublic(issuerID uint, page, perPage int, freeOnly bool) (*dto.Pagination[dto.ComponentInfo], error)
 	GetOwned(issuerID, userID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error)
 	GetByOwnerID(issuerID, ownerID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error)
+	GetByOrganization(issuerID, organizationID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error)
 	GetHoldersByID(issuerID, userID uint, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error)
 	GetTrashed(ownerID uint, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error)
 
 	Search(issuerID uint, search *dto.SearchComponent, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error)
 
-	Buy(issuerID, componentID uint) error
-	Sell(issuerID, componentID uint) error
+	Buy(issuerID, componentID uint, organizationID *uint, idempotencyKey *string) error
+	Sell(issuerID, componentID uint, idempotencyKey *string) error
 
 	SafeDelete(componentID uint) error
 	Restore(componentID uint) error
@@ -71,12 +73,14 @@ func (cr *componentRepository) baseComponentQuery(issuerID uint) *gorm.DB {
 			c.price as price,
       c.budget as budget,
 			co.id AS owner_id,
-			u.id AS owner_id,
-			u.first_name AS owner_first_name,
-			u.last_name AS owner_last_name,
-			u.username AS owner_username,
-      u.profile_picture AS owner_profile_picture,
-			u.verified AS owner_verified,
+			COALESCE(u.id, 0) AS owner_id,
+			COALESCE(u.first_name, '') AS owner_first_name,
+			COALESCE(u.last_name, '') AS owner_last_name,
+			COALESCE(u.username, '') AS owner_username,
+      COALESCE(u.profile_picture, '') AS owner_profile_picture,
+			COALESCE(u.verified, false) AS owner_verified,
+			co.organization_id AS organization_id,
+			COALESCE(o.name, '') AS organization_name,
 			COALESCE((
 				SELECT COUNT(*)
 				FROM component_holders ch
@@ -101,7 +105,8 @@ func (cr *componentRepository) baseComponentQuery(issuerID uint) *gorm.DB {
       (SELECT ch.price_paid FROM component_holders ch WHERE ch.component_id = c.id AND ch.user_id = ?) AS sell_price
 		`, issuerID, issuerID, issuerID).
 		Joins("JOIN component_owners co ON co.component_id = c.id").
-		Joins("JOIN users u ON co.user_id = u.id")
+		Joins("LEFT JOIN users u ON co.user_id = u.id").
+		Joins("LEFT JOIN organizations o ON o.id = co.organization_id")
 }
 
 func (cr *componentRepository) paginateComponents(query *gorm.DB, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error) {
@@ -137,6 +142,10 @@ func convertToComponentInfo(jsonInfo *dto.ComponentInfoJSON) (dto.ComponentInfo,
 		return dto.ComponentInfo{}, err
 	}
 
+	if upgraded, err := contentschema.Upgrade(contentschema.Component, content); err == nil {
+		content = upgraded
+	}
+
 	return dto.ComponentInfo{
 		ID:                  jsonInfo.ID,
 		CreatedAt:           jsonInfo.CreatedAt,
@@ -155,6 +164,8 @@ func convertToComponentInfo(jsonInfo *dto.ComponentInfoJSON) (dto.ComponentInfo,
 		OwnerUsername:       jsonInfo.OwnerUsername,
 		OwnerProfilePicture: jsonInfo.OwnerProfilePicture,
 		OwnerVerified:       jsonInfo.OwnerVerified,
+		OrganizationID:      jsonInfo.OrganizationID,
+		OrganizationName:    jsonInfo.OrganizationName,
 		IsPublic:            jsonInfo.IsPublic,
 		Holders:             jsonInfo.Holders,
 		Bought:              jsonInfo.Bought,
@@ -162,23 +173,46 @@ func convertToComponentInfo(jsonInfo *dto.ComponentInfoJSON) (dto.ComponentInfo,
 	}, nil
 }
 
+// Holders are refunded with the component row locked, so no purchase can slip in while they are
 func refund(tx *gorm.DB, componentID uint) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Unscoped().Where("id = ?", componentID).First(&model.Component{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var componentOwner model.ComponentOwner
+	if err := tx.Where("component_id = ?", componentID).First(&componentOwner).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	var holders []model.ComponentHolder
-	if err := tx.Where("component_id = ?", componentID).Find(&holders).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("component_id = ?", componentID).Find(&holders).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	totalRefund := uint64(0)
+	wallets := []wallet{{UserID: componentOwner.UserID, OrganizationID: componentOwner.OrganizationID}}
 	for _, holder := range holders {
-		var user model.User
-		if err := tx.Where("id = ?", holder.UserID).First(&user).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
+		wallets = append(wallets, wallet{UserID: &holder.UserID, OrganizationID: holder.OrganizationID})
+	}
 
-		user.Arkhoin += uint64(holder.PricePaid)
-		if err := tx.Save(&user).Error; err != nil {
+	if err := lockWallets(tx, wallets...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	totalRefund := uint64(0)
+	for _, holder := range holders {
+		if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+			Type:                       dto.ArkhoinRefund,
+			UserID:                     &holder.UserID,
+			OrganizationID:             holder.OrganizationID,
+			CounterpartyUserID:         componentOwner.UserID,
+			CounterpartyOrganizationID: componentOwner.OrganizationID,
+			ComponentID:                &componentID,
+			Amount:                     int64(holder.PricePaid),
+		}); err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -191,18 +225,29 @@ func refund(tx *gorm.DB, componentID uint) error {
 		}
 	}
 
-	var owner model.User
-	if err := tx.Joins("JOIN component_owners co ON co.user_id = users.id").Where("co.component_id = ?", componentID).First(&owner).Error; err != nil {
+	if totalRefund == 0 || (componentOwner.UserID == nil && componentOwner.OrganizationID == nil) {
+		return nil
+	}
+
+	balance, err := walletBalance(tx, componentOwner.UserID, componentOwner.OrganizationID)
+	if err != nil {
 		tx.Rollback()
 		return err
 	}
 
-	owner.Arkhoin -= totalRefund
-	if owner.Arkhoin < 0 {
-		owner.Arkhoin = 0
+	// Whatever the owner already spent is not taken back, so the wallet never goes below zero
+	taken := min(totalRefund, balance)
+	if taken == 0 {
+		return nil
 	}
 
-	if err := tx.Save(&owner).Error; err != nil {
+	if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+		Type:           dto.ArkhoinRefund,
+		UserID:         componentOwner.UserID,
+		OrganizationID: componentOwner.OrganizationID,
+		ComponentID:    &componentID,
+		Amount:         -int64(taken),
+	}); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -211,13 +256,18 @@ func refund(tx *gorm.DB, componentID uint) error {
 }
 
 func (cr *componentRepository) Create(createModel *dto.ComponentCreation) error {
-	tx := cr.db.Begin()
+	content, err := contentschema.Normalize(contentschema.Component, createModel.Content)
+	if err != nil {
+		return err
+	}
 
-	contentJSON, err := json.Marshal(createModel.Content)
+	contentJSON, err := json.Marshal(content)
 	if err != nil {
 		return err
 	}
 
+	tx := cr.db.Begin()
+
 	component := &model.Component{
 		Name:        createModel.Name,
 		Description: createModel.Description,
@@ -232,8 +282,9 @@ func (cr *componentRepository) Create(createModel *dto.ComponentCreation) error
 	}
 
 	if err := tx.Create(&model.ComponentOwner{
-		ComponentID: component.ID,
-		UserID:      &createModel.OwnerID,
+		ComponentID:    component.ID,
+		UserID:         &createModel.OwnerID,
+		OrganizationID: createModel.OrganizationID,
 	}).Error; err != nil {
 		tx.Rollback()
 		return err
@@ -254,6 +305,16 @@ func (cr *componentRepository) Update(componentID uint, updateModel *dto.Compone
 		return ErrComponentNotFound
 	}
 
+	var content any
+	if updateModel.Content != nil {
+		normalized, err := contentschema.Normalize(contentschema.Component, *updateModel.Content)
+		if err != nil {
+			return err
+		}
+
+		content = normalized
+	}
+
 	tx := cr.db.Begin()
 
 	if updateModel.Public != nil {
@@ -273,7 +334,10 @@ func (cr *componentRepository) Update(componentID uint, updateModel *dto.Compone
 				return err
 			}
 
-			refund(tx, componentID)
+			if err := refund(tx, componentID); err != nil {
+				tx.Rollback()
+				return err
+			}
 		}
 	}
 
@@ -288,7 +352,7 @@ func (cr *componentRepository) Update(componentID uint, updateModel *dto.Compone
 	}
 
 	if updateModel.Content != nil {
-		contentJSON, err := json.Marshal(updateModel.Content)
+		contentJSON, err := json.Marshal(content)
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -361,6 +425,13 @@ func (cr *componentRepository) Get(issuerID uint, componentModel *model.Componen
 		}
 	}
 
+	var organizationName string
+	if componentOwner.OrganizationID != nil {
+		if err := cr.db.Model(&model.Organization{}).Where("id = ?", *componentOwner.OrganizationID).Select("name").Scan(&organizationName).Error; err != nil {
+			return nil, err
+		}
+	}
+
 	var paidPrice *int
 	var sellPrice *int
 	bought := false
@@ -386,6 +457,11 @@ func (cr *componentRepository) Get(issuerID uint, componentModel *model.Componen
 		return nil, err
 	}
 
+	// Old documents are upgraded when read, they are only stored upgraded on the next save
+	if upgraded, err := contentschema.Upgrade(contentschema.Component, contentData); err == nil {
+		contentData = upgraded
+	}
+
 	var totalSells int64
 	if err := cr.db.Model(&model.ComponentHolder{}).Where("component_id = ?", component.ID).Select("COALESCE(SUM(price_paid), 0)").Scan(&totalSells).Error; err != nil {
 		return nil, err
@@ -405,6 +481,8 @@ func (cr *componentRepository) Get(issuerID uint, componentModel *model.Componen
 		OwnerUsername:       owner.Username,
 		OwnerProfilePicture: owner.ProfilePicture,
 		OwnerVerified:       owner.Verified,
+		OrganizationID:      componentOwner.OrganizationID,
+		OrganizationName:    organizationName,
 		Budget:              component.Budget,
 		Price:               component.Price,
 		PaidPrice:           paidPrice,
@@ -433,14 +511,34 @@ func (cr *componentRepository) GetTrashed(ownerID uint, page, perPage int) (*dto
 	query := cr.baseComponentQuery(ownerID).
 		Unscoped().
 		Where("c.deleted_at IS NOT NULL").
-		Where(`u.id = ?`, ownerID)
+		Where(`
+			(u.id = ? AND co.organization_id IS NULL) OR
+			EXISTS (
+				SELECT 1
+				FROM organization_members om
+				WHERE om.organization_id = co.organization_id
+				AND om.user_id = ?
+				AND om.role IN ?
+			)
+		`, ownerID, ownerID, []string{dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin})
 
 	return cr.paginateComponents(query, page, perPage)
 }
 
 func (cr *componentRepository) GetByOwnerID(issuerID, ownerID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error) {
 	query := cr.baseComponentQuery(issuerID).
-		Where("co.user_id = ?", ownerID)
+		Where("co.user_id = ? AND co.organization_id IS NULL", ownerID)
+
+	if onlyPublic {
+		query = query.Where("EXISTS (SELECT 1 FROM component_publications cp WHERE cp.component_id = c.id)")
+	}
+
+	return cr.paginateComponents(query, page, perPage)
+}
+
+func (cr *componentRepository) GetByOrganization(issuerID, organizationID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error) {
+	query := cr.baseComponentQuery(issuerID).
+		Where("co.organization_id = ?", organizationID)
 
 	if onlyPublic {
 		query = query.Where("EXISTS (SELECT 1 FROM component_publications cp WHERE cp.component_id = c.id)")
@@ -453,7 +551,7 @@ func (cr *componentRepository) GetOwned(issuerID, userID uint, onlyPublic bool,
 	query := cr.baseComponentQuery(issuerID).
 		Joins("LEFT JOIN component_holders ch ON ch.component_id = c.id").
 		Joins("LEFT JOIN component_owners coo ON coo.component_id = c.id").
-		Where("ch.user_id = ? OR co.user_id = ?", userID, userID)
+		Where("ch.user_id = ? OR (co.user_id = ? AND co.organization_id IS NULL)", userID, userID)
 
 	if onlyPublic {
 		query = query.Where("EXISTS (SELECT 1 FROM component_publications cp WHERE cp.component_id = c.id)")
@@ -519,118 +617,133 @@ func (cr *componentRepository) Search(issuerID uint, search *dto.SearchComponent
 	return cr.paginateComponents(query, page, perPage)
 }
 
-func (cr *componentRepository) Buy(issuerID, componentID uint) error {
-	var component model.Component
-	var user model.User
-	var owner model.User
-	var componentOwner model.ComponentOwner
+// Charges the issuer, or the organization they buy for, and pays the owner of the component, or the organization owning it
+// Runs in one transaction with every wallet involved locked, the one of the issuer included even when an
+// organization pays. Purchases of the same user happen one at a time, and a retry sent with the same idempotency
+// key sees what the first request did.
+func (cr *componentRepository) Buy(issuerID, componentID uint, organizationID *uint, idempotencyKey *string) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		// Shared lock so the component cannot be trashed, and its holders refunded, halfway through the purchase
+		var component model.Component
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id = ?", componentID).First(&component).Error; err != nil {
+			return err
+		}
 
-	err := cr.db.Where("id = ?", componentID).First(&component).Error
-	if err != nil {
-		return err
-	}
+		var componentOwner model.ComponentOwner
+		if err := tx.Where("component_id = ?", componentID).First(&componentOwner).Error; err != nil {
+			return err
+		}
 
-	err = cr.db.Where("id = ?", issuerID).First(&user).Error
-	if err != nil {
-		return err
-	}
+		if err := lockWallets(tx,
+			wallet{UserID: &issuerID},
+			wallet{UserID: &issuerID, OrganizationID: organizationID},
+			wallet{UserID: componentOwner.UserID, OrganizationID: componentOwner.OrganizationID},
+		); err != nil {
+			return err
+		}
 
-	if user.Arkhoin < uint64(component.Price) {
-		return ErrInsufficientArkhoins
-	}
+		if err := idempotentReplay(tx, issuerID, idempotencyKey, dto.ArkhoinPurchase, componentID); err != nil {
+			return err
+		}
 
-	var holder model.ComponentHolder
-	err = cr.db.Where("component_id = ? AND user_id = ?", componentID, issuerID).First(&holder).Error
-	if err == nil {
-		return ErrComponentAlreadyOwned
-	}
+		var holders int64
+		if err := tx.Model(&model.ComponentHolder{}).Where("component_id = ? AND user_id = ?", componentID, issuerID).Count(&holders).Error; err != nil {
+			return err
+		}
 
-	err = cr.db.Where("component_id = ?", componentID).First(&componentOwner).Error
-	if err != nil {
-		return err
-	}
+		if holders > 0 {
+			return ErrComponentAlreadyOwned
+		}
 
-	if componentOwner.UserID != nil {
-		err = cr.db.Where("id = ?", *componentOwner.UserID).First(&owner).Error
-		if err != nil {
-			return err
+		if componentOwner.UserID != nil && *componentOwner.UserID == issuerID {
+			return ErrComponentOwnerCannotBuy
 		}
 
-		if *componentOwner.UserID == issuerID {
+		if componentOwner.OrganizationID != nil && organizationID != nil && *componentOwner.OrganizationID == *organizationID {
 			return ErrComponentOwnerCannotBuy
 		}
 
-		owner.Arkhoin += uint64(component.Price)
-		err = cr.db.Save(&owner).Error
-		if err != nil {
+		if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+			Type:                       dto.ArkhoinPurchase,
+			UserID:                     &issuerID,
+			OrganizationID:             organizationID,
+			CounterpartyUserID:         componentOwner.UserID,
+			CounterpartyOrganizationID: componentOwner.OrganizationID,
+			ComponentID:                &componentID,
+			Amount:                     -int64(component.Price),
+			IdempotencyKey:             idempotencyKey,
+		}); err != nil {
 			return err
 		}
-	}
-
-	user.Arkhoin -= uint64(component.Price)
-	err = cr.db.Save(&user).Error
-	if err != nil {
-		return err
-	}
 
-	newHolder := model.ComponentHolder{
-		ComponentID: componentID,
-		UserID:      issuerID,
-		PricePaid:   component.Price,
-	}
-
-	err = cr.db.Create(&newHolder).Error
-	if err != nil {
-		return err
-	}
+		if componentOwner.UserID != nil || componentOwner.OrganizationID != nil {
+			if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+				Type:                       dto.ArkhoinSale,
+				UserID:                     componentOwner.UserID,
+				OrganizationID:             componentOwner.OrganizationID,
+				CounterpartyUserID:         &issuerID,
+				CounterpartyOrganizationID: organizationID,
+				ComponentID:                &componentID,
+				Amount:                     int64(component.Price),
+			}); err != nil {
+				return err
+			}
+		}
 
-	return nil
+		return tx.Create(&model.ComponentHolder{
+			ComponentID:    componentID,
+			UserID:         issuerID,
+			PricePaid:      component.Price,
+			OrganizationID: organizationID,
+		}).Error
+	})
 }
 
-func (cr *componentRepository) Sell(issuerID, componentID uint) error {
-	var component model.Component
-	var user model.User
-	var holder model.ComponentHolder
-	var componentOwner model.ComponentOwner
-
-	err := cr.db.Where("id = ?", componentID).First(&component).Error
-	if err != nil {
-		return err
-	}
-
-	err = cr.db.Where("component_id = ? AND user_id = ?", componentID, issuerID).First(&holder).Error
-	if err != nil {
-		return ErrComponentNotOwned
-	}
+// Locks the same way Buy does, and the holder row on top of it so the price is only paid back once
+func (cr *componentRepository) Sell(issuerID, componentID uint, idempotencyKey *string) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		var component model.Component
+		if err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id = ?", componentID).First(&component).Error; err != nil {
+			return err
+		}
 
-	err = cr.db.Where("id = ?", issuerID).First(&user).Error
-	if err != nil {
-		return err
-	}
+		if err := lockWallets(tx, wallet{UserID: &issuerID}); err != nil {
+			return err
+		}
 
-	err = cr.db.Where("component_id = ?", componentID).First(&componentOwner).Error
-	if err != nil {
-		return err
-	}
+		if err := idempotentReplay(tx, issuerID, idempotencyKey, dto.ArkhoinRefund, componentID); err != nil {
+			return err
+		}
 
-	if componentOwner.UserID != nil && *componentOwner.UserID == issuerID {
-		return ErrComponentOwnerCannotSell
-	}
+		var holder model.ComponentHolder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("component_id = ? AND user_id = ?", componentID, issuerID).First(&holder).Error; err != nil {
+			return ErrComponentNotOwned
+		}
 
-	refund := int(holder.PricePaid)
-	user.Arkhoin += uint64(refund)
+		var componentOwner model.ComponentOwner
+		if err := tx.Where("component_id = ?", componentID).First(&componentOwner).Error; err != nil {
+			return err
+		}
 
-	err = cr.db.Save(&user).Error
-	if err != nil {
-		return err
-	}
+		if componentOwner.UserID != nil && *componentOwner.UserID == issuerID {
+			return ErrComponentOwnerCannotSell
+		}
 
-	err = cr.db.Delete(&holder).Error
-	if err != nil {
-		return err
-	}
+		if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+			Type:                       dto.ArkhoinRefund,
+			UserID:                     &issuerID,
+			OrganizationID:             holder.OrganizationID,
+			CounterpartyUserID:         componentOwner.UserID,
+			CounterpartyOrganizationID: componentOwner.OrganizationID,
+			ComponentID:                &componentID,
+			Amount:                     int64(holder.PricePaid),
+			IdempotencyKey:             idempotencyKey,
+		}); err != nil {
+			return err
+		}
 
-	return nil
+		return tx.Delete(&holder).Error
+	})
 }
 
 func (cr *componentRepository) SafeDelete(componentID uint) error {
@@ -639,19 +752,23 @@ func (cr *componentRepository) SafeDelete(componentID uint) error {
 
 	err := tx.Unscoped().Where("id = ?", componentID).First(&component).Error
 	if err != nil {
+		tx.Rollback()
 		return err
 	}
 
 	if !component.DeletedAt.Valid {
-		refund(tx, componentID)
+		// A refund that fails must not let the component go without paying its holders back
+		if err := refund(tx, componentID); err != nil {
+			tx.Rollback()
+			return err
+		}
 
 		if err := tx.Delete(&component).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
 
-		tx.Commit()
-		return nil
+		return tx.Commit().Error
 	}
 
 	tx.Rollback()
@@ -725,6 +842,7 @@ func (cr *componentRepository) ClearTrash(userID uint) error {
 				FROM component_owners co
 				WHERE co.component_id = component_holders.component_id
 				AND co.user_id = ?
+				AND co.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.ComponentHolder{}).Error; err != nil {
@@ -739,6 +857,7 @@ func (cr *componentRepository) ClearTrash(userID uint) error {
 				FROM component_owners co
 				WHERE co.component_id = component_publications.component_id
 				AND co.user_id = ?
+				AND co.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.ComponentPublication{}).Error; err != nil {
@@ -753,6 +872,7 @@ func (cr *componentRepository) ClearTrash(userID uint) error {
 				FROM component_owners co
 				WHERE co.component_id = component_owners.component_id
 				AND co.user_id = ?
+				AND co.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.ComponentOwner{}).Error; err != nil {
@@ -768,6 +888,7 @@ func (cr *componentRepository) ClearTrash(userID uint) error {
 				FROM component_owners co
 				WHERE co.component_id = components.id
 				AND co.user_id = ?
+				AND co.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.Component{}).Error; err != nil {
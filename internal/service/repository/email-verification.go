@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/pkg/db"
+	"gorm.io/gorm"
+)
+
+type emailVerificationRepository struct {
+	db *gorm.DB
+}
+
+type EmailVerificationRepository interface {
+	Request(userID uint, email string, cooldown time.Duration) (*model.EmailVerificationKey, error)
+	Verify(key string) error
+}
+
+var ErrEmailVerificationThrottled = errors.New("a verification email was sent too recently")
+
+func NewEmailVerificationRepository() EmailVerificationRepository {
+	return &emailVerificationRepository{db: db.Postgres}
+}
+
+// Creates or renews the verification key of the user. If the last email was sent less than `cooldown` ago,
+// ErrEmailVerificationThrottled is returned and the key is left untouched
+func (evr *emailVerificationRepository) Request(userID uint, email string, cooldown time.Duration) (*model.EmailVerificationKey, error) {
+	tx := evr.db.Begin()
+
+	verification := &model.EmailVerificationKey{}
+	err := tx.Where("user_id = ?", userID).First(verification).Error
+
+	if err == gorm.ErrRecordNotFound {
+		verification = &model.EmailVerificationKey{
+			UserID:     userID,
+			Key:        uuid.New(),
+			Email:      email,
+			LastSentAt: time.Now(),
+		}
+		if err := tx.Create(verification).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else if err == nil {
+		if verification.Email == email && time.Since(verification.LastSentAt) < cooldown {
+			tx.Rollback()
+			return nil, ErrEmailVerificationThrottled
+		}
+
+		verification.Key = uuid.New()
+		verification.Email = email
+		verification.ExpiresAt = time.Now().Add(24 * time.Hour)
+		verification.LastSentAt = time.Now()
+
+		if err := tx.Model(&model.EmailVerificationKey{}).Where("user_id = ?", userID).UpdateColumns(map[string]interface{}{
+			"key":          verification.Key,
+			"email":        verification.Email,
+			"expires_at":   verification.ExpiresAt,
+			"last_sent_at": verification.LastSentAt,
+		}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	} else {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return verification, tx.Commit().Error
+}
+
+func (evr *emailVerificationRepository) Verify(key string) error {
+	if _, err := uuid.Parse(key); err != nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	verification := model.EmailVerificationKey{}
+	if err := evr.db.Where("key = ?", key).First(&verification).Error; err != nil {
+		return err
+	}
+
+	if verification.ExpiresAt.Before(time.Now()) {
+		return gorm.ErrRecordNotFound
+	}
+
+	tx := evr.db.Begin()
+
+	// Only verify the address the email was sent to
+	result := tx.Model(&model.User{}).Where("id = ? AND email = ?", verification.UserID, verification.Email).Update("verified", true)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Where("key = ?", key).Delete(&model.EmailVerificationKey{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
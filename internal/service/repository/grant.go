@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type grantRepository struct {
+	db *gorm.DB
+}
+
+type GrantRepository interface {
+	Apply(issuerID *uint, userIDs []uint, createModel *dto.GrantCreation, airdropID *uint) ([]dto.XPChange, error)
+	GetAll(userID *uint, page, perPage int) (*dto.Pagination[dto.GrantInfo], error)
+
+	ResolveUsernames(usernames []string) (ids []uint, missing []string, err error)
+
+	CreateAirdrop(airdrop *model.Airdrop, userIDs []uint) error
+	GetAirdrops(page, perPage int) (*dto.Pagination[dto.AirdropInfo], error)
+	DeleteAirdrop(airdropID uint) error
+
+	RunAirdrop(airdropID uint) (*model.Airdrop, []uint, []dto.XPChange, error)
+	GetDueAirdrops(now time.Time) ([]uint, error)
+}
+
+var (
+	ErrInsufficientXP  = errors.New("insufficient xp")
+	ErrAirdropNotFound = errors.New("airdrop not found or already executed")
+)
+
+func NewGrantRepository() GrantRepository {
+	return &grantRepository{db: db.Postgres}
+}
+
+// Changes the balance or the XP of every user, all or nothing, and writes an audit entry for each of them.
+// Returns how the XP of each user changed, when XP is granted.
+func (gr *grantRepository) Apply(issuerID *uint, userIDs []uint, createModel *dto.GrantCreation, airdropID *uint) ([]dto.XPChange, error) {
+	var changes []dto.XPChange
+
+	err := gr.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		changes, err = applyGrant(tx, issuerID, userIDs, createModel, airdropID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func applyGrant(tx *gorm.DB, issuerID *uint, userIDs []uint, createModel *dto.GrantCreation, airdropID *uint) ([]dto.XPChange, error) {
+	var changes []dto.XPChange
+
+	wallets := make([]wallet, 0, len(userIDs))
+	for _, userID := range userIDs {
+		wallets = append(wallets, wallet{UserID: &userID})
+	}
+
+	if err := lockWallets(tx, wallets...); err != nil {
+		return nil, err
+	}
+
+	// Chunked for the same reason lockWallets is
+	usernames := map[uint]string{}
+	for start := 0; start < len(userIDs); start += 10000 {
+		var users []model.User
+		if err := tx.Select("id", "username").Where("id IN ?", userIDs[start:min(start+10000, len(userIDs))]).Find(&users).Error; err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			usernames[user.ID] = user.Username
+		}
+	}
+
+	var issuerUsername string
+	if issuerID != nil {
+		if err := tx.Model(&model.User{}).Select("username").Where("id = ?", *issuerID).Scan(&issuerUsername).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, userID := range userIDs {
+		switch createModel.Currency {
+		case dto.GrantArkhoin:
+			if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+				Type:   dto.ArkhoinGrant,
+				UserID: &userID,
+				Amount: createModel.Amount,
+				Reason: createModel.Reason,
+			}); err != nil {
+				return nil, err
+			}
+		case dto.GrantXP:
+			var user model.User
+			result := tx.Model(&user).
+				Clauses(clause.Returning{Columns: []clause.Column{{Name: "xp"}}}).
+				Where("id = ? AND xp + ? >= 0", userID, createModel.Amount).
+				Update("xp", gorm.Expr("xp + ?", createModel.Amount))
+			if result.Error != nil {
+				return nil, result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return nil, ErrInsufficientXP
+			}
+
+			changes = append(changes, dto.XPChange{
+				UserID: userID,
+				Before: uint64(int64(user.XP) - createModel.Amount),
+				After:  user.XP,
+			})
+		}
+
+		if err := tx.Create(&model.Grant{
+			IssuerID:       issuerID,
+			IssuerUsername: issuerUsername,
+			UserID:         &userID,
+			Username:       usernames[userID],
+			Currency:       createModel.Currency,
+			Amount:         createModel.Amount,
+			Reason:         createModel.Reason,
+			AirdropID:      airdropID,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+func (gr *grantRepository) GetAll(userID *uint, page, perPage int) (*dto.Pagination[dto.GrantInfo], error) {
+	query := gr.db.Table("grants g").
+		Select(`
+			g.id AS id,
+			g.created_at AS created_at,
+			g.issuer_id AS issuer_id,
+			g.issuer_username AS issuer_username,
+			g.user_id AS user_id,
+			g.username AS username,
+			g.currency AS currency,
+			g.amount AS amount,
+			g.reason AS reason,
+			g.airdrop_id AS airdrop_id
+		`).
+		Order("g.id DESC")
+
+	if userID != nil {
+		query = query.Where("g.user_id = ?", *userID)
+	}
+
+	return pagination.Generate[dto.GrantInfo](query, page, perPage)
+}
+
+// Usernames are matched ignoring case, duplicates only count once
+func (gr *grantRepository) ResolveUsernames(usernames []string) ([]uint, []string, error) {
+	lowered := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		lowered = append(lowered, strings.ToLower(username))
+	}
+
+	var users []model.User
+	if err := gr.db.Select("id", "username").Where("LOWER(username) IN ?", lowered).Find(&users).Error; err != nil {
+		return nil, nil, err
+	}
+
+	found := map[string]bool{}
+	ids := make([]uint, 0, len(users))
+	for _, user := range users {
+		found[strings.ToLower(user.Username)] = true
+		ids = append(ids, user.ID)
+	}
+
+	missing := []string{}
+	for i, username := range lowered {
+		if !found[username] {
+			missing = append(missing, usernames[i])
+		}
+	}
+
+	return ids, missing, nil
+}
+
+func (gr *grantRepository) CreateAirdrop(airdrop *model.Airdrop, userIDs []uint) error {
+	return gr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(airdrop).Error; err != nil {
+			return err
+		}
+
+		if len(userIDs) == 0 {
+			return nil
+		}
+
+		recipients := make([]model.AirdropRecipient, 0, len(userIDs))
+		for _, userID := range userIDs {
+			recipients = append(recipients, model.AirdropRecipient{AirdropID: airdrop.ID, UserID: userID})
+		}
+
+		return tx.Create(&recipients).Error
+	})
+}
+
+func (gr *grantRepository) GetAirdrops(page, perPage int) (*dto.Pagination[dto.AirdropInfo], error) {
+	query := gr.db.Table("airdrops a").
+		Select(`
+			a.id AS id,
+			a.created_at AS created_at,
+			a.issuer_id AS issuer_id,
+			COALESCE(iu.username, '') AS issuer_username,
+			a.currency AS currency,
+			a.amount AS amount,
+			a.reason AS reason,
+			a.everyone AS everyone,
+			(
+				SELECT COUNT(*)
+				FROM airdrop_recipients ar
+				WHERE ar.airdrop_id = a.id
+			) AS recipients,
+			a.scheduled_at AS scheduled_at,
+			a.executed_at AS executed_at
+		`).
+		Joins("LEFT JOIN users iu ON iu.id = a.issuer_id").
+		Order("a.scheduled_at DESC")
+
+	return pagination.Generate[dto.AirdropInfo](query, page, perPage)
+}
+
+// Only airdrops that did not run yet can be canceled
+func (gr *grantRepository) DeleteAirdrop(airdropID uint) error {
+	result := gr.db.Where("id = ? AND executed_at IS NULL", airdropID).Delete(&model.Airdrop{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrAirdropNotFound
+	}
+
+	return nil
+}
+
+func (gr *grantRepository) GetDueAirdrops(now time.Time) ([]uint, error) {
+	var ids []uint
+	err := gr.db.Model(&model.Airdrop{}).Where("executed_at IS NULL AND scheduled_at <= ?", now).Order("scheduled_at ASC").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Grants the airdrop to its recipients and marks it as executed, returning who got it. Airdrops being run
+// somewhere else are skipped, with ErrAirdropNotFound.
+func (gr *grantRepository) RunAirdrop(airdropID uint) (*model.Airdrop, []uint, []dto.XPChange, error) {
+	var airdrop model.Airdrop
+	var recipients []uint
+	var changes []dto.XPChange
+
+	err := gr.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id = ? AND executed_at IS NULL", airdropID).
+			First(&airdrop).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAirdropNotFound
+		} else if err != nil {
+			return err
+		}
+
+		if airdrop.Everyone {
+			err = tx.Model(&model.User{}).Order("id").Pluck("id", &recipients).Error
+		} else {
+			err = tx.Model(&model.AirdropRecipient{}).Where("airdrop_id = ?", airdrop.ID).Order("user_id").Pluck("user_id", &recipients).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		changes, err = applyGrant(tx, airdrop.IssuerID, recipients, &dto.GrantCreation{
+			Currency: airdrop.Currency,
+			Amount:   airdrop.Amount,
+			Reason:   airdrop.Reason,
+		}, &airdrop.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		airdrop.ExecutedAt = &now
+
+		return tx.Model(&airdrop).Update("executed_at", now).Error
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return &airdrop, recipients, changes, nil
+}
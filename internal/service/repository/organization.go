@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+type OrganizationRepository interface {
+	Create(createModel *dto.OrganizationCreation, ownerID uint) (uint, error)
+	Update(organizationID uint, updateModel *dto.OrganizationUpdate) error
+	Delete(organizationID, issuerID uint) error
+
+	GetByName(issuerID uint, name string) (*dto.OrganizationInfo, error)
+	GetByMember(issuerID, userID uint, page, perPage int) (*dto.Pagination[dto.OrganizationInfo], error)
+
+	GetMembers(organizationID uint, page, perPage int) (*dto.Pagination[dto.OrganizationMemberInfo], error)
+	GetRole(organizationID, userID uint) (string, error)
+	SetMember(organizationID, userID uint, role string) error
+	RemoveMember(organizationID, userID uint) error
+
+	Deposit(organizationID, userID uint, amount uint64) error
+}
+
+var (
+	ErrOrganizationNameTaken = errors.New("organization name is already taken")
+	ErrOrganizationLastOwner = errors.New("organization must keep at least one owner")
+	ErrNotOrganizationMember = errors.New("user is not a member of the organization")
+)
+
+func NewOrganizationRepository() OrganizationRepository {
+	return &organizationRepository{db: db.Postgres}
+}
+
+func (orr *organizationRepository) nameTaken(tx *gorm.DB, name string, exceptID uint) (bool, error) {
+	var count int64
+	err := tx.Model(&model.Organization{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+// The creator becomes the first owner
+func (orr *organizationRepository) Create(createModel *dto.OrganizationCreation, ownerID uint) (uint, error) {
+	organization := &model.Organization{
+		Name:        createModel.Name,
+		DisplayName: createModel.DisplayName,
+		Bio:         createModel.Bio,
+	}
+
+	err := orr.db.Transaction(func(tx *gorm.DB) error {
+		if taken, err := orr.nameTaken(tx, createModel.Name, 0); err != nil {
+			return err
+		} else if taken {
+			return ErrOrganizationNameTaken
+		}
+
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         ownerID,
+			Role:           dto.OrganizationRoleOwner,
+		}).Error
+	})
+
+	return organization.ID, err
+}
+
+func (orr *organizationRepository) Update(organizationID uint, updateModel *dto.OrganizationUpdate) error {
+	return orr.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{}
+
+		if updateModel.Name != nil {
+			if taken, err := orr.nameTaken(tx, *updateModel.Name, organizationID); err != nil {
+				return err
+			} else if taken {
+				return ErrOrganizationNameTaken
+			}
+
+			updates["name"] = *updateModel.Name
+		}
+
+		if updateModel.DisplayName != nil {
+			updates["display_name"] = *updateModel.DisplayName
+		}
+
+		if updateModel.Bio != nil {
+			updates["bio"] = *updateModel.Bio
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+
+		return tx.Model(&model.Organization{}).Where("id = ?", organizationID).Updates(updates).Error
+	})
+}
+
+// Projects and components of the organization go back to the members who created them
+// Projects and components go back to the members who created them, or to the issuer when their creator is gone, and
+// whatever is left in the wallet is paid out to the issuer
+func (orr *organizationRepository) Delete(organizationID, issuerID uint) error {
+	return orr.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWallets(tx, wallet{UserID: &issuerID}, wallet{OrganizationID: &organizationID}); err != nil {
+			return err
+		}
+
+		balance, err := walletBalance(tx, nil, &organizationID)
+		if err != nil {
+			return err
+		}
+
+		if balance > 0 {
+			if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+				Type:               dto.ArkhoinTransfer,
+				OrganizationID:     &organizationID,
+				CounterpartyUserID: &issuerID,
+				Amount:             -int64(balance),
+			}); err != nil {
+				return err
+			}
+
+			if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+				Type:                       dto.ArkhoinTransfer,
+				UserID:                     &issuerID,
+				CounterpartyOrganizationID: &organizationID,
+				Amount:                     int64(balance),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&model.ProjectOwner{}).Where("organization_id = ? AND user_id IS NULL", organizationID).Update("user_id", issuerID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.ProjectOwner{}).Where("organization_id = ?", organizationID).Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.ComponentOwner{}).Where("organization_id = ? AND user_id IS NULL", organizationID).Update("user_id", issuerID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.ComponentOwner{}).Where("organization_id = ?", organizationID).Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.ComponentHolder{}).Where("organization_id = ?", organizationID).Update("organization_id", nil).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("organization_id = ?", organizationID).Delete(&model.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", organizationID).Delete(&model.Organization{}).Error
+	})
+}
+
+func (orr *organizationRepository) baseOrganizationQuery(issuerID uint) *gorm.DB {
+	return orr.db.Table("organizations o").
+		Select(`
+			o.id AS id,
+			o.created_at AS created_at,
+			o.updated_at AS updated_at,
+			o.name AS name,
+			o.display_name AS display_name,
+			o.bio AS bio,
+			o.arkhoin AS arkhoin,
+			(
+				SELECT COUNT(*)
+				FROM organization_members om
+				WHERE om.organization_id = o.id
+			) AS members,
+			COALESCE((
+				SELECT om.role
+				FROM organization_members om
+				WHERE om.organization_id = o.id AND om.user_id = ?
+			), '') AS role
+		`, issuerID)
+}
+
+func (orr *organizationRepository) GetByName(issuerID uint, name string) (*dto.OrganizationInfo, error) {
+	var organization dto.OrganizationInfo
+
+	result := orr.baseOrganizationQuery(issuerID).Where("LOWER(o.name) = LOWER(?)", name).Scan(&organization)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &organization, nil
+}
+
+func (orr *organizationRepository) GetByMember(issuerID, userID uint, page, perPage int) (*dto.Pagination[dto.OrganizationInfo], error) {
+	query := orr.baseOrganizationQuery(issuerID).
+		Joins("JOIN organization_members m ON m.organization_id = o.id AND m.user_id = ?", userID).
+		Order("m.created_at ASC")
+
+	return pagination.Generate[dto.OrganizationInfo](query, page, perPage)
+}
+
+func (orr *organizationRepository) GetMembers(organizationID uint, page, perPage int) (*dto.Pagination[dto.OrganizationMemberInfo], error) {
+	query := orr.db.Table("organization_members om").
+		Select(`
+			u.id AS id,
+			u.first_name AS first_name,
+			u.last_name AS last_name,
+			u.username AS username,
+			u.profile_picture AS profile_picture,
+			u.verified AS verified,
+			om.role AS role,
+			om.created_at AS since
+		`).
+		Joins("JOIN users u ON u.id = om.user_id").
+		Where("om.organization_id = ?", organizationID).
+		Order("om.created_at ASC")
+
+	return pagination.Generate[dto.OrganizationMemberInfo](query, page, perPage)
+}
+
+// Empty when the user is not a member
+func (orr *organizationRepository) GetRole(organizationID, userID uint) (string, error) {
+	var member model.OrganizationMember
+
+	err := orr.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+
+	return member.Role, err
+}
+
+// Counts the owners left if the user stopped being one, with the rows locked so two owners cannot step down at once
+func (orr *organizationRepository) ownersLeft(tx *gorm.DB, organizationID, userID uint) (int64, error) {
+	var owners []model.OrganizationMember
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ?", organizationID, dto.OrganizationRoleOwner).
+		Find(&owners).Error; err != nil {
+		return 0, err
+	}
+
+	left := int64(0)
+	for _, owner := range owners {
+		if owner.UserID != userID {
+			left++
+		}
+	}
+
+	return left, nil
+}
+
+// Adds the user to the organization or changes their role
+func (orr *organizationRepository) SetMember(organizationID, userID uint, role string) error {
+	return orr.db.Transaction(func(tx *gorm.DB) error {
+		if role != dto.OrganizationRoleOwner {
+			if left, err := orr.ownersLeft(tx, organizationID, userID); err != nil {
+				return err
+			} else if left == 0 {
+				return ErrOrganizationLastOwner
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+		}).Create(&model.OrganizationMember{
+			OrganizationID: organizationID,
+			UserID:         userID,
+			Role:           role,
+		}).Error
+	})
+}
+
+func (orr *organizationRepository) RemoveMember(organizationID, userID uint) error {
+	return orr.db.Transaction(func(tx *gorm.DB) error {
+		if left, err := orr.ownersLeft(tx, organizationID, userID); err != nil {
+			return err
+		} else if left == 0 {
+			return ErrOrganizationLastOwner
+		}
+
+		result := tx.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&model.OrganizationMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrNotOrganizationMember
+		}
+
+		return nil
+	})
+}
+
+// Moves Arkhoins from a member to the organization wallet
+func (orr *organizationRepository) Deposit(organizationID, userID uint, amount uint64) error {
+	return orr.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWallets(tx, wallet{UserID: &userID}, wallet{OrganizationID: &organizationID}); err != nil {
+			return err
+		}
+
+		if err := moveArkhoins(tx, &model.ArkhoinTransaction{
+			Type:                       dto.ArkhoinTransfer,
+			UserID:                     &userID,
+			CounterpartyOrganizationID: &organizationID,
+			Amount:                     -int64(amount),
+		}); err != nil {
+			return err
+		}
+
+		return moveArkhoins(tx, &model.ArkhoinTransaction{
+			Type:               dto.ArkhoinTransfer,
+			UserID:             &userID,
+			OrganizationID:     &organizationID,
+			CounterpartyUserID: &userID,
+			Amount:             int64(amount),
+		})
+	})
+}
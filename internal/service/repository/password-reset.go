@@ -102,6 +102,11 @@ func (prr *passwordResetRepository) Reset(key, newPassword string) error {
 		return err
 	}
 
+	if err := tx.Model(&model.Session{}).Where("user_id = ? AND revoked_at IS NULL", passwordReset.UserID).Update("revoked_at", time.Now()).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	return tx.Commit().Error
 }
 
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/swibly/swibly-api/internal/model"
 	"github.com/swibly/swibly-api/pkg/db"
 	"gorm.io/gorm"
@@ -11,13 +13,43 @@ type permissionRepository struct {
 }
 
 type PermissionRepository interface {
+	GetAll() ([]*model.Permission, error)
+	GetByName(name string) (*model.Permission, error)
 	GetByUser(userID uint) ([]*model.Permission, error)
+
+	Grant(userID, permissionID uint) error
+	Revoke(userID, permissionID uint) error
 }
 
+var (
+	ErrPermissionAlreadyGranted = errors.New("permission is already granted to the user")
+	ErrPermissionNotGranted     = errors.New("permission is not granted to the user")
+)
+
 func NewPermissionRepository() PermissionRepository {
 	return &permissionRepository{db: db.Postgres}
 }
 
+func (pr *permissionRepository) GetAll() ([]*model.Permission, error) {
+	var permissions []*model.Permission
+
+	if err := pr.db.Order("name ASC").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+func (pr *permissionRepository) GetByName(name string) (*model.Permission, error) {
+	var permission *model.Permission
+
+	if err := pr.db.First(&permission, &model.Permission{Name: name}).Error; err != nil {
+		return nil, err
+	}
+
+	return permission, nil
+}
+
 func (pr *permissionRepository) GetByUser(userID uint) ([]*model.Permission, error) {
 	var permissions []*model.Permission
 
@@ -30,3 +62,31 @@ func (pr *permissionRepository) GetByUser(userID uint) ([]*model.Permission, err
 
 	return permissions, err
 }
+
+func (pr *permissionRepository) Grant(userID, permissionID uint) error {
+	return pr.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.UserPermission{}).Where("user_id = ? AND permission_id = ?", userID, permissionID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrPermissionAlreadyGranted
+		}
+
+		return tx.Create(&model.UserPermission{UserID: userID, PermissionID: permissionID}).Error
+	})
+}
+
+func (pr *permissionRepository) Revoke(userID, permissionID uint) error {
+	result := pr.db.Where("user_id = ? AND permission_id = ?", userID, permissionID).Unscoped().Delete(&model.UserPermission{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrPermissionNotGranted
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type projectInviteRepository struct {
+	db *gorm.DB
+}
+
+type ProjectInviteRepository interface {
+	Create(createModel *model.ProjectInvite) error
+
+	GetByProject(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectInviteInfo], error)
+	GetByInvitee(invitee *dto.UserProfile, page, perPage int) (*dto.Pagination[dto.ProjectInviteInfo], error)
+	GetForInvitee(invitee *dto.UserProfile, inviteID uint) (*dto.ProjectInviteInfo, error)
+
+	Delete(projectID, inviteID uint) error
+}
+
+var ErrInviteNotFound = errors.New("invite not found or expired")
+
+func NewProjectInviteRepository() ProjectInviteRepository {
+	return &projectInviteRepository{db: db.Postgres}
+}
+
+// Replaces any pending invite of the same person to the same project. Expired invites are dropped along the way.
+func (pir *projectInviteRepository) Create(createModel *model.ProjectInvite) error {
+	createModel.Email = strings.ToLower(createModel.Email)
+
+	return pir.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("project_id = ?", createModel.ProjectID)
+		if createModel.UserID != nil {
+			query = query.Where("user_id = ?", *createModel.UserID)
+		} else {
+			query = query.Where("user_id IS NULL AND email = ?", createModel.Email)
+		}
+
+		if err := query.Delete(&model.ProjectInvite{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("expires_at <= ?", time.Now()).Delete(&model.ProjectInvite{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(createModel).Error
+	})
+}
+
+func (pir *projectInviteRepository) baseInviteQuery() *gorm.DB {
+	return pir.db.Table("project_invites pi").
+		Select(`
+			pi.id AS id,
+			pi.created_at AS created_at,
+			pi.expires_at AS expires_at,
+			pi.project_id AS project_id,
+			p.name AS project_name,
+			pi.inviter_id AS inviter_id,
+			COALESCE(iu.username, '') AS inviter_username,
+			pi.user_id AS user_id,
+			COALESCE(u.username, '') AS username,
+			pi.email AS email,
+			pi.role AS role,
+			pi.allow_view AS view,
+			pi.allow_edit AS edit,
+			pi.allow_delete AS "delete",
+			pi.allow_publish AS publish,
+			pi.allow_share AS share,
+			pi.allow_manage_users AS manage_users,
+			pi.allow_manage_metadata AS manage_metadata
+		`).
+		Joins("JOIN projects p ON p.id = pi.project_id AND p.deleted_at IS NULL").
+		Joins("LEFT JOIN users iu ON iu.id = pi.inviter_id").
+		Joins("LEFT JOIN users u ON u.id = pi.user_id").
+		Where("pi.expires_at > ?", time.Now())
+}
+
+// Invites to an email only reach a user once they have verified that address
+func (pir *projectInviteRepository) inviteeQuery(invitee *dto.UserProfile) *gorm.DB {
+	return pir.baseInviteQuery().
+		Where("(pi.user_id = ? OR (pi.user_id IS NULL AND ? AND pi.email = ?))", invitee.ID, invitee.Verified, strings.ToLower(invitee.Email))
+}
+
+func (pir *projectInviteRepository) GetByProject(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectInviteInfo], error) {
+	return pagination.Generate[dto.ProjectInviteInfo](pir.baseInviteQuery().Where("pi.project_id = ?", projectID).Order("pi.created_at DESC"), page, perPage)
+}
+
+func (pir *projectInviteRepository) GetByInvitee(invitee *dto.UserProfile, page, perPage int) (*dto.Pagination[dto.ProjectInviteInfo], error) {
+	return pagination.Generate[dto.ProjectInviteInfo](pir.inviteeQuery(invitee).Order("pi.created_at DESC"), page, perPage)
+}
+
+func (pir *projectInviteRepository) GetForInvitee(invitee *dto.UserProfile, inviteID uint) (*dto.ProjectInviteInfo, error) {
+	var invite dto.ProjectInviteInfo
+
+	result := pir.inviteeQuery(invitee).Where("pi.id = ?", inviteID).Scan(&invite)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrInviteNotFound
+	}
+
+	return &invite, nil
+}
+
+func (pir *projectInviteRepository) Delete(projectID, inviteID uint) error {
+	result := pir.db.Where("project_id = ? AND id = ?", projectID, inviteID).Delete(&model.ProjectInvite{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInviteNotFound
+	}
+
+	return nil
+}
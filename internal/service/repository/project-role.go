@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"gorm.io/gorm"
+)
+
+type projectRoleRepository struct {
+	db *gorm.DB
+}
+
+type ProjectRoleRepository interface {
+	Create(projectID uint, name string, allow dto.Allow) error
+	Update(projectID uint, name, newName string, allow dto.Allow) error
+	Delete(projectID uint, name string) error
+
+	Get(projectID uint, name string) (*dto.ProjectRoleInfo, error)
+	GetByProject(projectID uint) ([]dto.ProjectRoleInfo, error)
+}
+
+var (
+	ErrProjectRoleNotFound  = errors.New("project role not found")
+	ErrProjectRoleNameTaken = errors.New("project role name is already taken")
+	ErrProjectRoleIsPreset  = errors.New("preset project roles cannot be changed")
+	ErrProjectRoleEmpty     = errors.New("project role must allow something")
+)
+
+func NewProjectRoleRepository() ProjectRoleRepository {
+	return &projectRoleRepository{db: db.Postgres}
+}
+
+func (prr *projectRoleRepository) Create(projectID uint, name string, allow dto.Allow) error {
+	return prr.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&model.ProjectRole{}).Where("project_id = ? AND name = ?", projectID, name).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrProjectRoleNameTaken
+		}
+
+		return tx.Create(&model.ProjectRole{ProjectID: projectID, Name: name, Allow: allow}).Error
+	})
+}
+
+func allowUpdates(allow dto.Allow) map[string]any {
+	return map[string]any{
+		"allow_view":            allow.View,
+		"allow_edit":            allow.Edit,
+		"allow_delete":          allow.Delete,
+		"allow_publish":         allow.Publish,
+		"allow_share":           allow.Share,
+		"allow_manage_users":    allow.Manage.Users,
+		"allow_manage_metadata": allow.Manage.Metadata,
+	}
+}
+
+// Also rewrites the permissions of every collaborator and pending invite holding the role
+func (prr *projectRoleRepository) Update(projectID uint, name, newName string, allow dto.Allow) error {
+	return prr.db.Transaction(func(tx *gorm.DB) error {
+		if newName != name {
+			var count int64
+			if err := tx.Model(&model.ProjectRole{}).Where("project_id = ? AND name = ?", projectID, newName).Count(&count).Error; err != nil {
+				return err
+			}
+
+			if count > 0 {
+				return ErrProjectRoleNameTaken
+			}
+		}
+
+		updates := allowUpdates(allow)
+		updates["name"] = newName
+
+		result := tx.Model(&model.ProjectRole{}).Where("project_id = ? AND name = ?", projectID, name).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrProjectRoleNotFound
+		}
+
+		updates = allowUpdates(allow)
+		updates["role"] = newName
+
+		if err := tx.Model(&model.ProjectUserPermission{}).Where("project_id = ? AND role = ?", projectID, name).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.ProjectInvite{}).Where("project_id = ? AND role = ?", projectID, name).Updates(updates).Error
+	})
+}
+
+// Collaborators holding the role keep their permissions, only the link to the role is dropped
+func (prr *projectRoleRepository) Delete(projectID uint, name string) error {
+	return prr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("project_id = ? AND name = ?", projectID, name).Delete(&model.ProjectRole{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrProjectRoleNotFound
+		}
+
+		if err := tx.Model(&model.ProjectUserPermission{}).Where("project_id = ? AND role = ?", projectID, name).Update("role", "").Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.ProjectInvite{}).Where("project_id = ? AND role = ?", projectID, name).Update("role", "").Error
+	})
+}
+
+func (prr *projectRoleRepository) baseRoleQuery(projectID uint) *gorm.DB {
+	return prr.db.Table("project_roles r").
+		Select(`
+			r.name AS name,
+			false AS preset,
+			r.allow_view AS view,
+			r.allow_edit AS edit,
+			r.allow_delete AS "delete",
+			r.allow_publish AS publish,
+			r.allow_share AS share,
+			r.allow_manage_users AS manage_users,
+			r.allow_manage_metadata AS manage_metadata,
+			(
+				SELECT COUNT(*)
+				FROM project_user_permissions pu
+				WHERE pu.project_id = r.project_id AND pu.role = r.name
+			) AS holders
+		`).
+		Where("r.project_id = ?", projectID)
+}
+
+func (prr *projectRoleRepository) Get(projectID uint, name string) (*dto.ProjectRoleInfo, error) {
+	var role dto.ProjectRoleInfo
+
+	result := prr.baseRoleQuery(projectID).Where("r.name = ?", name).Scan(&role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrProjectRoleNotFound
+	}
+
+	return &role, nil
+}
+
+// Presets come first, followed by the custom roles in the order they were created
+func (prr *projectRoleRepository) GetByProject(projectID uint) ([]dto.ProjectRoleInfo, error) {
+	var holders []struct {
+		Role  string
+		Count int64
+	}
+
+	if err := prr.db.Model(&model.ProjectUserPermission{}).
+		Select("role, COUNT(*) AS count").
+		Where("project_id = ? AND role <> ''", projectID).
+		Group("role").
+		Scan(&holders).Error; err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{}
+	for _, h := range holders {
+		counts[h.Role] = h.Count
+	}
+
+	roles := []dto.ProjectRoleInfo{}
+	for _, name := range dto.ProjectRolePresets {
+		allow, _ := dto.ProjectRolePreset(name)
+
+		roles = append(roles, dto.ProjectRoleInfo{
+			Name:           name,
+			Preset:         true,
+			View:           allow.View,
+			Edit:           allow.Edit,
+			Delete:         allow.Delete,
+			Publish:        allow.Publish,
+			Share:          allow.Share,
+			ManageUsers:    allow.Manage.Users,
+			ManageMetadata: allow.Manage.Metadata,
+			Holders:        counts[name],
+		})
+	}
+
+	var custom []dto.ProjectRoleInfo
+	if err := prr.baseRoleQuery(projectID).Order("r.created_at ASC").Scan(&custom).Error; err != nil {
+		return nil, err
+	}
+
+	return append(roles, custom...), nil
+}
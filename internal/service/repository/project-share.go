@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type projectShareRepository struct {
+	db *gorm.DB
+}
+
+type ProjectShareRepository interface {
+	Create(createModel *model.ProjectShareLink) error
+
+	Get(projectID, linkID uint) (*dto.ProjectShareLinkInfo, error)
+	GetByProject(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectShareLinkInfo], error)
+
+	Use(projectID, userID uint, hash string) (*dto.ProjectShareLinkInfo, error)
+
+	Delete(projectID, linkID uint) error
+}
+
+var (
+	ErrShareLinkInvalid  = errors.New("share link does not exist, has expired or was used too many times")
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrShareLinkExpiry   = errors.New("share link expiry must be in the future and within the configured maximum")
+)
+
+func NewProjectShareRepository() ProjectShareRepository {
+	return &projectShareRepository{db: db.Postgres}
+}
+
+func (psr *projectShareRepository) Create(createModel *model.ProjectShareLink) error {
+	return psr.db.Create(createModel).Error
+}
+
+func (psr *projectShareRepository) baseShareLinkQuery(projectID uint) *gorm.DB {
+	return psr.db.Table("project_share_links sl").
+		Select(`
+			sl.id AS id,
+			sl.created_at AS created_at,
+			sl.project_id AS project_id,
+			sl.creator_id AS creator_id,
+			COALESCE(u.username, '') AS creator_username,
+			sl.scope AS scope,
+			sl.expires_at AS expires_at,
+			sl.max_uses AS max_uses,
+			sl.uses AS uses
+		`).
+		Joins("LEFT JOIN users u ON u.id = sl.creator_id").
+		Where("sl.project_id = ?", projectID)
+}
+
+func (psr *projectShareRepository) Get(projectID, linkID uint) (*dto.ProjectShareLinkInfo, error) {
+	var link dto.ProjectShareLinkInfo
+
+	result := psr.baseShareLinkQuery(projectID).Where("sl.id = ?", linkID).Scan(&link)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrShareLinkNotFound
+	}
+
+	return &link, nil
+}
+
+func (psr *projectShareRepository) GetByProject(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectShareLinkInfo], error) {
+	return pagination.Generate[dto.ProjectShareLinkInfo](psr.baseShareLinkQuery(projectID).Order("sl.created_at DESC"), page, perPage)
+}
+
+// Resolves the link for the user as long as it is still valid for the project. The first time a user sends the link
+// counts as a use, users who already used it can keep sending it until it expires even once it ran out of uses.
+func (psr *projectShareRepository) Use(projectID, userID uint, hash string) (*dto.ProjectShareLinkInfo, error) {
+	err := psr.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the link keeps concurrent first uses from going over the limit
+		var link model.ProjectShareLink
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("project_id = ? AND token = ? AND expires_at > ?", projectID, hash, time.Now()).
+			First(&link).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrShareLinkInvalid
+		} else if err != nil {
+			return err
+		}
+
+		var redeemed int64
+		if err := tx.Model(&model.ProjectShareLinkRedemption{}).Where("link_id = ? AND user_id = ?", link.ID, userID).Count(&redeemed).Error; err != nil {
+			return err
+		}
+
+		if redeemed > 0 {
+			return nil
+		}
+
+		if link.MaxUses > 0 && link.Uses >= link.MaxUses {
+			return ErrShareLinkInvalid
+		}
+
+		if err := tx.Create(&model.ProjectShareLinkRedemption{LinkID: link.ID, UserID: userID}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&link).Update("uses", gorm.Expr("uses + 1")).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var info dto.ProjectShareLinkInfo
+	if err := psr.baseShareLinkQuery(projectID).Where("sl.token = ?", hash).Scan(&info).Error; err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+func (psr *projectShareRepository) Delete(projectID, linkID uint) error {
+	return psr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("project_id = ? AND id = ?", projectID, linkID).Delete(&model.ProjectShareLink{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrShareLinkNotFound
+		}
+
+		return tx.Where("link_id = ?", linkID).Delete(&model.ProjectShareLinkRedemption{}).Error
+	})
+}
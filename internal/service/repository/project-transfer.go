@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type projectTransferRepository struct {
+	db *gorm.DB
+}
+
+type ProjectTransferRepository interface {
+	Create(createModel *model.ProjectTransfer) error
+
+	Get(projectID uint) (*dto.ProjectTransferInfo, error)
+	GetByRecipient(recipientID uint, page, perPage int) (*dto.Pagination[dto.ProjectTransferInfo], error)
+	GetForRecipient(recipientID, transferID uint) (*dto.ProjectTransferInfo, error)
+
+	Accept(transfer *dto.ProjectTransferInfo) error
+
+	Delete(projectID uint) error
+}
+
+var ErrProjectTransferNotFound = errors.New("project transfer not found or expired")
+
+func NewProjectTransferRepository() ProjectTransferRepository {
+	return &projectTransferRepository{db: db.Postgres}
+}
+
+// A project has at most one pending transfer, starting a new one replaces it. Expired transfers are dropped along the way.
+func (ptr *projectTransferRepository) Create(createModel *model.ProjectTransfer) error {
+	return ptr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ? OR expires_at <= ?", createModel.ProjectID, time.Now()).Delete(&model.ProjectTransfer{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(createModel).Error
+	})
+}
+
+func (ptr *projectTransferRepository) baseTransferQuery() *gorm.DB {
+	return ptr.db.Table("project_transfers pt").
+		Select(`
+			pt.id AS id,
+			pt.created_at AS created_at,
+			pt.expires_at AS expires_at,
+			pt.project_id AS project_id,
+			p.name AS project_name,
+			COALESCE(po.user_id, 0) AS owner_id,
+			COALESCE(ou.username, '') AS owner_username,
+			pt.issuer_id AS issuer_id,
+			COALESCE(iu.username, '') AS issuer_username,
+			pt.recipient_id AS recipient_id,
+			ru.username AS recipient_username,
+			pt.keep_access AS keep_access
+		`).
+		Joins("JOIN projects p ON p.id = pt.project_id AND p.deleted_at IS NULL").
+		Joins("JOIN project_owners po ON po.project_id = pt.project_id").
+		Joins("LEFT JOIN users ou ON ou.id = po.user_id").
+		Joins("LEFT JOIN users iu ON iu.id = pt.issuer_id").
+		Joins("JOIN users ru ON ru.id = pt.recipient_id").
+		Where("pt.expires_at > ?", time.Now())
+}
+
+func (ptr *projectTransferRepository) first(query *gorm.DB) (*dto.ProjectTransferInfo, error) {
+	var transfer dto.ProjectTransferInfo
+
+	result := query.Scan(&transfer)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrProjectTransferNotFound
+	}
+
+	return &transfer, nil
+}
+
+func (ptr *projectTransferRepository) Get(projectID uint) (*dto.ProjectTransferInfo, error) {
+	return ptr.first(ptr.baseTransferQuery().Where("pt.project_id = ?", projectID))
+}
+
+func (ptr *projectTransferRepository) GetByRecipient(recipientID uint, page, perPage int) (*dto.Pagination[dto.ProjectTransferInfo], error) {
+	return pagination.Generate[dto.ProjectTransferInfo](ptr.baseTransferQuery().Where("pt.recipient_id = ?", recipientID).Order("pt.created_at DESC"), page, perPage)
+}
+
+func (ptr *projectTransferRepository) GetForRecipient(recipientID, transferID uint) (*dto.ProjectTransferInfo, error) {
+	return ptr.first(ptr.baseTransferQuery().Where("pt.recipient_id = ? AND pt.id = ?", recipientID, transferID))
+}
+
+// Hands the project to the recipient, taking it away from the organization owning it if any. The recipient no longer
+// needs their own permissions, while the previous owner either leaves the project or, when asked for, stays with every
+// permission. transfer.OwnerID is set to whoever owned the project right before.
+func (ptr *projectTransferRepository) Accept(transfer *dto.ProjectTransferInfo) error {
+	return ptr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", transfer.ID).Delete(&model.ProjectTransfer{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrProjectTransferNotFound
+		}
+
+		var owner model.ProjectOwner
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", transfer.ProjectID).First(&owner).Error; err != nil {
+			return err
+		}
+
+		if owner.UserID != nil {
+			transfer.OwnerID = *owner.UserID
+		}
+
+		if err := tx.Model(&owner).Updates(map[string]any{"user_id": transfer.RecipientID, "organization_id": nil}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("project_id = ? AND user_id IN ?", transfer.ProjectID, []uint{transfer.RecipientID, transfer.OwnerID}).Delete(&model.ProjectUserPermission{}).Error; err != nil {
+			return err
+		}
+
+		if !transfer.KeepAccess || transfer.OwnerID == 0 || transfer.OwnerID == transfer.RecipientID {
+			return nil
+		}
+
+		allow, _ := dto.ProjectRolePreset(dto.ProjectRoleManager)
+
+		return tx.Create(&model.ProjectUserPermission{
+			ProjectID: transfer.ProjectID,
+			UserID:    transfer.OwnerID,
+			Role:      dto.ProjectRoleManager,
+			Allow:     allow,
+		}).Error
+	})
+}
+
+func (ptr *projectTransferRepository) Delete(projectID uint) error {
+	result := ptr.db.Where("project_id = ? AND expires_at > ?", projectID, time.Now()).Delete(&model.ProjectTransfer{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrProjectTransferNotFound
+	}
+
+	return nil
+}
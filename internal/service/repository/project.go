@@ -3,10 +3,14 @@ package repository
 import (
 	"encoding/json"
 	"errors"
+	"reflect"
+	"time"
 
+	"github.com/swibly/swibly-api/config"
 	"github.com/swibly/swibly-api/internal/model"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/pkg/aws"
+	"github.com/swibly/swibly-api/pkg/contentschema"
 	"github.com/swibly/swibly-api/pkg/db"
 	"github.com/swibly/swibly-api/pkg/pagination"
 	"github.com/swibly/swibly-api/pkg/utils"
@@ -25,18 +29,27 @@ type ProjectRepository interface {
 	Update(projectID uint, updateModel *dto.ProjectUpdate) error
 	Unlink(projectID uint) error
 
+	GetForkBase(projectID uint) (uint, any, error)
+	SyncFork(projectID, authorID, expectedVersion uint, merge func(content, base, upstream any) (any, error)) (uint, error)
+	GetForks(issuerID, projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error)
+
 	Assign(userID uint, projectID uint, allowList *dto.ProjectAssign) error
 
 	Get(issuerID uint, projectModel *model.Project) (*dto.ProjectInfo, error)
 	GetByOwner(issuerID, userID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error)
+	GetByOrganization(issuerID, organizationID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error)
 	GetPublic(issuerID uint, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error)
 	GetFavorited(issuerID, userID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error)
 	GetTrashed(ownerID uint, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error)
 
 	Search(issuerID uint, options *dto.SearchProject, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error)
 
-	GetContent(projectID uint) (any, error)
-	SaveContent(projectID uint, content any) error
+	GetContent(projectID uint) (any, uint, error)
+	SaveContent(projectID, authorID, expectedVersion uint, content any) (uint, error)
+	PatchContent(projectID, authorID, expectedVersion uint, patch func(content any) (any, error)) (uint, error)
+
+	GetRevisions(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectRevisionInfo], error)
+	GetRevision(projectID, revisionID uint) (*dto.ProjectRevision, error)
 
 	Favorite(projectID, userID uint) error
 	Unfavorite(projectID, userID uint) error
@@ -57,6 +70,7 @@ var (
 	ErrUpstreamNotPublic       = errors.New("cannot publish this project because the upstream project is not public")
 	ErrCannotAssignOwner       = errors.New("cannot assign owner")
 	ErrUserNotAssigned         = errors.New("user is not assigned to the project")
+	ErrProjectVersionMismatch  = errors.New("project content was changed by someone else")
 )
 
 func NewProjectRepository(userRepo UserRepository) ProjectRepository {
@@ -77,12 +91,14 @@ func (pr *projectRepository) baseProjectQuery(issuerID uint) *gorm.DB {
       p.height as height,
       p.banner_url as banner_url,
 			p.fork as fork,
-			u.id AS owner_id,
-			u.first_name AS owner_first_name,
-			u.last_name AS owner_last_name,
-			u.username AS owner_username,
-			u.profile_picture AS owner_profile_picture,
-			u.verified AS owner_verified,
+			COALESCE(u.id, 0) AS owner_id,
+			COALESCE(u.first_name, '') AS owner_first_name,
+			COALESCE(u.last_name, '') AS owner_last_name,
+			COALESCE(u.username, '') AS owner_username,
+			COALESCE(u.profile_picture, '') AS owner_profile_picture,
+			COALESCE(u.verified, false) AS owner_verified,
+			po.organization_id AS organization_id,
+			COALESCE(o.name, '') AS organization_name,
 			EXISTS (
 				SELECT 1 
 				FROM project_publications pp 
@@ -97,6 +113,7 @@ func (pr *projectRepository) baseProjectQuery(issuerID uint) *gorm.DB {
 						'username', puu.username,
 						'pfp', puu.profile_picture,
 						'verified', puu.verified,
+						'role', pu.role,
 						'allow_view', pu.allow_view,
 						'allow_edit', pu.allow_edit,
 						'allow_delete', pu.allow_delete,
@@ -129,7 +146,8 @@ func (pr *projectRepository) baseProjectQuery(issuerID uint) *gorm.DB {
       ) AS total_clones
 		`, issuerID).
 		Joins("JOIN project_owners po ON po.project_id = p.id").
-		Joins("JOIN users u ON po.user_id = u.id")
+		Joins("LEFT JOIN users u ON po.user_id = u.id").
+		Joins("LEFT JOIN organizations o ON o.id = po.organization_id")
 }
 
 func (pr *projectRepository) paginateProjects(query *gorm.DB, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error) {
@@ -184,6 +202,8 @@ func convertToProjectInfo(jsonInfo *dto.ProjectInfoJSON) (dto.ProjectInfo, error
 		OwnerUsername:       jsonInfo.OwnerUsername,
 		OwnerProfilePicture: jsonInfo.OwnerProfilePicture,
 		OwnerVerified:       jsonInfo.OwnerVerified,
+		OrganizationID:      jsonInfo.OrganizationID,
+		OrganizationName:    jsonInfo.OrganizationName,
 		IsFavorited:         jsonInfo.IsFavorited,
 		TotalFavorites:      jsonInfo.TotalFavorites,
 		TotalClones:         jsonInfo.TotalClones,
@@ -192,9 +212,23 @@ func convertToProjectInfo(jsonInfo *dto.ProjectInfoJSON) (dto.ProjectInfo, error
 }
 
 func (pr *projectRepository) Create(createModel *dto.ProjectCreation) (uint, error) {
+	content := createModel.Content
+
+	// Content sent through multipart forms arrives as text
+	if text, ok := content.(string); ok {
+		if err := json.Unmarshal([]byte(text), &content); err != nil {
+			return 0, contentschema.ValidationErrors{{Path: "", Rule: "type", Param: "object"}}
+		}
+	}
+
+	content, err := contentschema.Normalize(contentschema.Project, content)
+	if err != nil {
+		return 0, err
+	}
+
 	tx := pr.db.Begin()
 
-	out, err := json.MarshalIndent(createModel.Content, "", "")
+	out, err := json.MarshalIndent(content, "", "")
 	if err != nil {
 		tx.Rollback()
 		return 0, err
@@ -210,13 +244,23 @@ func (pr *projectRepository) Create(createModel *dto.ProjectCreation) (uint, err
 		Fork:        createModel.Fork,
 	}
 
+	if createModel.Fork != nil {
+		project.ForkBase = string(out)
+	}
+
 	if err := tx.Create(&project).Error; err != nil {
 		tx.Rollback()
 		return 0, err
 	}
 
-	if createModel.BannerImage != nil {
-		url, err := aws.UploadProjectImage(project.ID, createModel.BannerImage)
+	if createModel.BannerImage != nil || len(createModel.BannerData) > 0 {
+		var url string
+		if createModel.BannerImage != nil {
+			url, err = aws.UploadProjectImage(project.ID, createModel.BannerImage)
+		} else {
+			url, err = aws.UploadProjectImageData(project.ID, createModel.BannerData)
+		}
+
 		if err != nil {
 			tx.Rollback()
 			return 0, err
@@ -229,8 +273,9 @@ func (pr *projectRepository) Create(createModel *dto.ProjectCreation) (uint, err
 	}
 
 	projectOwner := &model.ProjectOwner{
-		UserID:    createModel.OwnerID,
-		ProjectID: project.ID,
+		UserID:         &createModel.OwnerID,
+		ProjectID:      project.ID,
+		OrganizationID: createModel.OrganizationID,
 	}
 
 	if err := tx.Create(&projectOwner).Error; err != nil {
@@ -250,7 +295,7 @@ func (pr *projectRepository) Create(createModel *dto.ProjectCreation) (uint, err
 	}
 
 	if createModel.Fork != nil {
-		var originalOwnerID uint
+		var originalOwnerID *uint
 		if err := tx.Model(&model.ProjectOwner{}).
 			Where("project_id = ?", createModel.Fork).
 			Select("user_id").
@@ -259,10 +304,10 @@ func (pr *projectRepository) Create(createModel *dto.ProjectCreation) (uint, err
 			return 0, err
 		}
 
-		if originalOwnerID != createModel.OwnerID {
+		if originalOwnerID != nil && *originalOwnerID != createModel.OwnerID {
 			if err := tx.Create(&model.ProjectUserPermission{
 				ProjectID: project.ID,
-				UserID:    originalOwnerID,
+				UserID:    *originalOwnerID,
 				Allow:     dto.Allow{View: true},
 			}).Error; err != nil {
 				tx.Rollback()
@@ -406,7 +451,182 @@ func (pr *projectRepository) Unlink(projectID uint) error {
 		return ErrProjectIsNotAFork
 	}
 
-	return pr.db.Model(&model.Project{}).Where("id = ?", projectID).Update("fork", nil).Error
+	return pr.db.Model(&model.Project{}).Where("id = ?", projectID).Updates(map[string]any{"fork": nil, "fork_base": nil}).Error
+}
+
+// Loads the upstream of the fork, which must still be public for the fork to follow it
+func upstreamOf(tx *gorm.DB, fork *uint) (*model.Project, error) {
+	if fork == nil {
+		return nil, ErrProjectIsNotAFork
+	}
+
+	var upstream model.Project
+	if err := tx.Where("id = ?", *fork).First(&upstream).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUpstreamNotPublic
+		}
+
+		return nil, err
+	}
+
+	if err := tx.Where("project_id = ?", upstream.ID).First(&model.ProjectPublication{}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUpstreamNotPublic
+		}
+
+		return nil, err
+	}
+
+	return &upstream, nil
+}
+
+// Returns the upstream of the fork and the content it was forked or last synced from.
+// The base is nil for forks made before it was recorded.
+func (pr *projectRepository) GetForkBase(projectID uint) (uint, any, error) {
+	var project struct {
+		Fork     *uint
+		ForkBase *string
+	}
+
+	result := pr.db.Model(&model.Project{}).Select("fork, fork_base").Where("id = ?", projectID).Scan(&project)
+	if result.Error != nil {
+		return 0, nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, nil, gorm.ErrRecordNotFound
+	}
+
+	upstream, err := upstreamOf(pr.db, project.Fork)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if project.ForkBase == nil {
+		return upstream.ID, nil, nil
+	}
+
+	base, err := decodeContent(*project.ForkBase)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return upstream.ID, base, nil
+}
+
+// Replaces the content of the fork with what `merge` makes of it, its base and the current upstream content,
+// then records the upstream content as the new base. Nothing is saved when the merge leaves the content as it was.
+func (pr *projectRepository) SyncFork(projectID, authorID, expectedVersion uint, merge func(content, base, upstream any) (any, error)) (uint, error) {
+	var version uint
+
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		var project struct {
+			Content  string
+			Version  uint
+			Fork     *uint
+			ForkBase *string
+		}
+
+		result := tx.Model(&model.Project{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("content, version, fork, fork_base").
+			Where("id = ?", projectID).
+			Scan(&project)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if expectedVersion != 0 && project.Version != expectedVersion {
+			return ErrProjectVersionMismatch
+		}
+
+		upstream, err := upstreamOf(tx, project.Fork)
+		if err != nil {
+			return err
+		}
+
+		var upstreamRaw string
+		if err := tx.Model(&model.Project{}).Select("content").Where("id = ?", upstream.ID).Scan(&upstreamRaw).Error; err != nil {
+			return err
+		}
+
+		upstreamContent, err := decodeContent(upstreamRaw)
+		if err != nil {
+			return err
+		}
+
+		content, err := decodeContent(project.Content)
+		if err != nil {
+			return err
+		}
+
+		var base any
+		if project.ForkBase != nil {
+			if base, err = decodeContent(*project.ForkBase); err != nil {
+				return err
+			}
+		}
+
+		merged, err := merge(content, base, upstreamContent)
+		if err != nil {
+			return err
+		}
+
+		version = project.Version
+		if !reflect.DeepEqual(merged, content) {
+			if version, err = saveContent(tx, projectID, authorID, project.Version, merged); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&model.Project{}).Where("id = ?", projectID).Update("fork_base", upstreamRaw).Error
+	})
+
+	return version, err
+}
+
+// Lists every project forked from this one, directly or from one of its forks, nearest first.
+// Only the forks the issuer can see are listed.
+func (pr *projectRepository) GetForks(issuerID, projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error) {
+	query := pr.baseProjectQuery(issuerID).
+		Joins(`
+			JOIN (
+				WITH RECURSIVE fork_tree AS (
+					SELECT id, 1 AS depth FROM projects WHERE fork = ?
+					UNION ALL
+					SELECT child.id, ft.depth + 1 FROM projects child JOIN fork_tree ft ON child.fork = ft.id
+				)
+				SELECT id, depth FROM fork_tree
+			) ft ON ft.id = p.id`, projectID).
+		Where("p.deleted_at IS NULL").
+		Where(`(
+			EXISTS (
+				SELECT 1
+				FROM project_publications pp
+				WHERE pp.project_id = p.id
+			) OR
+			(po.user_id = ? AND po.organization_id IS NULL) OR
+			EXISTS (
+				SELECT 1
+				FROM project_user_permissions pu
+				WHERE pu.project_id = p.id
+				AND pu.user_id = ?
+				AND pu.allow_view = true
+			) OR
+			EXISTS (
+				SELECT 1
+				FROM organization_members om
+				WHERE om.organization_id = po.organization_id
+				AND om.user_id = ?
+			)
+		)`, issuerID, issuerID, issuerID).
+		Order("ft.depth ASC, p.created_at ASC")
+
+	return pr.paginateProjects(query, page, perPage)
 }
 
 func (pr *projectRepository) Assign(userID uint, projectID uint, allowList *dto.ProjectAssign) error {
@@ -459,6 +679,7 @@ func (pr *projectRepository) upsertPermissions(userID uint, projectID uint, allo
 			userPermission = model.ProjectUserPermission{
 				UserID:    userID,
 				ProjectID: projectID,
+				Role:      allowList.RoleName(),
 				Allow:     dto.Allow{},
 			}
 
@@ -517,6 +738,13 @@ func (pr *projectRepository) upsertPermissions(userID uint, projectID uint, allo
 			updates["allow_manage_metadata"] = *allowList.ManageMetadata
 		}
 
+		// Changing a flag on its own means the user no longer holds the role they had
+		if allowList.Role != nil {
+			updates["role"] = *allowList.Role
+		} else if len(updates) > 0 {
+			updates["role"] = ""
+		}
+
 		if err := tx.Model(&userPermission).Updates(updates).Error; err != nil {
 			return err
 		}
@@ -548,18 +776,22 @@ func (pr *projectRepository) Get(userID uint, projectModel *model.Project) (*dto
 		return nil, err
 	}
 
-	ownerProfile, err := pr.userRepo.Get(&model.User{ID: projectOwner.UserID})
-	if err != nil {
-		return nil, err
-	}
+	// Projects of an organization outlive the member who created them
+	var owner dto.UserInfoLite
+	if projectOwner.UserID != nil {
+		ownerProfile, err := pr.userRepo.Get(&model.User{ID: *projectOwner.UserID})
+		if err != nil {
+			return nil, err
+		}
 
-	owner := dto.UserInfoLite{
-		ID:             ownerProfile.ID,
-		FirstName:      ownerProfile.FirstName,
-		LastName:       ownerProfile.LastName,
-		Username:       ownerProfile.Username,
-		ProfilePicture: ownerProfile.ProfilePicture,
-		Verified:       ownerProfile.Verified,
+		owner = dto.UserInfoLite{
+			ID:             ownerProfile.ID,
+			FirstName:      ownerProfile.FirstName,
+			LastName:       ownerProfile.LastName,
+			Username:       ownerProfile.Username,
+			ProfilePicture: ownerProfile.ProfilePicture,
+			Verified:       ownerProfile.Verified,
+		}
 	}
 
 	allowedUserDTOs := []dto.ProjectUserPermissions{}
@@ -577,6 +809,7 @@ func (pr *projectRepository) Get(userID uint, projectModel *model.Project) (*dto
 			Username:       userProfile.Username,
 			ProfilePicture: userProfile.ProfilePicture,
 			Verified:       userProfile.Verified,
+			Role:           userPerm.Role,
 			View:           userPerm.Allow.View,
 			Edit:           userPerm.Allow.Edit,
 			Delete:         userPerm.Allow.Delete,
@@ -587,6 +820,13 @@ func (pr *projectRepository) Get(userID uint, projectModel *model.Project) (*dto
 		})
 	}
 
+	var organizationName string
+	if projectOwner.OrganizationID != nil {
+		if err := pr.db.Model(&model.Organization{}).Where("id = ?", *projectOwner.OrganizationID).Select("name").Scan(&organizationName).Error; err != nil {
+			return nil, err
+		}
+	}
+
 	isFavorited := false
 	if err := pr.db.Where("project_id = ? AND user_id = ?", project.ID, userID).First(&model.ProjectUserFavorite{}).Error; err == nil {
 		isFavorited = true
@@ -608,6 +848,8 @@ func (pr *projectRepository) Get(userID uint, projectModel *model.Project) (*dto
 		OwnerUsername:       owner.Username,
 		OwnerProfilePicture: owner.ProfilePicture,
 		OwnerVerified:       owner.Verified,
+		OrganizationID:      projectOwner.OrganizationID,
+		OrganizationName:    organizationName,
 		Name:                project.Name,
 		Description:         project.Description,
 		Budget:              project.Budget,
@@ -628,7 +870,7 @@ func (pr *projectRepository) GetByOwner(issuerID, userID uint, onlyPublic bool,
 	query := pr.baseProjectQuery(issuerID).
 		Where("deleted_at IS NULL").
 		Where(`
-			po.user_id = ? OR 
+			(po.user_id = ? AND po.organization_id IS NULL) OR 
 			EXISTS (
 				SELECT 1 
 				FROM project_user_permissions pu 
@@ -645,6 +887,19 @@ func (pr *projectRepository) GetByOwner(issuerID, userID uint, onlyPublic bool,
 	return pr.paginateProjects(query, page, perPage)
 }
 
+func (pr *projectRepository) GetByOrganization(issuerID, organizationID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error) {
+	query := pr.baseProjectQuery(issuerID).
+		Where("deleted_at IS NULL").
+		Where("po.organization_id = ?", organizationID).
+		Order("created_at DESC")
+
+	if onlyPublic {
+		query = query.Joins("JOIN project_publications pp ON pp.project_id = p.id")
+	}
+
+	return pr.paginateProjects(query, page, perPage)
+}
+
 func (pr *projectRepository) GetPublic(issuerID uint, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error) {
 	query := pr.baseProjectQuery(issuerID).
 		Where("deleted_at IS NULL").
@@ -671,15 +926,22 @@ func (pr *projectRepository) GetTrashed(issuerID uint, page, perPage int) (*dto.
 	query := pr.baseProjectQuery(issuerID).
 		Where("deleted_at IS NOT NULL").
 		Where(`
-			u.id = ? OR 
+			(u.id = ? AND po.organization_id IS NULL) OR 
 			EXISTS (
 				SELECT 1
 				FROM project_user_permissions pu
 				WHERE pu.project_id = p.id
 				AND pu.user_id = ?
 				AND pu.allow_delete = true
+			) OR
+			EXISTS (
+				SELECT 1
+				FROM organization_members om
+				WHERE om.organization_id = po.organization_id
+				AND om.user_id = ?
+				AND om.role IN ?
 			)
-		`, issuerID, issuerID).
+		`, issuerID, issuerID, issuerID, []string{dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin}).
 		Order("deleted_at DESC")
 
 	return pr.paginateProjects(query, page, perPage)
@@ -760,35 +1022,230 @@ func (pr *projectRepository) Search(issuerID uint, search *dto.SearchProject, pa
 	return pr.paginateProjects(query, page, perPage)
 }
 
-func (pr *projectRepository) GetContent(projectID uint) (any, error) {
-	var content string
+func (pr *projectRepository) GetContent(projectID uint) (any, uint, error) {
+	var project struct {
+		Content string
+		Version uint
+	}
 
-	result := pr.db.Model(&model.Project{}).Select("content").Where("id = ?", projectID).Scan(&content)
+	result := pr.db.Model(&model.Project{}).Select("content, version").Where("id = ?", projectID).Scan(&project)
 
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, 0, result.Error
 	}
 
-	var contentData any
-	if err := json.Unmarshal([]byte(content), &contentData); err != nil {
+	contentData, err := decodeContent(project.Content)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return contentData, project.Version, nil
+}
+
+func decodeContent(raw string) (any, error) {
+	var content any
+	if err := json.Unmarshal([]byte(raw), &content); err != nil {
 		return nil, err
 	}
 
-	return contentData, nil
+	// Old documents are upgraded when read, they are only stored upgraded on the next save
+	if upgraded, err := contentschema.Upgrade(contentschema.Project, content); err == nil {
+		content = upgraded
+	}
+
+	return content, nil
+}
+
+// Every save is recorded as a revision of the project, then the revisions that fall outside the
+// retention policy (see config/projects.yaml) are pruned.
+//
+// When expectedVersion is not 0, the content is only saved if the project is still at that version,
+// otherwise ErrProjectVersionMismatch is returned. The new version is returned on success.
+func (pr *projectRepository) SaveContent(projectID, authorID, expectedVersion uint, content any) (uint, error) {
+	var version uint
+
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		version, err = saveContent(tx, projectID, authorID, expectedVersion, content)
+		return err
+	})
+
+	return version, err
 }
 
-func (pr *projectRepository) SaveContent(projectID uint, content any) error {
+// Same as SaveContent, but the new content is computed from the stored one by `patch`. The row is locked
+// while patching, so concurrent patches are applied one after the other instead of overwriting each other.
+func (pr *projectRepository) PatchContent(projectID, authorID, expectedVersion uint, patch func(content any) (any, error)) (uint, error) {
+	var version uint
+
+	err := pr.db.Transaction(func(tx *gorm.DB) error {
+		var project struct {
+			Content string
+			Version uint
+		}
+
+		result := tx.Model(&model.Project{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("content, version").
+			Where("id = ?", projectID).
+			Scan(&project)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if expectedVersion != 0 && project.Version != expectedVersion {
+			return ErrProjectVersionMismatch
+		}
+
+		var content any
+		if err := json.Unmarshal([]byte(project.Content), &content); err != nil {
+			return err
+		}
+
+		patched, err := patch(content)
+		if err != nil {
+			return err
+		}
+
+		version, err = saveContent(tx, projectID, authorID, project.Version, patched)
+		return err
+	})
+
+	return version, err
+}
+
+func saveContent(tx *gorm.DB, projectID, authorID, expectedVersion uint, content any) (uint, error) {
+	content, err := contentschema.Normalize(contentschema.Project, content)
+	if err != nil {
+		return 0, err
+	}
+
 	contentJSON, err := json.Marshal(content)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	contentString := string(contentJSON)
 
-	return pr.db.Model(&model.Project{}).
-		Where("id = ?", projectID).
-		Update("content", contentString).
-		Error
+	query := tx.Model(&model.Project{}).Where("id = ?", projectID)
+	if expectedVersion != 0 {
+		query = query.Where("version = ?", expectedVersion)
+	}
+
+	result := query.Updates(map[string]any{
+		"content": contentString,
+		"version": gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		if expectedVersion != 0 {
+			return 0, ErrProjectVersionMismatch
+		}
+
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	var version uint
+	if err := tx.Model(&model.Project{}).Select("version").Where("id = ?", projectID).Scan(&version).Error; err != nil {
+		return 0, err
+	}
+
+	revision := &model.ProjectRevision{ProjectID: projectID, Content: contentString}
+	if authorID != 0 {
+		revision.AuthorID = &authorID
+	}
+
+	if err := tx.Create(revision).Error; err != nil {
+		return 0, err
+	}
+
+	return version, pruneRevisions(tx, projectID)
+}
+
+func pruneRevisions(tx *gorm.DB, projectID uint) error {
+	retention := config.Projects.Revisions
+
+	if retention.KeepLast <= 0 {
+		return nil
+	}
+
+	return tx.Exec(`
+		DELETE FROM project_revisions
+		WHERE project_id = @project
+		AND id NOT IN (
+			SELECT id
+			FROM project_revisions
+			WHERE project_id = @project
+			ORDER BY id DESC
+			LIMIT @keep
+		)
+		AND id NOT IN (
+			SELECT DISTINCT ON (date_trunc('day', created_at)) id
+			FROM project_revisions
+			WHERE project_id = @project
+			AND created_at >= @since
+			ORDER BY date_trunc('day', created_at), id DESC
+		)
+	`, map[string]any{
+		"project": projectID,
+		"keep":    retention.KeepLast,
+		"since":   time.Now().AddDate(0, 0, -retention.DailySnapshots),
+	}).Error
+}
+
+func (pr *projectRepository) baseRevisionQuery(projectID uint, withContent bool) *gorm.DB {
+	content := ""
+	if withContent {
+		content = ", r.content AS content"
+	}
+
+	return pr.db.Table("project_revisions r").
+		Select(`
+			r.id AS id,
+			r.created_at AS created_at,
+			r.project_id AS project_id,
+			r.author_id AS author_id,
+			COALESCE(u.first_name, '') AS author_first_name,
+			COALESCE(u.last_name, '') AS author_last_name,
+			COALESCE(u.username, '') AS author_username,
+			COALESCE(u.profile_picture, '') AS author_profile_picture
+		`+content).
+		Joins("LEFT JOIN users u ON u.id = r.author_id").
+		Where("r.project_id = ?", projectID)
+}
+
+func (pr *projectRepository) GetRevisions(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectRevisionInfo], error) {
+	return pagination.Generate[dto.ProjectRevisionInfo](pr.baseRevisionQuery(projectID, false).Order("r.id DESC"), page, perPage)
+}
+
+func (pr *projectRepository) GetRevision(projectID, revisionID uint) (*dto.ProjectRevision, error) {
+	var revision struct {
+		dto.ProjectRevisionInfo
+		Content string
+	}
+
+	result := pr.baseRevisionQuery(projectID, true).Where("r.id = ?", revisionID).Scan(&revision)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var contentData any
+	if err := json.Unmarshal([]byte(revision.Content), &contentData); err != nil {
+		return nil, err
+	}
+
+	return &dto.ProjectRevision{ProjectRevisionInfo: revision.ProjectRevisionInfo, Content: contentData}, nil
 }
 
 func (pr *projectRepository) Favorite(projectID, userID uint) error {
@@ -920,6 +1377,7 @@ func (pr *projectRepository) ClearTrash(userID uint) error {
 				FROM project_owners po
 				WHERE po.project_id = project_user_favorites.project_id
 				AND po.user_id = ?
+				AND po.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.ProjectUserFavorite{}).Error; err != nil {
@@ -935,6 +1393,7 @@ func (pr *projectRepository) ClearTrash(userID uint) error {
 				FROM project_owners po
 				WHERE po.project_id = project_user_permissions.project_id
 				AND po.user_id = ?
+				AND po.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.ProjectUserPermission{}).Error; err != nil {
@@ -950,6 +1409,7 @@ func (pr *projectRepository) ClearTrash(userID uint) error {
 				FROM project_owners po
 				WHERE po.project_id = project_publications.project_id
 				AND po.user_id = ?
+				AND po.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.ProjectPublication{}).Error; err != nil {
@@ -965,6 +1425,7 @@ func (pr *projectRepository) ClearTrash(userID uint) error {
 				FROM project_owners po
 				WHERE po.project_id = project_owners.project_id
 				AND po.user_id = ?
+				AND po.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.ProjectOwner{}).Error; err != nil {
@@ -981,6 +1442,7 @@ func (pr *projectRepository) ClearTrash(userID uint) error {
 				FROM project_owners po
 				WHERE po.project_id = projects.id
 				AND po.user_id = ?
+				AND po.organization_id IS NULL
 			)
 		`, userID).
 		Find(&projects).Error; err != nil {
@@ -1005,6 +1467,7 @@ func (pr *projectRepository) ClearTrash(userID uint) error {
 				FROM project_owners po
 				WHERE po.project_id = projects.id
 				AND po.user_id = ?
+				AND po.organization_id IS NULL
 			)
 		`, userID).
 		Delete(&model.Project{}).Error; err != nil {
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/pkg/db"
+	"gorm.io/gorm"
+)
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+type SessionRepository interface {
+	Create(createModel *model.Session) error
+
+	Get(id uint) (*model.Session, error)
+	GetByRefreshToken(hash string) (*model.Session, error)
+	GetActiveByUser(userID uint) ([]*model.Session, error)
+
+	Rotate(id uint, oldHash, newHash string, expiresAt time.Time, ip string) error
+
+	Revoke(userID, id uint) error
+	RevokeAll(userID uint) error
+}
+
+var ErrSessionRevoked = errors.New("session was revoked or has expired")
+
+func NewSessionRepository() SessionRepository {
+	return &sessionRepository{db: db.Postgres}
+}
+
+func (sr *sessionRepository) Create(createModel *model.Session) error {
+	return sr.db.Create(createModel).Error
+}
+
+func (sr *sessionRepository) Get(id uint) (*model.Session, error) {
+	var session *model.Session
+
+	if err := sr.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (sr *sessionRepository) GetByRefreshToken(hash string) (*model.Session, error) {
+	var session *model.Session
+
+	if err := sr.db.First(&session, &model.Session{RefreshToken: hash}).Error; err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func (sr *sessionRepository) GetActiveByUser(userID uint) ([]*model.Session, error) {
+	var sessions []*model.Session
+
+	if err := sr.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).Order("last_used_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// The update only goes through if the refresh token was not rotated or revoked in the meantime,
+// so the same refresh token can never be exchanged twice
+func (sr *sessionRepository) Rotate(id uint, oldHash, newHash string, expiresAt time.Time, ip string) error {
+	result := sr.db.Model(&model.Session{}).
+		Where("id = ? AND refresh_token = ? AND revoked_at IS NULL AND expires_at > ?", id, oldHash, time.Now()).
+		Updates(map[string]any{
+			"refresh_token": newHash,
+			"expires_at":    expiresAt,
+			"last_used_at":  time.Now(),
+			"ip":            ip,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+func (sr *sessionRepository) Revoke(userID, id uint) error {
+	result := sr.db.Model(&model.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (sr *sessionRepository) RevokeAll(userID uint) error {
+	return sr.db.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
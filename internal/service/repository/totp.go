@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/pkg/db"
+	"gorm.io/gorm"
+)
+
+type totpRepository struct {
+	db *gorm.DB
+}
+
+type TOTPRepository interface {
+	Get(userID uint) (*model.UserTOTP, error)
+
+	Enroll(userID uint, secret string) error
+	Enable(userID uint, step int64, recoveryCodes []string) error
+	Disable(userID uint) error
+
+	UseStep(userID uint, step int64) error
+	UseRecoveryCode(userID uint, code string) error
+}
+
+var (
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTOTPInvalidCode    = errors.New("invalid or already used two-factor code")
+)
+
+func NewTOTPRepository() TOTPRepository {
+	return &totpRepository{db: db.Postgres}
+}
+
+func (tr *totpRepository) Get(userID uint) (*model.UserTOTP, error) {
+	var totp *model.UserTOTP
+
+	if err := tr.db.First(&totp, &model.UserTOTP{UserID: userID}).Error; err != nil {
+		return nil, err
+	}
+
+	return totp, nil
+}
+
+// Starts (or restarts) an enrollment. A pending enrollment gets a new secret, an enabled one is left untouched.
+func (tr *totpRepository) Enroll(userID uint, secret string) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		totp := &model.UserTOTP{}
+		err := tx.Where("user_id = ?", userID).First(totp).Error
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&model.UserTOTP{UserID: userID, Secret: secret}).Error
+		} else if err != nil {
+			return err
+		}
+
+		if totp.Enabled {
+			return ErrTOTPAlreadyEnabled
+		}
+
+		return tx.Model(&model.UserTOTP{}).Where("user_id = ?", userID).Updates(map[string]any{
+			"secret":         secret,
+			"last_used_step": 0,
+		}).Error
+	})
+}
+
+func (tr *totpRepository) Enable(userID uint, step int64, recoveryCodes []string) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.UserTOTP{}).Where("user_id = ? AND enabled = ?", userID, false).Updates(map[string]any{
+			"enabled":        true,
+			"last_used_step": step,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrTOTPAlreadyEnabled
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&model.TOTPRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]model.TOTPRecoveryCode, len(recoveryCodes))
+		for i, code := range recoveryCodes {
+			codes[i] = model.TOTPRecoveryCode{UserID: userID, Code: code}
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+func (tr *totpRepository) Disable(userID uint) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND enabled = ?", userID, true).Delete(&model.UserTOTP{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrTOTPNotEnabled
+		}
+
+		return tx.Where("user_id = ?", userID).Delete(&model.TOTPRecoveryCode{}).Error
+	})
+}
+
+// Marks a time step as used. Fails if the same (or a later) step was already accepted.
+func (tr *totpRepository) UseStep(userID uint, step int64) error {
+	result := tr.db.Model(&model.UserTOTP{}).
+		Where("user_id = ? AND enabled = ? AND last_used_step < ?", userID, true, step).
+		Update("last_used_step", step)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTOTPInvalidCode
+	}
+
+	return nil
+}
+
+func (tr *totpRepository) UseRecoveryCode(userID uint, code string) error {
+	result := tr.db.Model(&model.TOTPRecoveryCode{}).
+		Where("user_id = ? AND code = ? AND used_at IS NULL", userID, code).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrTOTPInvalidCode
+	}
+
+	return nil
+}
@@ -35,8 +35,21 @@ func NewUserRepository() UserRepository {
 	return &userRepository{db: db.Postgres, followRepo: NewFollowRepository(), permissionRepo: NewPermissionRepository(), notificationRepo: NewNotificationRepository()}
 }
 
+// The starting balance of the user is recorded as the first entry of their ledger
 func (u userRepository) Create(createModel *model.User) error {
-	return u.db.Create(&createModel).Error
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&createModel).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&model.ArkhoinTransaction{
+			Type:         dto.ArkhoinGrant,
+			UserID:       &createModel.ID,
+			Amount:       int64(createModel.Arkhoin),
+			BalanceAfter: createModel.Arkhoin,
+			Reason:       dto.ArkhoinOpeningBalance,
+		}).Error
+	})
 }
 
 func (u userRepository) Update(id uint, updateModel *dto.UserUpdate) error {
@@ -61,6 +74,7 @@ func (u userRepository) Get(searchModel *model.User) (*dto.UserProfile, error) {
 	}
 
 	user.Permissions = []string{}
+	user.Level = levelOf(user.XP)
 
 	if count, err := u.followRepo.GetFollowersCount(user.ID); err != nil {
 		return nil, err
@@ -138,9 +152,20 @@ func (u userRepository) Search(issuerID uint, search *dto.SearchUser, page, perp
 		query = query.Order("created_at " + orderDirection)
 	}
 
-	return pagination.Generate[dto.UserProfile](query, page, perpage)
+	users, err := pagination.Generate[dto.UserProfile](query, page, perpage)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users.Data {
+		user.Level = levelOf(user.XP)
+	}
+
+	return users, nil
 }
 
+// Deletes the user along with their own projects. Projects and components of organizations stay with them, and
+// users who are the last owner of an organization cannot be deleted.
 func (u userRepository) Delete(id uint) error {
 	tx := u.db.Begin()
 
@@ -150,6 +175,26 @@ func (u userRepository) Delete(id uint) error {
 		return err
 	}
 
+	var lastOwnerOf int64
+	if err := tx.Model(&model.OrganizationMember{}).
+		Where("user_id = ? AND role = ?", id, dto.OrganizationRoleOwner).
+		Where(`NOT EXISTS (
+			SELECT 1
+			FROM organization_members om
+			WHERE om.organization_id = organization_members.organization_id
+			AND om.user_id <> ?
+			AND om.role = ?
+		)`, id, dto.OrganizationRoleOwner).
+		Count(&lastOwnerOf).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if lastOwnerOf > 0 {
+		tx.Rollback()
+		return ErrOrganizationLastOwner
+	}
+
 	if err := tx.Where("owner = ?", user.Username).Unscoped().Delete(&model.APIKey{}).Error; err != nil {
 		tx.Rollback()
 		return err
@@ -160,13 +205,13 @@ func (u userRepository) Delete(id uint) error {
 		return err
 	}
 
-	if err := tx.Where("project_id IN (?)", tx.Model(&model.ProjectOwner{}).Select("project_id").Where("user_id = ?", id)).Unscoped().Delete(&model.ProjectPublication{}).Error; err != nil {
+	if err := tx.Where("project_id IN (?)", tx.Model(&model.ProjectOwner{}).Select("project_id").Where("user_id = ? AND organization_id IS NULL", id)).Unscoped().Delete(&model.ProjectPublication{}).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
 	var projectIDs []uint
-	if err := tx.Model(&model.ProjectOwner{}).Where("user_id = ?", id).Pluck("project_id", &projectIDs).Error; err != nil {
+	if err := tx.Model(&model.ProjectOwner{}).Where("user_id = ? AND organization_id IS NULL", id).Pluck("project_id", &projectIDs).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -185,12 +230,37 @@ func (u userRepository) Delete(id uint) error {
 			}
 		}
 
+		if err := tx.Where("project_id = ?", projectID).Delete(&model.ProjectOwner{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Where("project_id = ?", projectID).Delete(&model.ProjectInvite{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if err := tx.Where("project_id = ?", projectID).Delete(&model.ProjectTransfer{}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+
 		if err := tx.Where("id = ?", projectID).Unscoped().Delete(&model.Project{}).Error; err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
+	if err := tx.Model(&model.ProjectOwner{}).Where("user_id = ?", id).Update("user_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", id).Delete(&model.OrganizationMember{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Where("user_id = ?", id).Unscoped().Delete(&model.ProjectUserPermission{}).Error; err != nil {
 		tx.Rollback()
 		return err
@@ -201,6 +271,42 @@ func (u userRepository) Delete(id uint) error {
 		return err
 	}
 
+	if err := tx.Where("user_id = ?", id).Delete(&model.ProjectShareLinkRedemption{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Invites and transfers sent to the user go away with them, those they sent for projects of organizations stay valid
+	if err := tx.Where("user_id = ?", id).Delete(&model.ProjectInvite{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&model.ProjectInvite{}).Where("inviter_id = ?", id).Update("inviter_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("recipient_id = ?", id).Delete(&model.ProjectTransfer{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&model.ProjectTransfer{}).Where("issuer_id = ?", id).Update("issuer_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("recipient_id = ?", id).Delete(&model.ComponentTransfer{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&model.ComponentTransfer{}).Where("issuer_id = ?", id).Update("issuer_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Model(&model.ComponentOwner{}).Where("user_id = ?", id).Update("user_id", nil).Error; err != nil {
 		tx.Rollback()
 		return err
@@ -221,6 +327,47 @@ func (u userRepository) Delete(id uint) error {
 		return err
 	}
 
+	if err := tx.Where("user_id = ?", id).Delete(&model.Session{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", id).Delete(&model.UserTOTP{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", id).Delete(&model.TOTPRecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", id).Delete(&model.EmailVerificationKey{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", id).Delete(&model.XPEvent{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", id).Delete(&model.UserAchievement{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// The audit trail of grants outlives the users in it, their usernames are kept on each entry
+	if err := tx.Model(&model.Grant{}).Where("user_id = ?", id).Update("user_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&model.Grant{}).Where("issuer_id = ?", id).Update("issuer_id", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	if err := tx.Where("id = ?", id).Unscoped().Delete(&model.User{}).Error; err != nil {
 		tx.Rollback()
 		return err
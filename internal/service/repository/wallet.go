@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type walletRepository struct {
+	db *gorm.DB
+}
+
+type WalletRepository interface {
+	GetByUser(userID uint, page, perPage int) (*dto.Pagination[dto.ArkhoinTransactionInfo], error)
+	GetByOrganization(organizationID uint, page, perPage int) (*dto.Pagination[dto.ArkhoinTransactionInfo], error)
+
+	Reconcile() (*dto.WalletReconciliation, error)
+}
+
+var (
+	ErrAlreadyProcessed     = errors.New("a request with the same idempotency key was already processed")
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+)
+
+func NewWalletRepository() WalletRepository {
+	return &walletRepository{db: db.Postgres}
+}
+
+// Locks the wallet of the organization when one is given, otherwise the one of the user, and returns its balance
+func walletBalance(tx *gorm.DB, userID, organizationID *uint) (uint64, error) {
+	var balance uint64
+
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("arkhoin")
+	if organizationID != nil {
+		query = query.Model(&model.Organization{}).Where("id = ?", *organizationID)
+	} else if userID != nil {
+		query = query.Model(&model.User{}).Where("id = ?", *userID)
+	} else {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	result := query.Scan(&balance)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	return balance, nil
+}
+
+type wallet struct {
+	UserID         *uint
+	OrganizationID *uint
+}
+
+// Locks the wallets in the same order whatever order they are given in, users first and then organizations, by id.
+// Two transactions moving Arkhoins between the same wallets in opposite directions would deadlock otherwise.
+func lockWallets(tx *gorm.DB, wallets ...wallet) error {
+	var users, organizations []uint
+	for _, w := range wallets {
+		if w.OrganizationID != nil {
+			organizations = append(organizations, *w.OrganizationID)
+		} else if w.UserID != nil {
+			users = append(users, *w.UserID)
+		}
+	}
+
+	var locked []uint
+
+	// Chunked so airdrops to every user stay below the limit of query parameters
+	lock := func(walletModel any, ids []uint) error {
+		slices.Sort(ids)
+
+		for start := 0; start < len(ids); start += 10000 {
+			chunk := ids[start:min(start+10000, len(ids))]
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Model(walletModel).Where("id IN ?", chunk).Order("id").Pluck("id", &locked).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := lock(&model.User{}, users); err != nil {
+		return err
+	}
+
+	return lock(&model.Organization{}, organizations)
+}
+
+// Looks for the entry a previous request sent with the same key wrote. The wallet of the user must be locked
+// already, so a retry racing the original request waits for it and then sees its entry.
+func idempotentReplay(tx *gorm.DB, userID uint, key *string, kind string, componentID uint) error {
+	if key == nil {
+		return nil
+	}
+
+	var entry model.ArkhoinTransaction
+	err := tx.Where("user_id = ? AND idempotency_key = ?", userID, *key).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if entry.Type != kind || entry.ComponentID == nil || *entry.ComponentID != componentID {
+		return ErrIdempotencyKeyReused
+	}
+
+	return ErrAlreadyProcessed
+}
+
+// Applies the amount of the entry to its wallet and appends the entry to the ledger. Must run inside a transaction,
+// wallets never go below zero.
+func moveArkhoins(tx *gorm.DB, entry *model.ArkhoinTransaction) error {
+	balance, err := walletBalance(tx, entry.UserID, entry.OrganizationID)
+	if err != nil {
+		return err
+	}
+
+	if entry.Amount < 0 && uint64(-entry.Amount) > balance {
+		return ErrInsufficientArkhoins
+	}
+
+	entry.BalanceAfter = uint64(int64(balance) + entry.Amount)
+
+	var update *gorm.DB
+	if entry.OrganizationID != nil {
+		update = tx.Model(&model.Organization{}).Where("id = ?", *entry.OrganizationID)
+	} else {
+		update = tx.Model(&model.User{}).Where("id = ?", *entry.UserID)
+	}
+
+	if err := update.Update("arkhoin", entry.BalanceAfter).Error; err != nil {
+		return err
+	}
+
+	return tx.Create(entry).Error
+}
+
+func (wr *walletRepository) baseTransactionQuery() *gorm.DB {
+	return wr.db.Table("arkhoin_transactions t").
+		Select(`
+			t.id AS id,
+			t.created_at AS created_at,
+			t.type AS type,
+			t.amount AS amount,
+			t.balance_after AS balance_after,
+			t.user_id AS user_id,
+			COALESCE(u.username, '') AS username,
+			t.organization_id AS organization_id,
+			t.counterparty_user_id AS counterparty_user_id,
+			COALESCE(cu.username, '') AS counterparty_username,
+			t.counterparty_organization_id AS counterparty_organization_id,
+			COALESCE(co.name, '') AS counterparty_organization_name,
+			t.component_id AS component_id,
+			COALESCE(c.name, '') AS component_name,
+			t.reason AS reason
+		`).
+		Joins("LEFT JOIN users u ON u.id = t.user_id").
+		Joins("LEFT JOIN users cu ON cu.id = t.counterparty_user_id").
+		Joins("LEFT JOIN organizations co ON co.id = t.counterparty_organization_id").
+		Joins("LEFT JOIN components c ON c.id = t.component_id").
+		Order("t.id DESC")
+}
+
+func (wr *walletRepository) GetByUser(userID uint, page, perPage int) (*dto.Pagination[dto.ArkhoinTransactionInfo], error) {
+	query := wr.baseTransactionQuery().Where("t.user_id = ? AND t.organization_id IS NULL", userID)
+	return pagination.Generate[dto.ArkhoinTransactionInfo](query, page, perPage)
+}
+
+func (wr *walletRepository) GetByOrganization(organizationID uint, page, perPage int) (*dto.Pagination[dto.ArkhoinTransactionInfo], error) {
+	query := wr.baseTransactionQuery().Where("t.organization_id = ?", organizationID)
+	return pagination.Generate[dto.ArkhoinTransactionInfo](query, page, perPage)
+}
+
+// Compares the balance stored in every wallet with the sum of its ledger entries
+func (wr *walletRepository) Reconcile() (*dto.WalletReconciliation, error) {
+	reconciliation := &dto.WalletReconciliation{Mismatches: []dto.WalletMismatch{}}
+
+	var users, organizations int64
+	if err := wr.db.Model(&model.User{}).Count(&users).Error; err != nil {
+		return nil, err
+	}
+
+	if err := wr.db.Model(&model.Organization{}).Count(&organizations).Error; err != nil {
+		return nil, err
+	}
+
+	reconciliation.Wallets = users + organizations
+
+	err := wr.db.Raw(`
+		SELECT 'user' AS wallet, u.id AS id, u.username AS name, u.arkhoin AS balance, COALESCE(l.total, 0) AS ledger
+		FROM users u
+		LEFT JOIN (
+			SELECT user_id, SUM(amount) AS total
+			FROM arkhoin_transactions
+			WHERE organization_id IS NULL
+			GROUP BY user_id
+		) l ON l.user_id = u.id
+		WHERE u.arkhoin <> COALESCE(l.total, 0)
+		UNION ALL
+		SELECT 'organization' AS wallet, o.id AS id, o.name AS name, o.arkhoin AS balance, COALESCE(l.total, 0) AS ledger
+		FROM organizations o
+		LEFT JOIN (
+			SELECT organization_id, SUM(amount) AS total
+			FROM arkhoin_transactions
+			WHERE organization_id IS NOT NULL
+			GROUP BY organization_id
+		) l ON l.organization_id = o.id
+		WHERE o.arkhoin <> COALESCE(l.total, 0)
+		ORDER BY wallet, id
+	`).Scan(&reconciliation.Mismatches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciliation, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type xpRepository struct {
+	db *gorm.DB
+}
+
+type XPRepository interface {
+	Award(userID uint, rule, reference string, amount, ruleCap, dailyCap uint64) (*dto.XPChange, error)
+}
+
+func NewXPRepository() XPRepository {
+	return &xpRepository{db: db.Postgres}
+}
+
+func levelOf(xp uint64) dto.Level {
+	return dto.NewLevel(xp, config.XP.Levels.Thresholds, config.XP.Levels.Step)
+}
+
+// Gives the user up to amount XP for the rule, less when it would go over what the rule or all rules together
+// can earn today. Nothing is given when the reference was already rewarded for the rule.
+func (xr *xpRepository) Award(userID uint, rule, reference string, amount, ruleCap, dailyCap uint64) (*dto.XPChange, error) {
+	change := &dto.XPChange{UserID: userID}
+
+	err := xr.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the user keeps concurrent awards from going over the caps together
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "xp").Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+
+		change.Before = user.XP
+		change.After = user.XP
+
+		err := tx.Where("user_id = ? AND rule = ? AND reference = ?", userID, rule, reference).First(&model.XPEvent{}).Error
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		var earned struct {
+			Rule  uint64
+			Total uint64
+		}
+
+		if err := tx.Model(&model.XPEvent{}).
+			Select("COALESCE(SUM(amount) FILTER (WHERE rule = ?), 0) AS rule, COALESCE(SUM(amount), 0) AS total", rule).
+			Where("user_id = ? AND created_at >= ?", userID, today).
+			Scan(&earned).Error; err != nil {
+			return err
+		}
+
+		amount = capped(amount, ruleCap, earned.Rule)
+		amount = capped(amount, dailyCap, earned.Total)
+
+		if amount == 0 {
+			return nil
+		}
+
+		if err := tx.Create(&model.XPEvent{UserID: userID, Rule: rule, Reference: reference, Amount: amount}).Error; err != nil {
+			return err
+		}
+
+		change.After += amount
+
+		return tx.Model(&model.User{}).Where("id = ?", userID).Update("xp", change.After).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// What is left of amount once earned is taken from the limit, a limit of 0 meaning there is none
+func capped(amount, limit, earned uint64) uint64 {
+	if limit == 0 {
+		return amount
+	}
+
+	if earned >= limit {
+		return 0
+	}
+
+	return min(amount, limit-earned)
+}
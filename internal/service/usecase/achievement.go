@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type AchievementUseCase struct {
+	ar repository.AchievementRepository
+}
+
+func NewAchievementUseCase() AchievementUseCase {
+	return AchievementUseCase{ar: repository.NewAchievementRepository()}
+}
+
+func (auc AchievementUseCase) Evaluate(userID uint, metric string) ([]model.Achievement, *dto.XPChange, error) {
+	return auc.ar.Evaluate(userID, metric)
+}
+
+func (auc AchievementUseCase) GetByUser(userID uint) ([]dto.AchievementInfo, error) {
+	return auc.ar.GetByUser(userID)
+}
@@ -1,87 +1,211 @@
 package usecase
 
 import (
+	"log"
+	"sync"
+	"time"
+
 	"github.com/swibly/swibly-api/internal/model"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/internal/service/repository"
-
-	"github.com/google/uuid"
+	"github.com/swibly/swibly-api/pkg/utils"
 )
 
 type APIKeyUseCase struct {
 	akr repository.APIKeyRepository
 }
 
+type apiKeyPendingUsage struct {
+	uses       uint
+	lastUsedAt time.Time
+	lastUsedIP string
+}
+
+// Uses are counted in memory and written to the database in batches by FlushUsage,
+// so guarded requests don't cost an UPDATE each
+var apiKeyUsage = struct {
+	sync.Mutex
+	pending map[uint]*apiKeyPendingUsage
+}{pending: make(map[uint]*apiKeyPendingUsage)}
+
 func NewAPIKeyUseCase() APIKeyUseCase {
 	return APIKeyUseCase{akr: repository.NewAPIKeyRepository()}
 }
 
-func (akuc *APIKeyUseCase) Create(owner string, maxUsage uint) (*dto.ReadAPIKey, error) {
+func (akuc *APIKeyUseCase) generate() (key, prefix string, err error) {
+	key, prefix, err = utils.GenerateAPIKey()
+	if err != nil {
+		return "", "", err
+	}
+
+	if existingKey, _ := akuc.GetByPrefix(prefix); existingKey != nil {
+		return akuc.generate()
+	}
+
+	return key, prefix, nil
+}
+
+func (akuc *APIKeyUseCase) Create(createModel *dto.CreateAPIKey) (*dto.ReadAPIKey, error) {
+	secret, prefix, err := akuc.generate()
+	if err != nil {
+		return nil, err
+	}
+
 	key := new(model.APIKey)
-	key.Key = uuid.New().String()
+	key.Key = utils.HashToken(secret)
+	key.Prefix = prefix
 
-	if owner != "" {
-		if _, err := NewUserUseCase().GetByUsername(owner); err != nil {
+	if createModel.Owner != "" {
+		if _, err := NewUserUseCase().GetByUsername(createModel.Owner); err != nil {
 			return nil, err
 		}
 
-		key.Owner = owner
+		key.Owner = createModel.Owner
 	}
 
-	key.MaxUsage = maxUsage
+	key.MaxUsage = createModel.MaxUsage
+	key.RateLimitMinute = createModel.RateLimitMinute
+	key.RateLimitHour = createModel.RateLimitHour
+	key.RateLimitDay = createModel.RateLimitDay
+	key.ExpiresAt = createModel.ExpiresAt
+
+	if err := akuc.akr.Create(key); err != nil {
+		return nil, err
+	}
 
 	return &dto.ReadAPIKey{
+		ID:                 key.ID,
 		Key:                key.Key,
+		Prefix:             key.Prefix,
 		Owner:              key.Owner,
+		Secret:             secret,
 		EnabledKeyManage:   key.EnabledKeyManage,
 		EnabledAuth:        key.EnabledAuth,
 		EnabledSearch:      key.EnabledSearch,
 		EnabledUserFetch:   key.EnabledUserFetch,
 		EnabledUserActions: key.EnabledUserActions,
+		EnabledProjects:    key.EnabledProjects,
 		TimesUsed:          key.TimesUsed,
 		MaxUsage:           key.MaxUsage,
-	}, akuc.akr.Create(key)
+		RateLimitMinute:    key.RateLimitMinute,
+		RateLimitHour:      key.RateLimitHour,
+		RateLimitDay:       key.RateLimitDay,
+		ExpiresAt:          key.ExpiresAt,
+	}, nil
 }
 
-func (akuc *APIKeyUseCase) Update(key string, updateModel *dto.UpdateAPIKey) error {
-	return akuc.akr.Update(key, &model.APIKey{
+func (akuc *APIKeyUseCase) Update(prefix string, updateModel *dto.UpdateAPIKey) error {
+	if updateModel.Owner != "" {
+		if _, err := NewUserUseCase().GetByUsername(updateModel.Owner); err != nil {
+			return err
+		}
+	}
+
+	return akuc.akr.Update(prefix, &model.APIKey{
 		Owner:              updateModel.Owner,
 		EnabledKeyManage:   updateModel.EnabledKeyManage,
 		EnabledAuth:        updateModel.EnabledAuth,
 		EnabledSearch:      updateModel.EnabledSearch,
 		EnabledUserFetch:   updateModel.EnabledUserFetch,
 		EnabledUserActions: updateModel.EnabledUserActions,
+		EnabledProjects:    updateModel.EnabledProjects,
 		TimesUsed:          updateModel.TimesUsed,
 		MaxUsage:           updateModel.MaxUsage,
+		RateLimitMinute:    updateModel.RateLimitMinute,
+		RateLimitHour:      updateModel.RateLimitHour,
+		RateLimitDay:       updateModel.RateLimitDay,
+		ExpiresAt:          updateModel.ExpiresAt,
 	})
 }
 
-func (akuc *APIKeyUseCase) Delete(key string) error {
-	return akuc.akr.Delete(key)
+func (akuc *APIKeyUseCase) Delete(prefix string) error {
+	return akuc.akr.Delete(prefix)
 }
 
 func (akuc *APIKeyUseCase) GetAll(page, perPage int) (*dto.Pagination[dto.ReadAPIKey], error) {
 	return akuc.akr.GetAll(page, perPage)
 }
 
-func (akuc *APIKeyUseCase) GetByKey(key string) (*dto.ReadAPIKey, error) {
-	return akuc.akr.GetByKey(key)
+// Looks up a key by the secret sent by clients
+func (akuc *APIKeyUseCase) GetByKey(secret string) (*dto.ReadAPIKey, error) {
+	return akuc.akr.GetByKey(utils.HashToken(secret))
+}
+
+func (akuc *APIKeyUseCase) GetByPrefix(prefix string) (*dto.ReadAPIKey, error) {
+	return akuc.akr.GetByPrefix(prefix)
 }
 
 func (akuc *APIKeyUseCase) GetByOwner(owner string, page, perPage int) (*dto.Pagination[dto.ReadAPIKey], error) {
 	return akuc.akr.GetByOwner(owner, page, perPage)
 }
 
-func (akuc *APIKeyUseCase) RegisterUse(key string) error {
-	return akuc.akr.RegisterUse(key)
+func (akuc *APIKeyUseCase) RegisterUse(key *dto.ReadAPIKey, ip string) {
+	apiKeyUsage.Lock()
+	defer apiKeyUsage.Unlock()
+
+	usage, ok := apiKeyUsage.pending[key.ID]
+	if !ok {
+		usage = &apiKeyPendingUsage{}
+		apiKeyUsage.pending[key.ID] = usage
+	}
+
+	usage.uses++
+	usage.lastUsedAt = time.Now()
+	usage.lastUsedIP = ip
+}
+
+// Stored uses plus the ones that were not flushed yet
+func (akuc *APIKeyUseCase) TimesUsed(key *dto.ReadAPIKey) uint {
+	apiKeyUsage.Lock()
+	defer apiKeyUsage.Unlock()
+
+	if usage, ok := apiKeyUsage.pending[key.ID]; ok {
+		return key.TimesUsed + usage.uses
+	}
+
+	return key.TimesUsed
+}
+
+func (akuc *APIKeyUseCase) FlushUsage() {
+	apiKeyUsage.Lock()
+	pending := apiKeyUsage.pending
+	apiKeyUsage.pending = make(map[uint]*apiKeyPendingUsage)
+	apiKeyUsage.Unlock()
+
+	for id, usage := range pending {
+		if err := akuc.akr.AddUses(id, usage.uses, usage.lastUsedAt, usage.lastUsedIP); err != nil {
+			log.Print(err)
+
+			// Put them back so they are retried in the next flush
+			apiKeyUsage.Lock()
+			if current, ok := apiKeyUsage.pending[id]; ok {
+				current.uses += usage.uses
+			} else {
+				apiKeyUsage.pending[id] = usage
+			}
+			apiKeyUsage.Unlock()
+		}
+	}
 }
 
-func (akuc *APIKeyUseCase) Regenerate(key string) error {
-	newKey := uuid.New().String()
+func (akuc *APIKeyUseCase) FlushUsageEvery(interval time.Duration) {
+	for range time.Tick(interval) {
+		akuc.FlushUsage()
+	}
+}
+
+// Replaces the secret of the key, keeping its prefix. The new secret is only returned here.
+func (akuc *APIKeyUseCase) Regenerate(prefix string) (string, error) {
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	secret = utils.APIKeyIdentifier + "_" + prefix + "_" + secret
 
-	if existingKey, _ := akuc.GetByKey(newKey); existingKey != nil {
-		return akuc.Regenerate(key)
+	if err := akuc.akr.Regenerate(prefix, utils.HashToken(secret)); err != nil {
+		return "", err
 	}
 
-	return akuc.akr.Update(key, &model.APIKey{Key: newKey})
+	return secret, nil
 }
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type ComponentTransferUseCase struct {
+	ctr repository.ComponentTransferRepository
+}
+
+func NewComponentTransferUseCase() ComponentTransferUseCase {
+	return ComponentTransferUseCase{ctr: repository.NewComponentTransferRepository()}
+}
+
+func (ctuc ComponentTransferUseCase) Start(componentID, issuerID, recipientID uint, keepAccess bool) error {
+	return ctuc.ctr.Create(&model.ComponentTransfer{
+		ComponentID: componentID,
+		IssuerID:    &issuerID,
+		RecipientID: recipientID,
+		KeepAccess:  keepAccess,
+		ExpiresAt:   transferExpiry(),
+	})
+}
+
+func (ctuc ComponentTransferUseCase) Get(componentID uint) (*dto.ComponentTransferInfo, error) {
+	return ctuc.ctr.Get(componentID)
+}
+
+func (ctuc ComponentTransferUseCase) GetByRecipient(recipientID uint, page, perPage int) (*dto.Pagination[dto.ComponentTransferInfo], error) {
+	return ctuc.ctr.GetByRecipient(recipientID, page, perPage)
+}
+
+func (ctuc ComponentTransferUseCase) Accept(recipientID, transferID uint) (*dto.ComponentTransferInfo, error) {
+	transfer, err := ctuc.ctr.GetForRecipient(recipientID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, ctuc.ctr.Accept(transfer)
+}
+
+func (ctuc ComponentTransferUseCase) Decline(recipientID, transferID uint) (*dto.ComponentTransferInfo, error) {
+	transfer, err := ctuc.ctr.GetForRecipient(recipientID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, ctuc.ctr.Delete(transfer.ComponentID)
+}
+
+func (ctuc ComponentTransferUseCase) Cancel(componentID uint) error {
+	return ctuc.ctr.Delete(componentID)
+}
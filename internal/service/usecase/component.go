@@ -47,6 +47,10 @@ func (cuc *ComponentUseCase) GetByOwnerID(issuerID, ownerID uint, onlyPublic boo
 	return cuc.cr.GetByOwnerID(issuerID, ownerID, onlyPublic, page, perPage)
 }
 
+func (cuc *ComponentUseCase) GetByOrganization(issuerID, organizationID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error) {
+	return cuc.cr.GetByOrganization(issuerID, organizationID, onlyPublic, page, perPage)
+}
+
 func (cuc *ComponentUseCase) GetOwned(issuerID, userID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ComponentInfo], error) {
 	return cuc.cr.GetOwned(issuerID, userID, onlyPublic, page, perPage)
 }
@@ -59,12 +63,12 @@ func (cuc *ComponentUseCase) Search(issuerID uint, search *dto.SearchComponent,
 	return cuc.cr.Search(issuerID, search, page, perPage)
 }
 
-func (cuc *ComponentUseCase) Buy(issuerID, componentID uint) error {
-	return cuc.cr.Buy(issuerID, componentID)
+func (cuc *ComponentUseCase) Buy(issuerID, componentID uint, organizationID *uint, idempotencyKey *string) error {
+	return cuc.cr.Buy(issuerID, componentID, organizationID, idempotencyKey)
 }
 
-func (cuc *ComponentUseCase) Sell(issuerID, componentID uint) error {
-	return cuc.cr.Sell(issuerID, componentID)
+func (cuc *ComponentUseCase) Sell(issuerID, componentID uint, idempotencyKey *string) error {
+	return cuc.cr.Sell(issuerID, componentID, idempotencyKey)
 }
 
 func (cuc *ComponentUseCase) SafeDelete(componentID uint) error {
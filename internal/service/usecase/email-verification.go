@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"text/template"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/sender"
+	"github.com/swibly/swibly-api/translations"
+)
+
+type EmailVerificationUseCase struct {
+	evr repository.EmailVerificationRepository
+}
+
+func NewEmailVerificationUseCase() EmailVerificationUseCase {
+	return EmailVerificationUseCase{evr: repository.NewEmailVerificationRepository()}
+}
+
+func (evuc EmailVerificationUseCase) send(dict translations.Translation, userID uint, name, email string, throttle bool) error {
+	cooldown := config.Security.EmailVerificationCooldown
+	if !throttle {
+		cooldown = 0
+	}
+
+	data, err := evuc.evr.Request(userID, email, cooldown)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("email").Parse(dict.EmailVerificationEmailTemplate)
+	if err != nil {
+		return err
+	}
+
+	mapping := map[string]string{
+		"user": name,
+		"url":  fmt.Sprintf("https://www.swibly.com.br/verify/%s", url.QueryEscape(data.Key.String())),
+	}
+
+	var body bytes.Buffer
+	err = tmpl.Execute(&body, mapping)
+	if err != nil {
+		return err
+	}
+
+	sender.SMTPSender.Send(email, dict.EmailVerificationEmailSubject, body.String())
+
+	return nil
+}
+
+// Used when an address is set for the first time (register or email change)
+func (evuc EmailVerificationUseCase) Send(dict translations.Translation, userID uint, name, email string) error {
+	return evuc.send(dict, userID, name, email, false)
+}
+
+// Same as Send, but fails with repository.ErrEmailVerificationThrottled if an email was sent recently
+func (evuc EmailVerificationUseCase) Resend(dict translations.Translation, userID uint, name, email string) error {
+	return evuc.send(dict, userID, name, email, true)
+}
+
+func (evuc EmailVerificationUseCase) Verify(key string) error {
+	return evuc.evr.Verify(key)
+}
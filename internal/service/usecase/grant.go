@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type GrantUseCase struct {
+	gr repository.GrantRepository
+}
+
+func NewGrantUseCase() GrantUseCase {
+	return GrantUseCase{gr: repository.NewGrantRepository()}
+}
+
+func (guc GrantUseCase) Grant(issuerID uint, userIDs []uint, createModel *dto.GrantCreation) ([]dto.XPChange, error) {
+	return guc.gr.Apply(&issuerID, userIDs, createModel, nil)
+}
+
+func (guc GrantUseCase) GetAll(userID *uint, page, perPage int) (*dto.Pagination[dto.GrantInfo], error) {
+	return guc.gr.GetAll(userID, page, perPage)
+}
+
+func (guc GrantUseCase) ResolveUsernames(usernames []string) ([]uint, []string, error) {
+	return guc.gr.ResolveUsernames(usernames)
+}
+
+func (guc GrantUseCase) ScheduleAirdrop(issuerID uint, lang string, userIDs []uint, createModel *dto.AirdropCreation) (uint, error) {
+	airdrop := &model.Airdrop{
+		IssuerID:    &issuerID,
+		Currency:    createModel.Currency,
+		Amount:      createModel.Amount,
+		Reason:      createModel.Reason,
+		Everyone:    createModel.Everyone,
+		Language:    lang,
+		ScheduledAt: createModel.ScheduledAt,
+	}
+
+	if createModel.Everyone {
+		userIDs = nil
+	}
+
+	if err := guc.gr.CreateAirdrop(airdrop, userIDs); err != nil {
+		return 0, err
+	}
+
+	return airdrop.ID, nil
+}
+
+func (guc GrantUseCase) GetAirdrops(page, perPage int) (*dto.Pagination[dto.AirdropInfo], error) {
+	return guc.gr.GetAirdrops(page, perPage)
+}
+
+func (guc GrantUseCase) CancelAirdrop(airdropID uint) error {
+	return guc.gr.DeleteAirdrop(airdropID)
+}
+
+// Runs every airdrop that is due and calls done with the recipients of each one that went through, and how
+// their XP changed. A failing airdrop is logged and tried again next time.
+func (guc GrantUseCase) RunDueAirdrops(done func(airdrop *model.Airdrop, recipients []uint, changes []dto.XPChange)) {
+	ids, err := guc.gr.GetDueAirdrops(time.Now())
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	for _, id := range ids {
+		airdrop, recipients, changes, err := guc.gr.RunAirdrop(id)
+		if errors.Is(err, repository.ErrAirdropNotFound) {
+			continue
+		} else if err != nil {
+			log.Printf("airdrop %d: %v", id, err)
+			continue
+		}
+
+		done(airdrop, recipients, changes)
+	}
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type OrganizationUseCase struct {
+	or repository.OrganizationRepository
+}
+
+func NewOrganizationUseCase() OrganizationUseCase {
+	return OrganizationUseCase{or: repository.NewOrganizationRepository()}
+}
+
+func (ouc OrganizationUseCase) Create(createModel *dto.OrganizationCreation, ownerID uint) (uint, error) {
+	return ouc.or.Create(createModel, ownerID)
+}
+
+func (ouc OrganizationUseCase) Update(organizationID uint, updateModel *dto.OrganizationUpdate) error {
+	return ouc.or.Update(organizationID, updateModel)
+}
+
+func (ouc OrganizationUseCase) Delete(organizationID, issuerID uint) error {
+	return ouc.or.Delete(organizationID, issuerID)
+}
+
+func (ouc OrganizationUseCase) GetByName(issuerID uint, name string) (*dto.OrganizationInfo, error) {
+	return ouc.or.GetByName(issuerID, name)
+}
+
+func (ouc OrganizationUseCase) GetByMember(issuerID, userID uint, page, perPage int) (*dto.Pagination[dto.OrganizationInfo], error) {
+	return ouc.or.GetByMember(issuerID, userID, page, perPage)
+}
+
+func (ouc OrganizationUseCase) GetMembers(organizationID uint, page, perPage int) (*dto.Pagination[dto.OrganizationMemberInfo], error) {
+	return ouc.or.GetMembers(organizationID, page, perPage)
+}
+
+func (ouc OrganizationUseCase) GetRole(organizationID, userID uint) (string, error) {
+	return ouc.or.GetRole(organizationID, userID)
+}
+
+func (ouc OrganizationUseCase) SetMember(organizationID, userID uint, role string) error {
+	return ouc.or.SetMember(organizationID, userID, role)
+}
+
+func (ouc OrganizationUseCase) RemoveMember(organizationID, userID uint) error {
+	return ouc.or.RemoveMember(organizationID, userID)
+}
+
+func (ouc OrganizationUseCase) Deposit(organizationID, userID uint, amount uint64) error {
+	return ouc.or.Deposit(organizationID, userID, amount)
+}
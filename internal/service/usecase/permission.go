@@ -13,7 +13,33 @@ func NewPermissionUseCase() PermissionUseCase {
 	return PermissionUseCase{ur: repository.NewPermissionRepository()}
 }
 
+func (p PermissionUseCase) GetAll() ([]*model.Permission, error) {
+	return p.ur.GetAll()
+}
+
+func (p PermissionUseCase) GetByName(name string) (*model.Permission, error) {
+	return p.ur.GetByName(name)
+}
+
 func (p PermissionUseCase) GetByUser(userID uint) ([]*model.Permission, error) {
 	following, err := p.ur.GetByUser(userID)
 	return following, err
 }
+
+func (p PermissionUseCase) Grant(userID uint, name string) error {
+	permission, err := p.ur.GetByName(name)
+	if err != nil {
+		return err
+	}
+
+	return p.ur.Grant(userID, permission.ID)
+}
+
+func (p PermissionUseCase) Revoke(userID uint, name string) error {
+	permission, err := p.ur.GetByName(name)
+	if err != nil {
+		return err
+	}
+
+	return p.ur.Revoke(userID, permission.ID)
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/archive"
+	"github.com/swibly/swibly-api/pkg/aws"
+	"github.com/swibly/swibly-api/pkg/contentschema"
+	"gorm.io/gorm"
+)
+
+// Packs the metadata, content and banner of the project, along with the components its content uses
+func (puc ProjectUseCase) Export(issuerID, projectID uint) (*archive.Archive, error) {
+	project, err := puc.pr.Get(issuerID, &model.Project{ID: projectID})
+	if err != nil {
+		return nil, err
+	}
+
+	content, _, err := puc.pr.GetContent(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	components := []archive.ComponentReference{}
+	for _, id := range contentschema.ComponentReferences(content) {
+		reference := archive.ComponentReference{ID: id}
+
+		component, err := puc.cr.Get(issuerID, &model.Component{ID: id})
+		if err == nil {
+			reference.Name = component.Name
+			reference.OwnerUsername = component.OwnerUsername
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		components = append(components, reference)
+	}
+
+	var banner []byte
+	if project.BannerURL != "" {
+		// A banner that cannot be fetched should not make the rest of the project impossible to export
+		if banner, err = aws.DownloadProjectImage(project.BannerURL); err != nil {
+			log.Print(err)
+		}
+	}
+
+	return &archive.Archive{
+		Manifest: archive.Manifest{
+			ExportedAt: time.Now(),
+			Project: archive.Metadata{
+				Name:        project.Name,
+				Description: project.Description,
+				Width:       project.Width,
+				Height:      project.Height,
+				Budget:      project.Budget,
+			},
+			Components: components,
+		},
+		Content: content,
+		Banner:  banner,
+	}, nil
+}
+
+// Creates a private project owned by the issuer from an exported archive. Collaborators, publication
+// and fork links are not carried over. The components referenced by the content that no longer exist
+// are returned so the issuer knows what will be missing.
+func (puc ProjectUseCase) Import(issuerID uint, a *archive.Archive, createModel *dto.ProjectCreation) (uint, []archive.ComponentReference, error) {
+	missing := []archive.ComponentReference{}
+	for _, id := range contentschema.ComponentReferences(a.Content) {
+		component, err := puc.cr.Get(issuerID, &model.Component{ID: id})
+		if err == nil && !component.DeletedAt.Valid {
+			continue
+		}
+
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil, err
+		}
+
+		reference := archive.ComponentReference{ID: id}
+		for _, listed := range a.Manifest.Components {
+			if listed.ID == id {
+				reference = listed
+				break
+			}
+		}
+
+		missing = append(missing, reference)
+	}
+
+	createModel.Content = a.Content
+	createModel.BannerData = a.Banner
+	createModel.OwnerID = issuerID
+	createModel.Public = false
+	createModel.Fork = nil
+
+	id, err := puc.pr.Create(createModel)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, missing, nil
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/sender"
+	"github.com/swibly/swibly-api/translations"
+)
+
+type ProjectInviteUseCase struct {
+	pir repository.ProjectInviteRepository
+	pr  repository.ProjectRepository
+}
+
+func NewProjectInviteUseCase() ProjectInviteUseCase {
+	return ProjectInviteUseCase{
+		pir: repository.NewProjectInviteRepository(),
+		pr:  repository.NewProjectRepository(repository.NewUserRepository()),
+	}
+}
+
+func inviteExpiry() time.Time {
+	expiry := config.Projects.Invites.Expiry
+	if expiry <= 0 {
+		expiry = 7 * 24 * time.Hour
+	}
+
+	return time.Now().Add(expiry)
+}
+
+func (piuc ProjectInviteUseCase) Invite(projectID, inviterID, userID uint, allowList *dto.ProjectAssign) error {
+	return piuc.pir.Create(&model.ProjectInvite{
+		ProjectID: projectID,
+		InviterID: &inviterID,
+		UserID:    &userID,
+		Role:      allowList.RoleName(),
+		Allow:     allowList.Allow(),
+		ExpiresAt: inviteExpiry(),
+	})
+}
+
+// Invites someone without an account and emails them a link to sign up
+func (piuc ProjectInviteUseCase) InviteEmail(dict translations.Translation, project *dto.ProjectInfo, inviter *dto.UserProfile, email string, allowList *dto.ProjectAssign) error {
+	invite := &model.ProjectInvite{
+		ProjectID: project.ID,
+		InviterID: &inviter.ID,
+		Email:     email,
+		Role:      allowList.RoleName(),
+		Allow:     allowList.Allow(),
+		ExpiresAt: inviteExpiry(),
+	}
+
+	if err := piuc.pir.Create(invite); err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("email").Parse(dict.ProjectInviteEmailTemplate)
+	if err != nil {
+		return err
+	}
+
+	mapping := map[string]string{
+		"inviter": inviter.FirstName + " " + inviter.LastName,
+		"project": project.Name,
+		"expires": invite.ExpiresAt.Format(time.DateOnly),
+		"url":     fmt.Sprintf("https://www.swibly.com.br/register?email=%s", url.QueryEscape(email)),
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, mapping); err != nil {
+		return err
+	}
+
+	sender.SMTPSender.Send(email, dict.ProjectInviteEmailSubject, body.String())
+
+	return nil
+}
+
+func (piuc ProjectInviteUseCase) GetByProject(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectInviteInfo], error) {
+	return piuc.pir.GetByProject(projectID, page, perPage)
+}
+
+func (piuc ProjectInviteUseCase) GetByInvitee(invitee *dto.UserProfile, page, perPage int) (*dto.Pagination[dto.ProjectInviteInfo], error) {
+	return piuc.pir.GetByInvitee(invitee, page, perPage)
+}
+
+// Turns the invite into the permissions it carries
+func (piuc ProjectInviteUseCase) Accept(invitee *dto.UserProfile, inviteID uint) (*dto.ProjectInviteInfo, error) {
+	invite, err := piuc.pir.GetForInvitee(invitee, inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := piuc.pr.Assign(invitee.ID, invite.ProjectID, invite.Assign()); err != nil {
+		return nil, err
+	}
+
+	return invite, piuc.pir.Delete(invite.ProjectID, invite.ID)
+}
+
+func (piuc ProjectInviteUseCase) Decline(invitee *dto.UserProfile, inviteID uint) (*dto.ProjectInviteInfo, error) {
+	invite, err := piuc.pir.GetForInvitee(invitee, inviteID)
+	if err != nil {
+		return nil, err
+	}
+
+	return invite, piuc.pir.Delete(invite.ProjectID, invite.ID)
+}
+
+func (piuc ProjectInviteUseCase) Cancel(projectID, inviteID uint) error {
+	return piuc.pir.Delete(projectID, inviteID)
+}
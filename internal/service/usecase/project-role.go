@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type ProjectRoleUseCase struct {
+	prr repository.ProjectRoleRepository
+}
+
+func NewProjectRoleUseCase() ProjectRoleUseCase {
+	return ProjectRoleUseCase{prr: repository.NewProjectRoleRepository()}
+}
+
+func (pruc ProjectRoleUseCase) Create(projectID uint, createModel *dto.ProjectRoleCreation) error {
+	name := strings.ToLower(createModel.Name)
+
+	if _, ok := dto.ProjectRolePreset(name); ok {
+		return repository.ErrProjectRoleNameTaken
+	}
+
+	allow := createModel.Allow()
+	if allow.IsEmpty() {
+		return repository.ErrProjectRoleEmpty
+	}
+
+	return pruc.prr.Create(projectID, name, allow)
+}
+
+func (pruc ProjectRoleUseCase) Update(projectID uint, name string, updateModel *dto.ProjectRoleUpdate) error {
+	name = strings.ToLower(name)
+
+	if _, ok := dto.ProjectRolePreset(name); ok {
+		return repository.ErrProjectRoleIsPreset
+	}
+
+	role, err := pruc.prr.Get(projectID, name)
+	if err != nil {
+		return err
+	}
+
+	newName := name
+	if updateModel.Name != nil {
+		newName = strings.ToLower(*updateModel.Name)
+	}
+
+	if _, ok := dto.ProjectRolePreset(newName); ok {
+		return repository.ErrProjectRoleNameTaken
+	}
+
+	allow := updateModel.Apply(role.Allow())
+	if allow.IsEmpty() {
+		return repository.ErrProjectRoleEmpty
+	}
+
+	return pruc.prr.Update(projectID, name, newName, allow)
+}
+
+func (pruc ProjectRoleUseCase) Delete(projectID uint, name string) error {
+	name = strings.ToLower(name)
+
+	if _, ok := dto.ProjectRolePreset(name); ok {
+		return repository.ErrProjectRoleIsPreset
+	}
+
+	return pruc.prr.Delete(projectID, name)
+}
+
+func (pruc ProjectRoleUseCase) GetByProject(projectID uint) ([]dto.ProjectRoleInfo, error) {
+	return pruc.prr.GetByProject(projectID)
+}
+
+// Replaces the flags of the assignment with the permissions of the role it names, if any
+func (pruc ProjectRoleUseCase) Resolve(projectID uint, assign *dto.ProjectAssign) error {
+	if assign.Role == nil {
+		return nil
+	}
+
+	name := strings.ToLower(*assign.Role)
+	if name == "" {
+		assign.Role = nil
+		return nil
+	}
+
+	allow, ok := dto.ProjectRolePreset(name)
+	if !ok {
+		role, err := pruc.prr.Get(projectID, name)
+		if err != nil {
+			return err
+		}
+
+		allow = role.Allow()
+	}
+
+	*assign = *allow.Assign(name)
+
+	return nil
+}
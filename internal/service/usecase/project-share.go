@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/utils"
+)
+
+type ProjectShareUseCase struct {
+	psr repository.ProjectShareRepository
+}
+
+func NewProjectShareUseCase() ProjectShareUseCase {
+	return ProjectShareUseCase{psr: repository.NewProjectShareRepository()}
+}
+
+// Mints a new link and returns it along with its token, which cannot be retrieved later
+func (psuc ProjectShareUseCase) Create(projectID, creatorID uint, createModel *dto.ProjectShareLinkCreation) (*dto.ProjectShareLinkInfo, string, error) {
+	now := time.Now()
+
+	defaultExpiry := config.Projects.ShareLinks.DefaultExpiry
+	if defaultExpiry <= 0 {
+		defaultExpiry = 7 * 24 * time.Hour
+	}
+
+	expiresAt := now.Add(defaultExpiry)
+	if createModel.ExpiresAt != nil {
+		expiresAt = *createModel.ExpiresAt
+	}
+
+	if !expiresAt.After(now) {
+		return nil, "", repository.ErrShareLinkExpiry
+	}
+
+	if limit := config.Projects.ShareLinks.MaxExpiry; limit > 0 && expiresAt.After(now.Add(limit)) {
+		return nil, "", repository.ErrShareLinkExpiry
+	}
+
+	token, err := utils.RandomToken(24)
+	if err != nil {
+		return nil, "", err
+	}
+
+	link := &model.ProjectShareLink{
+		ProjectID: projectID,
+		CreatorID: &creatorID,
+		Token:     utils.HashToken(token),
+		Scope:     createModel.Scope,
+		ExpiresAt: expiresAt,
+		MaxUses:   createModel.MaxUses,
+	}
+
+	if err := psuc.psr.Create(link); err != nil {
+		return nil, "", err
+	}
+
+	info, err := psuc.psr.Get(projectID, link.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return info, token, nil
+}
+
+func (psuc ProjectShareUseCase) GetByProject(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectShareLinkInfo], error) {
+	return psuc.psr.GetByProject(projectID, page, perPage)
+}
+
+// Resolves the token sent by a user, counting it as a use of the link the first time they send it
+func (psuc ProjectShareUseCase) Use(projectID, userID uint, token string) (*dto.ProjectShareLinkInfo, error) {
+	return psuc.psr.Use(projectID, userID, utils.HashToken(token))
+}
+
+func (psuc ProjectShareUseCase) Revoke(projectID, linkID uint) error {
+	return psuc.psr.Delete(projectID, linkID)
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type ProjectTransferUseCase struct {
+	ptr repository.ProjectTransferRepository
+}
+
+func NewProjectTransferUseCase() ProjectTransferUseCase {
+	return ProjectTransferUseCase{ptr: repository.NewProjectTransferRepository()}
+}
+
+// Shared by project and component transfers
+func transferExpiry() time.Time {
+	expiry := config.Projects.Transfers.Expiry
+	if expiry <= 0 {
+		expiry = 7 * 24 * time.Hour
+	}
+
+	return time.Now().Add(expiry)
+}
+
+func (ptuc ProjectTransferUseCase) Start(projectID, issuerID, recipientID uint, keepAccess bool) error {
+	return ptuc.ptr.Create(&model.ProjectTransfer{
+		ProjectID:   projectID,
+		IssuerID:    &issuerID,
+		RecipientID: recipientID,
+		KeepAccess:  keepAccess,
+		ExpiresAt:   transferExpiry(),
+	})
+}
+
+func (ptuc ProjectTransferUseCase) Get(projectID uint) (*dto.ProjectTransferInfo, error) {
+	return ptuc.ptr.Get(projectID)
+}
+
+func (ptuc ProjectTransferUseCase) GetByRecipient(recipientID uint, page, perPage int) (*dto.Pagination[dto.ProjectTransferInfo], error) {
+	return ptuc.ptr.GetByRecipient(recipientID, page, perPage)
+}
+
+func (ptuc ProjectTransferUseCase) Accept(recipientID, transferID uint) (*dto.ProjectTransferInfo, error) {
+	transfer, err := ptuc.ptr.GetForRecipient(recipientID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, ptuc.ptr.Accept(transfer)
+}
+
+func (ptuc ProjectTransferUseCase) Decline(recipientID, transferID uint) (*dto.ProjectTransferInfo, error) {
+	transfer, err := ptuc.ptr.GetForRecipient(recipientID, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, ptuc.ptr.Delete(transfer.ProjectID)
+}
+
+func (ptuc ProjectTransferUseCase) Cancel(projectID uint) error {
+	return ptuc.ptr.Delete(projectID)
+}
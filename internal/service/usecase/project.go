@@ -4,15 +4,22 @@ import (
 	"github.com/swibly/swibly-api/internal/model"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/jsonpatch"
 	"github.com/swibly/swibly-api/pkg/utils"
 )
 
 type ProjectUseCase struct {
 	pr repository.ProjectRepository
+	cr repository.ComponentRepository
 }
 
 func NewProjectUseCase() ProjectUseCase {
-	return ProjectUseCase{pr: repository.NewProjectRepository(repository.NewUserRepository())}
+	userRepo := repository.NewUserRepository()
+
+	return ProjectUseCase{
+		pr: repository.NewProjectRepository(userRepo),
+		cr: repository.NewComponentRepository(userRepo),
+	}
 }
 
 func (puc ProjectUseCase) Create(createModel *dto.ProjectCreation) (uint, error) {
@@ -20,7 +27,7 @@ func (puc ProjectUseCase) Create(createModel *dto.ProjectCreation) (uint, error)
 }
 
 func (puc ProjectUseCase) Fork(projectID, issuerID uint) (uint, error) {
-	content, err := puc.pr.GetContent(projectID)
+	content, _, err := puc.pr.GetContent(projectID)
 	if err != nil {
 		return 0, err
 	}
@@ -47,6 +54,59 @@ func (puc ProjectUseCase) Unlink(id uint) error {
 	return puc.pr.Unlink(id)
 }
 
+// Compares the fork with its upstream. Forks made before the base was recorded use their current content as the base.
+func (puc ProjectUseCase) DiffFork(projectID uint) (*dto.ProjectForkDiff, error) {
+	upstreamID, base, err := puc.pr.GetForkBase(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, _, err := puc.pr.GetContent(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	upstream, upstreamVersion, err := puc.pr.GetContent(upstreamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if base == nil {
+		base = content
+	}
+
+	return &dto.ProjectForkDiff{
+		Upstream:        upstreamID,
+		UpstreamVersion: upstreamVersion,
+		Diff:            jsonpatch.Diff(content, upstream),
+		Incoming:        jsonpatch.Diff(base, upstream),
+	}, nil
+}
+
+// Pulls the upstream changes made since the fork was made or last synced into the fork. Local changes
+// are kept, except where the upstream changed the same values, in which case the upstream wins.
+// Upstream changes that no longer apply, such as edits to something the fork removed, are skipped and returned.
+func (puc ProjectUseCase) SyncFork(projectID, authorID, expectedVersion uint) (uint, []jsonpatch.Operation, error) {
+	var skipped []jsonpatch.Operation
+
+	version, err := puc.pr.SyncFork(projectID, authorID, expectedVersion, func(content, base, upstream any) (any, error) {
+		if base == nil {
+			base = content
+		}
+
+		var merged any
+		merged, skipped = jsonpatch.ThreeWayMerge(base, content, upstream)
+
+		return merged, nil
+	})
+
+	return version, skipped, err
+}
+
+func (puc ProjectUseCase) GetForks(issuerID, projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error) {
+	return puc.pr.GetForks(issuerID, projectID, page, perPage)
+}
+
 func (puc ProjectUseCase) Update(projectID uint, updateModel *dto.ProjectUpdate) error {
 	return puc.pr.Update(projectID, updateModel)
 }
@@ -75,6 +135,10 @@ func (puc ProjectUseCase) GetByOwner(issuerID, userID uint, onlyPublic bool, pag
 	return puc.pr.GetByOwner(issuerID, userID, onlyPublic, page, perPage)
 }
 
+func (puc ProjectUseCase) GetByOrganization(issuerID, organizationID uint, onlyPublic bool, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error) {
+	return puc.pr.GetByOrganization(issuerID, organizationID, onlyPublic, page, perPage)
+}
+
 func (puc ProjectUseCase) GetPublic(issuerID uint, page, perPage int) (*dto.Pagination[dto.ProjectInfo], error) {
 	return puc.pr.GetPublic(issuerID, page, perPage)
 }
@@ -91,12 +155,71 @@ func (puc ProjectUseCase) Search(issuerID uint, search *dto.SearchProject, page,
 	return puc.pr.Search(issuerID, search, page, perPage)
 }
 
-func (puc ProjectUseCase) GetContent(projectID uint) (any, error) {
+// Returns the content of the project along with its version
+func (puc ProjectUseCase) GetContent(projectID uint) (any, uint, error) {
 	return puc.pr.GetContent(projectID)
 }
 
-func (puc ProjectUseCase) SaveContent(projectID uint, content any) error {
-	return puc.pr.SaveContent(projectID, content)
+// Saves the content if the project is still at expectedVersion (0 skips the check) and returns the new version
+func (puc ProjectUseCase) SaveContent(projectID, authorID, expectedVersion uint, content any) (uint, error) {
+	return puc.pr.SaveContent(projectID, authorID, expectedVersion, content)
+}
+
+// Applies a JSON Patch (RFC 6902) to the stored content
+func (puc ProjectUseCase) PatchContent(projectID, authorID, expectedVersion uint, ops []jsonpatch.Operation) (uint, error) {
+	return puc.pr.PatchContent(projectID, authorID, expectedVersion, func(content any) (any, error) {
+		return jsonpatch.Apply(content, ops)
+	})
+}
+
+// Applies a JSON Merge Patch (RFC 7396) to the stored content
+func (puc ProjectUseCase) MergeContent(projectID, authorID, expectedVersion uint, patch any) (uint, error) {
+	return puc.pr.PatchContent(projectID, authorID, expectedVersion, func(content any) (any, error) {
+		return jsonpatch.MergePatch(content, patch), nil
+	})
+}
+
+func (puc ProjectUseCase) GetRevisions(projectID uint, page, perPage int) (*dto.Pagination[dto.ProjectRevisionInfo], error) {
+	return puc.pr.GetRevisions(projectID, page, perPage)
+}
+
+func (puc ProjectUseCase) GetRevision(projectID, revisionID uint) (*dto.ProjectRevision, error) {
+	return puc.pr.GetRevision(projectID, revisionID)
+}
+
+// Compares two revisions of the project. When toID is 0, the revision is compared against the current content.
+func (puc ProjectUseCase) DiffRevisions(projectID, fromID, toID uint) ([]jsonpatch.Operation, error) {
+	from, err := puc.pr.GetRevision(projectID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	var to any
+
+	if toID == 0 {
+		if to, _, err = puc.pr.GetContent(projectID); err != nil {
+			return nil, err
+		}
+	} else {
+		revision, err := puc.pr.GetRevision(projectID, toID)
+		if err != nil {
+			return nil, err
+		}
+
+		to = revision.Content
+	}
+
+	return jsonpatch.Diff(from.Content, to), nil
+}
+
+// Restoring does not rewrite the history, the content of the revision is saved as a new one
+func (puc ProjectUseCase) RestoreRevision(projectID, revisionID, authorID, expectedVersion uint) (uint, error) {
+	revision, err := puc.pr.GetRevision(projectID, revisionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return puc.pr.SaveContent(projectID, authorID, expectedVersion, revision.Content)
 }
 
 func (puc ProjectUseCase) Favorite(projectID, userID uint) error {
@@ -107,8 +230,8 @@ func (puc ProjectUseCase) Unfavorite(projectID, userID uint) error {
 	return puc.pr.Unfavorite(projectID, userID)
 }
 
-func (puc ProjectUseCase) ClearContent(projectID uint) error {
-	return puc.pr.SaveContent(projectID, nil)
+func (puc ProjectUseCase) ClearContent(projectID, authorID, expectedVersion uint) (uint, error) {
+	return puc.pr.SaveContent(projectID, authorID, expectedVersion, nil)
 }
 
 func (puc ProjectUseCase) Trash(id uint) error {
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/utils"
+)
+
+type SessionUseCase struct {
+	sr repository.SessionRepository
+}
+
+func NewSessionUseCase() SessionUseCase {
+	return SessionUseCase{sr: repository.NewSessionRepository()}
+}
+
+func refreshTokenTTL() time.Duration {
+	if config.Security.RefreshTokenTTL <= 0 {
+		return 30 * 24 * time.Hour
+	}
+
+	return config.Security.RefreshTokenTTL
+}
+
+func (suc SessionUseCase) issue(userID, sessionID uint, refreshToken string, expiresAt time.Time) (*dto.SessionTokens, error) {
+	token, err := utils.GenerateJWT(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SessionTokens{Token: token, RefreshToken: refreshToken, ExpiresAt: expiresAt}, nil
+}
+
+func (suc SessionUseCase) Create(userID uint, metadata dto.SessionMetadata) (*dto.SessionTokens, error) {
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{
+		UserID:       userID,
+		RefreshToken: utils.HashToken(refreshToken),
+		Device:       metadata.Device,
+		IP:           metadata.IP,
+		UserAgent:    metadata.UserAgent,
+		ExpiresAt:    time.Now().Add(refreshTokenTTL()),
+		LastUsedAt:   time.Now(),
+	}
+
+	if err := suc.sr.Create(session); err != nil {
+		return nil, err
+	}
+
+	return suc.issue(userID, session.ID, refreshToken, session.ExpiresAt)
+}
+
+// Exchanges a refresh token for a new pair of tokens. The old refresh token stops working.
+func (suc SessionUseCase) Refresh(refreshToken string, metadata dto.SessionMetadata) (*dto.SessionTokens, error) {
+	oldHash := utils.HashToken(refreshToken)
+
+	session, err := suc.sr.GetByRefreshToken(oldHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if !session.IsActive() {
+		return nil, repository.ErrSessionRevoked
+	}
+
+	newToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(refreshTokenTTL())
+
+	if err := suc.sr.Rotate(session.ID, oldHash, utils.HashToken(newToken), expiresAt, metadata.IP); err != nil {
+		return nil, err
+	}
+
+	return suc.issue(session.UserID, session.ID, newToken, expiresAt)
+}
+
+func (suc SessionUseCase) IsActive(userID, sessionID uint) (bool, error) {
+	session, err := suc.sr.Get(sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	return session.UserID == userID && session.IsActive(), nil
+}
+
+func (suc SessionUseCase) GetByUser(userID, currentSessionID uint) ([]*dto.SessionInfo, error) {
+	sessions, err := suc.sr.GetActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []*dto.SessionInfo{}
+	for _, session := range sessions {
+		infos = append(infos, &dto.SessionInfo{
+			ID:         session.ID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Device:     session.Device,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return infos, nil
+}
+
+func (suc SessionUseCase) Revoke(userID, sessionID uint) error {
+	return suc.sr.Revoke(userID, sessionID)
+}
+
+func (suc SessionUseCase) RevokeAll(userID uint) error {
+	return suc.sr.RevokeAll(userID)
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer             = "Swibly"
+	totpRecoveryCodeAmount = 10
+)
+
+type TOTPUseCase struct {
+	tr repository.TOTPRepository
+}
+
+func NewTOTPUseCase() TOTPUseCase {
+	return TOTPUseCase{tr: repository.NewTOTPRepository()}
+}
+
+func (tuc TOTPUseCase) IsEnabled(userID uint) (bool, error) {
+	totp, err := tuc.tr.Get(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return totp.Enabled, nil
+}
+
+func (tuc TOTPUseCase) Enroll(user *dto.UserProfile) (*dto.TOTPEnrollment, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tuc.tr.Enroll(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &dto.TOTPEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Enables 2FA if the code matches the pending secret. Returns the recovery codes, which are only shown this once.
+func (tuc TOTPUseCase) Confirm(userID uint, code string) ([]string, error) {
+	totp, err := tuc.tr.Get(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrTOTPNotEnabled
+		}
+
+		return nil, err
+	}
+
+	if totp.Enabled {
+		return nil, repository.ErrTOTPAlreadyEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, repository.ErrTOTPInvalidCode
+	}
+
+	codes := make([]string, totpRecoveryCodeAmount)
+	hashes := make([]string, totpRecoveryCodeAmount)
+	for i := range codes {
+		code, err := utils.RandomToken(5)
+		if err != nil {
+			return nil, err
+		}
+
+		codes[i] = code
+		hashes[i] = utils.HashToken(code)
+	}
+
+	if err := tuc.tr.Enable(userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (tuc TOTPUseCase) Disable(userID uint) error {
+	return tuc.tr.Disable(userID)
+}
+
+// Accepts either a code from the authenticator app or one of the recovery codes
+func (tuc TOTPUseCase) Verify(userID uint, code string) error {
+	totp, err := tuc.tr.Get(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return repository.ErrTOTPNotEnabled
+		}
+
+		return err
+	}
+
+	if !totp.Enabled {
+		return repository.ErrTOTPNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+
+	if len(code) == utils.TOTPDigits {
+		if step, ok := utils.ValidateTOTP(totp.Secret, code, time.Now()); ok {
+			return tuc.tr.UseStep(userID, step)
+		}
+
+		return repository.ErrTOTPInvalidCode
+	}
+
+	return tuc.tr.UseRecoveryCode(userID, utils.HashToken(strings.ToLower(code)))
+}
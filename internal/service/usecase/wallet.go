@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type WalletUseCase struct {
+	wr repository.WalletRepository
+}
+
+func NewWalletUseCase() WalletUseCase {
+	return WalletUseCase{wr: repository.NewWalletRepository()}
+}
+
+func (wuc WalletUseCase) GetByUser(userID uint, page, perPage int) (*dto.Pagination[dto.ArkhoinTransactionInfo], error) {
+	return wuc.wr.GetByUser(userID, page, perPage)
+}
+
+func (wuc WalletUseCase) GetByOrganization(organizationID uint, page, perPage int) (*dto.Pagination[dto.ArkhoinTransactionInfo], error) {
+	return wuc.wr.GetByOrganization(organizationID, page, perPage)
+}
+
+func (wuc WalletUseCase) Reconcile() (*dto.WalletReconciliation, error) {
+	return wuc.wr.Reconcile()
+}
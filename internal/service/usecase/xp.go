@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service/repository"
+)
+
+type XPUseCase struct {
+	xr repository.XPRepository
+}
+
+func NewXPUseCase() XPUseCase {
+	return XPUseCase{xr: repository.NewXPRepository()}
+}
+
+// Rewards the user for an action, as configured in config/xp.yaml. The reference is what earned the XP,
+// the same reference is only rewarded once per rule.
+func (xuc XPUseCase) Award(userID uint, rule, reference string) (*dto.XPChange, error) {
+	r, ok := config.XP.Rules[rule]
+	if !ok || r.Amount == 0 {
+		return &dto.XPChange{UserID: userID}, nil
+	}
+
+	return xuc.xr.Award(userID, rule, reference, r.Amount, r.DailyCap, config.XP.DailyCap)
+}
+
+func (xuc XPUseCase) Level(xp uint64) dto.Level {
+	return dto.NewLevel(xp, config.XP.Levels.Thresholds, config.XP.Levels.Step)
+}
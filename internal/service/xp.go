@@ -0,0 +1,37 @@
+package service
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/translations"
+)
+
+// Rewards the user for an action and tells them when they reach a new level. Failures are only logged, the
+// action itself already went through.
+func AwardXP(dict translations.Translation, userID uint, rule, reference string) {
+	change, err := XP.Award(userID, rule, reference)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	NotifyLevelUps(dict, *change)
+}
+
+func NotifyLevelUps(dict translations.Translation, changes ...dto.XPChange) {
+	for _, change := range changes {
+		level := XP.Level(change.After).Level
+		if level <= XP.Level(change.Before).Level {
+			continue
+		}
+
+		CreateNotification(dto.CreateNotification{
+			Title:   dict.CategoryLevel,
+			Message: fmt.Sprintf(dict.NotificationLevelUp, level),
+			Type:    notification.Information,
+		}, change.UserID)
+	}
+}
@@ -0,0 +1,199 @@
+package archive
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+)
+
+// Version of the layout below, bumped whenever archives written by older versions cannot be read as they are
+const FormatVersion = 1
+
+// Files inside the zip
+const (
+	ManifestFile = "manifest.json"
+	ContentFile  = "content.json"
+	BannerFile   = "banner.webp"
+)
+
+// Archives bigger than this once extracted are rejected, so a small zip cannot expand into something huge
+const maxExtractedSize = 64 * 1024 * 1024
+
+var (
+	ErrInvalidArchive    = errors.New("invalid project archive")
+	ErrUnsupportedFormat = errors.New("unsupported project archive format")
+	ErrArchiveTooLarge   = errors.New("project archive is too large")
+)
+
+type Metadata struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	Budget int `json:"budget"`
+}
+
+// A component used by the content. Name and owner are kept for people reading the archive,
+// only the ID is used when importing it.
+type ComponentReference struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name,omitempty"`
+	OwnerUsername string `json:"owner_username,omitempty"`
+}
+
+type Manifest struct {
+	Format     int       `json:"format"`
+	ExportedAt time.Time `json:"exported_at"`
+
+	Project Metadata `json:"project"`
+
+	// Empty when the project has no banner
+	Banner string `json:"banner,omitempty"`
+
+	Components []ComponentReference `json:"components"`
+}
+
+type Archive struct {
+	Manifest Manifest
+	Content  any
+	Banner   []byte
+}
+
+func (a *Archive) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	manifest := a.Manifest
+	manifest.Format = FormatVersion
+	manifest.Banner = ""
+	if len(a.Banner) > 0 {
+		manifest.Banner = BannerFile
+	}
+
+	if manifest.Components == nil {
+		manifest.Components = []ComponentReference{}
+	}
+
+	if err := writeJSON(zw, ManifestFile, manifest); err != nil {
+		return err
+	}
+
+	if err := writeJSON(zw, ContentFile, a.Content); err != nil {
+		return err
+	}
+
+	if len(a.Banner) > 0 {
+		f, err := zw.Create(BannerFile)
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.Write(a.Banner); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeJSON(zw *zip.Writer, name string, value any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(value)
+}
+
+// Reads an archive written by Write
+func Read(data []byte) (*Archive, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	total := uint64(0)
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		files[path.Clean(f.Name)] = f
+	}
+
+	if total > maxExtractedSize {
+		return nil, ErrArchiveTooLarge
+	}
+
+	a := &Archive{}
+
+	if err := readJSON(files, ManifestFile, &a.Manifest); err != nil {
+		return nil, err
+	}
+
+	if a.Manifest.Format < 1 || a.Manifest.Format > FormatVersion {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if err := readJSON(files, ContentFile, &a.Content); err != nil {
+		return nil, err
+	}
+
+	if a.Manifest.Banner != "" {
+		f, ok := files[path.Clean(a.Manifest.Banner)]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, a.Manifest.Banner)
+		}
+
+		if a.Banner, err = readFile(f); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+func readJSON(files map[string]*zip.File, name string, value any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%w: missing %s", ErrInvalidArchive, name)
+	}
+
+	data, err := readFile(f)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidArchive, name, err)
+	}
+
+	return nil
+}
+
+func readFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer rc.Close()
+
+	// The declared size can lie, never read past the limit
+	data, err := io.ReadAll(io.LimitReader(rc, maxExtractedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+
+	if len(data) > maxExtractedSize {
+		return nil, ErrArchiveTooLarge
+	}
+
+	return data, nil
+}
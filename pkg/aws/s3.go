@@ -3,6 +3,7 @@ package aws
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"io"
@@ -29,7 +30,7 @@ var (
 	ErrFileTooLarge        = fmt.Errorf("file too large")
 )
 
-func (svc *AWSService) UploadFile(key string, file multipart.File) (string, error) {
+func (svc *AWSService) UploadFile(key string, file io.Reader) (string, error) {
 	newKey := fmt.Sprintf("%s/%s-%d.webp", config.Router.Environment, key, time.Now().Unix())
 
   log.Printf("Saving file to: %s", newKey)
@@ -43,7 +44,7 @@ func (svc *AWSService) UploadFile(key string, file multipart.File) (string, erro
 	imgData := bytes.NewReader(buf.Bytes())
 	img, _, err := image.Decode(imgData)
 	if err != nil {
-		return "", fmt.Errorf("unable to decode image: %v", err)
+		return "", fmt.Errorf("%w: %v", ErrUnableToDecode, err)
 	}
 
 	imgData.Seek(0, io.SeekStart)
@@ -55,7 +56,7 @@ func (svc *AWSService) UploadFile(key string, file multipart.File) (string, erro
 		Lossless: true,
 	})
 	if err != nil {
-		return "", fmt.Errorf("unable to encode image to WebP: %v", err)
+		return "", fmt.Errorf("%w: %v", ErrUnableToEncode, err)
 	}
 
 	_, err = svc.s3.PutObject(context.TODO(), &s3.PutObjectInput{
@@ -136,6 +137,47 @@ func UploadUserImage(userID uint, file *multipart.FileHeader) (string, error) {
 	return url, nil
 }
 
+// Downloads a file uploaded by UploadFile, `key` being the same path used to delete it
+func (svc *AWSService) DownloadFile(key string) ([]byte, error) {
+	newKey := fmt.Sprintf("%s/%s", config.Router.Environment, key)
+
+	out, err := svc.s3.GetObject(context.TODO(), &s3.GetObjectInput{
+		Bucket: aws.String(config.S3.Bucket),
+		Key:    aws.String(newKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to download file from S3: %v", err)
+	}
+	defer out.Body.Close()
+
+	return io.ReadAll(out.Body)
+}
+
+// Same as UploadProjectImage, for images that are already in memory
+func UploadProjectImageData(projectID uint, data []byte) (string, error) {
+	const maxFileSize = 5 * 1024 * 1024
+
+	if len(data) > maxFileSize {
+		return "", ErrFileTooLarge
+	}
+
+	outputPath := fmt.Sprintf("projects/%d", projectID)
+
+	// Images that cannot be read are the fault of the client, unlike failed uploads
+	url, err := AWS.UploadFile(outputPath, bytes.NewReader(data))
+	if errors.Is(err, ErrUnableToDecode) || errors.Is(err, ErrUnableToEncode) {
+		return "", err
+	} else if err != nil {
+		return "", ErrUnableToUploadFile
+	}
+
+	return url, nil
+}
+
+func DownloadProjectImage(filename string) ([]byte, error) {
+	return AWS.DownloadFile(fmt.Sprintf("projects/%s", filepath.Base(filename)))
+}
+
 func DeleteProjectImage(filename string) error {
 	return AWS.DeleteFile(fmt.Sprintf("projects/%s", filepath.Base(filename)))
 }
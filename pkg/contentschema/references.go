@@ -0,0 +1,45 @@
+package contentschema
+
+import (
+	"math"
+	"sort"
+)
+
+// Objects that place a component in a document carry its ID in this field
+const ComponentReferenceField = "component_id"
+
+// The IDs of every component placed anywhere in the document, sorted and without repetitions
+func ComponentReferences(doc any) []uint {
+	found := map[uint]bool{}
+	collectReferences(doc, found)
+
+	ids := make([]uint, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func collectReferences(value any, found map[uint]bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if key == ComponentReferenceField {
+				if id, ok := child.(float64); ok && id > 0 && id == math.Trunc(id) {
+					found[uint(id)] = true
+				}
+
+				continue
+			}
+
+			collectReferences(child, found)
+		}
+	case []any:
+		for _, child := range v {
+			collectReferences(child, found)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package contentschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/swibly/swibly-api/pkg/jsonpatch"
+)
+
+type Kind string
+
+const (
+	Project   Kind = "project"
+	Component Kind = "component"
+)
+
+var kinds = []Kind{Project, Component}
+
+// Every document carries the version of the schema it was written for
+const VersionField = "schema_version"
+
+// Documents with more errors than this only report the first ones
+const maxErrors = 20
+
+// A subset of JSON Schema, enough to describe the content documents. Schema files are regular
+// JSON Schema documents, keywords that are not listed here are ignored.
+type Schema struct {
+	Type string `json:"type,omitempty"`
+
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+
+	Items    *Schema `json:"items,omitempty"`
+	MinItems *int    `json:"minItems,omitempty"`
+	MaxItems *int    `json:"maxItems,omitempty"`
+
+	Enum      []any    `json:"enum,omitempty"`
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+}
+
+// A problem found in a document. `Path` is a JSON pointer to the offending value and `Rule`
+// the schema keyword it breaks, with `Param` being the keyword's value.
+type ValidationError struct {
+	Path  string `json:"path"`
+	Rule  string `json:"rule"`
+	Param any    `json:"param,omitempty"`
+}
+
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = fmt.Sprintf("%s: %s %v", err.Path, err.Rule, err.Param)
+	}
+
+	return "invalid content: " + strings.Join(messages, ", ")
+}
+
+var schemas = map[Kind]map[int]*Schema{}
+
+// Reads the schemas from config/schemas/<kind>/<version>.json
+func Init() {
+	for _, kind := range kinds {
+		files, err := filepath.Glob(filepath.Join("config", "schemas", string(kind), "*.json"))
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
+
+		for _, file := range files {
+			version, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(file), ".json"))
+			if err != nil {
+				log.Fatalf("error: schema file name must be its version: %s", file)
+			}
+
+			data, err := os.ReadFile(file)
+			if err != nil {
+				log.Fatalf("error: %v", err)
+			}
+
+			var schema Schema
+			if err := json.Unmarshal(data, &schema); err != nil {
+				log.Fatalf("error: %s: %v", file, err)
+			}
+
+			Register(kind, version, &schema)
+		}
+	}
+
+	log.Print("Loaded content schemas")
+}
+
+func Register(kind Kind, version int, schema *Schema) {
+	if schemas[kind] == nil {
+		schemas[kind] = map[int]*Schema{}
+	}
+
+	schemas[kind][version] = schema
+}
+
+// The newest registered version for the kind, 0 when it has no schemas
+func Current(kind Kind) int {
+	current := 0
+	for version := range schemas[kind] {
+		current = max(current, version)
+	}
+
+	return current
+}
+
+// The document used when there is no content, such as new or cleared projects.
+// It is an empty object taken through every upgrade hook.
+func Empty(kind Kind) any {
+	doc, err := Upgrade(kind, map[string]any{})
+	if err != nil {
+		return map[string]any{VersionField: float64(Current(kind))}
+	}
+
+	return doc
+}
+
+// Upgrades the document to the current version and checks it against the current schema.
+// The result is what should be stored.
+func Normalize(kind Kind, doc any) (any, error) {
+	doc, err := Upgrade(kind, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Validate(kind, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// Checks the document against the schema of the version it declares
+func Validate(kind Kind, doc any) error {
+	if len(schemas[kind]) == 0 {
+		return nil
+	}
+
+	object, ok := doc.(map[string]any)
+	if !ok {
+		return ValidationErrors{{Path: "", Rule: "type", Param: "object"}}
+	}
+
+	version, err := versionOf(object)
+	if err != nil {
+		return err
+	}
+
+	schema, ok := schemas[kind][version]
+	if !ok {
+		return ValidationErrors{{Path: "/" + VersionField, Rule: "enum", Param: Current(kind)}}
+	}
+
+	errs := ValidationErrors{}
+	schema.validate("", doc, &errs)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func versionOf(object map[string]any) (int, error) {
+	value, ok := object[VersionField]
+	if !ok {
+		return 0, nil
+	}
+
+	version, ok := value.(float64)
+	if !ok || version != math.Trunc(version) || version < 0 {
+		return 0, ValidationErrors{{Path: "/" + VersionField, Rule: "type", Param: "integer"}}
+	}
+
+	return int(version), nil
+}
+
+func (s *Schema) validate(path string, value any, errs *ValidationErrors) {
+	if len(*errs) >= maxErrors {
+		return
+	}
+
+	report := func(rule string, param any) {
+		if len(*errs) < maxErrors {
+			*errs = append(*errs, ValidationError{Path: path, Rule: rule, Param: param})
+		}
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		report("type", s.Type)
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if reflect.DeepEqual(value, allowed) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			report("enum", s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				*errs = append(*errs, ValidationError{Path: path + "/" + jsonpatch.EscapeToken(key), Rule: "required"})
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		// Sorted so the same document always reports the same errors
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := v[key]
+			childPath := path + "/" + jsonpatch.EscapeToken(key)
+
+			if property, ok := s.Properties[key]; ok {
+				property.validate(childPath, child, errs)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*errs = append(*errs, ValidationError{Path: childPath, Rule: "additionalProperties", Param: false})
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			report("minItems", *s.MinItems)
+		}
+
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			report("maxItems", *s.MaxItems)
+		}
+
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(path+"/"+strconv.Itoa(i), item, errs)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+
+		if s.MinLength != nil && length < *s.MinLength {
+			report("minLength", *s.MinLength)
+		}
+
+		if s.MaxLength != nil && length > *s.MaxLength {
+			report("maxLength", *s.MaxLength)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			report("minimum", *s.Minimum)
+		}
+
+		if s.Maximum != nil && v > *s.Maximum {
+			report("maximum", *s.Maximum)
+		}
+	}
+
+	if len(*errs) > maxErrors {
+		*errs = (*errs)[:maxErrors]
+	}
+}
+
+func hasType(value any, expected string) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		return expected == "object"
+	case []any:
+		return expected == "array"
+	case string:
+		return expected == "string"
+	case bool:
+		return expected == "boolean"
+	case nil:
+		return expected == "null"
+	case float64:
+		return expected == "number" || (expected == "integer" && v == math.Trunc(v))
+	}
+
+	return false
+}
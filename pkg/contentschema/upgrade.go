@@ -0,0 +1,78 @@
+package contentschema
+
+import "fmt"
+
+// Turns a document of one version into the next one. It receives a copy of the top level object,
+// nested values must be replaced instead of modified in place.
+type UpgradeFunc func(doc map[string]any) (map[string]any, error)
+
+var upgrades = map[Kind]map[int]UpgradeFunc{}
+
+func init() {
+	// Documents stored before the content was versioned are adopted as version 1 as they are
+	for _, kind := range kinds {
+		RegisterUpgrade(kind, 0, func(doc map[string]any) (map[string]any, error) {
+			return doc, nil
+		})
+	}
+}
+
+// Registers the hook that upgrades documents from version `from` to `from + 1`
+func RegisterUpgrade(kind Kind, from int, upgrade UpgradeFunc) {
+	if upgrades[kind] == nil {
+		upgrades[kind] = map[int]UpgradeFunc{}
+	}
+
+	upgrades[kind][from] = upgrade
+}
+
+// Runs the upgrade hooks until the document reaches the current version. Missing documents are
+// upgraded from an empty object. Documents that are already current are returned as they are.
+func Upgrade(kind Kind, doc any) (any, error) {
+	current := Current(kind)
+	if current == 0 {
+		return doc, nil
+	}
+
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	object, ok := doc.(map[string]any)
+	if !ok {
+		return nil, ValidationErrors{{Path: "", Rule: "type", Param: "object"}}
+	}
+
+	version, err := versionOf(object)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > current {
+		return nil, ValidationErrors{{Path: "/" + VersionField, Rule: "maximum", Param: current}}
+	}
+
+	if version == current {
+		return object, nil
+	}
+
+	upgraded := make(map[string]any, len(object))
+	for key, value := range object {
+		upgraded[key] = value
+	}
+
+	for ; version < current; version++ {
+		upgrade, ok := upgrades[kind][version]
+		if !ok {
+			return nil, fmt.Errorf("no %s content upgrade registered from version %d", kind, version)
+		}
+
+		if upgraded, err = upgrade(upgraded); err != nil {
+			return nil, err
+		}
+
+		upgraded[VersionField] = float64(version + 1)
+	}
+
+	return upgraded, nil
+}
@@ -7,11 +7,12 @@ import (
 	"reflect"
 	"strings"
 
-	"github.com/google/uuid"
 	"github.com/swibly/swibly-api/config"
 	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/pkg/language"
 	"github.com/swibly/swibly-api/pkg/notification"
+	"github.com/swibly/swibly-api/pkg/utils"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -95,17 +96,39 @@ func Load() {
 		&model.Permission{},
 		&model.UserPermission{},
 		&model.PasswordResetKey{},
+		&model.EmailVerificationKey{},
+		&model.Session{},
+		&model.UserTOTP{},
+		&model.TOTPRecoveryCode{},
+
+		&model.Organization{},
+		&model.OrganizationMember{},
 
 		&model.Project{},
 		&model.ProjectOwner{},
 		&model.ProjectPublication{},
 		&model.ProjectUserFavorite{},
 		&model.ProjectUserPermission{},
+		&model.ProjectRole{},
+		&model.ProjectRevision{},
+		&model.ProjectShareLink{},
+		&model.ProjectShareLinkRedemption{},
+		&model.ProjectInvite{},
+		&model.ProjectTransfer{},
 
 		&model.Component{},
 		&model.ComponentOwner{},
 		&model.ComponentHolder{},
 		&model.ComponentPublication{},
+		&model.ComponentTransfer{},
+
+		&model.ArkhoinTransaction{},
+		&model.Grant{},
+		&model.Airdrop{},
+		&model.AirdropRecipient{},
+		&model.XPEvent{},
+		&model.Achievement{},
+		&model.UserAchievement{},
 
 		&model.Notification{},
 		&model.NotificationUser{},
@@ -131,14 +154,117 @@ func Load() {
 		log.Println(err)
 	}
 
+	if len(config.Achievements.Definitions) > 0 {
+		var achievements []model.Achievement
+		for _, definition := range config.Achievements.Definitions {
+			achievements = append(achievements, model.Achievement{
+				Key:           definition.Key,
+				Name:          definition.Name,
+				Description:   definition.Description,
+				Metric:        definition.Metric,
+				Threshold:     definition.Threshold,
+				ArkhoinReward: definition.Arkhoins,
+				XPReward:      definition.XP,
+			})
+		}
+
+		if err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"updated_at", "name", "description", "metric", "threshold", "arkhoin_reward", "xp_reward"}),
+		}).Create(&achievements).Error; err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Wallets from before the ledger existed start their history with a grant of their balance at that time
+	if err := db.Exec(`
+		INSERT INTO arkhoin_transactions (created_at, type, user_id, amount, balance_after, reason)
+		SELECT NOW(), ?, u.id, u.arkhoin, u.arkhoin, ?
+		FROM users u
+		WHERE NOT EXISTS (SELECT 1 FROM arkhoin_transactions t WHERE t.user_id = u.id AND t.organization_id IS NULL)
+	`, dto.ArkhoinGrant, dto.ArkhoinOpeningBalance).Error; err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.Exec(`
+		INSERT INTO arkhoin_transactions (created_at, type, organization_id, amount, balance_after, reason)
+		SELECT NOW(), ?, o.id, o.arkhoin, o.arkhoin, ?
+		FROM organizations o
+		WHERE o.arkhoin > 0 AND NOT EXISTS (SELECT 1 FROM arkhoin_transactions t WHERE t.organization_id = o.id)
+	`, dto.ArkhoinGrant, dto.ArkhoinOpeningBalance).Error; err != nil {
+		log.Fatal(err)
+	}
+
+	// Grants from before usernames were kept on them
+	if err := db.Exec(`
+		UPDATE grants g
+		SET username = u.username
+		FROM users u
+		WHERE u.id = g.user_id AND g.username = ''
+	`).Error; err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.Exec(`
+		UPDATE grants g
+		SET issuer_username = u.username
+		FROM users u
+		WHERE u.id = g.issuer_id AND COALESCE(g.issuer_username, '') = ''
+	`).Error; err != nil {
+		log.Fatal(err)
+	}
+
 	log.Print("DONE: Loaded migrations")
 
 	log.Print("WAIT: Validating API keys")
 
+	// Keys created before hashing was introduced have no prefix and store the secret as is.
+	// Hashing the stored value keeps those secrets working, and the prefix is taken from the start of the secret
+	// so that owners can still tell which key is theirs. It grows until it no longer matches another key.
+	var legacyKeys []model.APIKey
+	if err := db.Where("prefix IS NULL OR prefix = ''").Find(&legacyKeys).Error; err != nil {
+		log.Fatal(err)
+	}
+
+	for _, legacy := range legacyKeys {
+		prefix := ""
+		for size := 8; prefix == "" && size <= len(legacy.Key); size++ {
+			var taken int64
+			if err := db.Model(&model.APIKey{}).Where("prefix = ?", legacy.Key[:size]).Count(&taken).Error; err != nil {
+				log.Fatal(err)
+			}
+
+			if taken == 0 {
+				prefix = legacy.Key[:size]
+			}
+		}
+
+		if prefix == "" {
+			log.Fatalf("Couldn't find a free prefix for the legacy API key %d", legacy.ID)
+		}
+
+		if err := db.Model(&model.APIKey{}).Where("id = ?", legacy.ID).Updates(map[string]any{
+			"key":    utils.HashToken(legacy.Key),
+			"prefix": prefix,
+		}).Error; err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(legacyKeys) > 0 {
+		log.Printf("Hashed %d legacy API keys", len(legacyKeys))
+	}
+
 	var apikey model.APIKey
 	if err := db.First(&apikey).Error; err != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+		secret, prefix, err := utils.GenerateAPIKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		key := &model.APIKey{
-			Key:                uuid.New().String(),
+			Key:                utils.HashToken(secret),
+			Prefix:             prefix,
 			EnabledKeyManage:   1,
 			EnabledAuth:        1,
 			EnabledSearch:      1,
@@ -147,7 +273,7 @@ func Load() {
 			EnabledProjects:    1,
 		}
 
-		log.Print("Created API key for the first time: ", key.Key)
+		log.Print("Created API key for the first time: ", secret)
 		db.Create(&key)
 	}
 
@@ -0,0 +1,404 @@
+package jsonpatch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A single RFC 6902 operation
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value"`
+}
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Escapes a key to be used as a JSON pointer token (RFC 6901)
+func EscapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func UnescapeToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// Returns the operations that turn `from` into `to`. Both values are expected to be
+// decoded with encoding/json (maps, slices, float64, string, bool and nil).
+func Diff(from, to any) []Operation {
+	return diff("", from, to, []Operation{})
+}
+
+func diff(path string, from, to any, ops []Operation) []Operation {
+	switch fromValue := from.(type) {
+	case map[string]any:
+		toValue, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+
+		for _, key := range sortedKeys(fromValue) {
+			if _, exists := toValue[key]; !exists {
+				ops = append(ops, Operation{Op: OpRemove, Path: path + "/" + EscapeToken(key)})
+			}
+		}
+
+		for _, key := range sortedKeys(toValue) {
+			if previous, exists := fromValue[key]; exists {
+				ops = diff(path+"/"+EscapeToken(key), previous, toValue[key], ops)
+			} else {
+				ops = append(ops, Operation{Op: OpAdd, Path: path + "/" + EscapeToken(key), Value: toValue[key]})
+			}
+		}
+
+		return ops
+	case []any:
+		toValue, ok := to.([]any)
+		if !ok {
+			break
+		}
+
+		common := min(len(fromValue), len(toValue))
+
+		for i := 0; i < common; i++ {
+			ops = diff(path+"/"+strconv.Itoa(i), fromValue[i], toValue[i], ops)
+		}
+
+		// Removed from the end so the indexes of the remaining elements don't shift
+		for i := len(fromValue) - 1; i >= common; i-- {
+			ops = append(ops, Operation{Op: OpRemove, Path: path + "/" + strconv.Itoa(i)})
+		}
+
+		for i := common; i < len(toValue); i++ {
+			ops = append(ops, Operation{Op: OpAdd, Path: path + "/-", Value: toValue[i]})
+		}
+
+		return ops
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		ops = append(ops, Operation{Op: OpReplace, Path: path, Value: to})
+	}
+
+	return ops
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+var (
+	ErrInvalidOperation = errors.New("invalid patch operation")
+	ErrInvalidPath      = errors.New("invalid JSON pointer")
+	ErrPathNotFound     = errors.New("path does not exist in the document")
+	ErrTestFailed       = errors.New("test operation failed")
+)
+
+// Returned by Apply, it tells which operation of the patch could not be applied
+type Error struct {
+	Index     int
+	Operation Operation
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %v", e.Index, e.Operation.Op, e.Operation.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Applies the operations to the document and returns the result. The patch is atomic: the given
+// document is never modified, and if any operation fails the whole patch is rejected.
+func Apply(doc any, ops []Operation) (any, error) {
+	doc = deepCopy(doc)
+
+	for i, op := range ops {
+		var err error
+
+		if doc, err = apply(doc, op); err != nil {
+			return nil, &Error{Index: i, Operation: op, Err: err}
+		}
+	}
+
+	return doc, nil
+}
+
+func apply(doc any, op Operation) (any, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return add(doc, path, deepCopy(op.Value))
+	case OpRemove:
+		return remove(doc, path)
+	case OpReplace:
+		return replace(doc, path, deepCopy(op.Value))
+	case OpMove, OpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == OpCopy {
+			return add(doc, path, deepCopy(value))
+		}
+
+		// A value cannot be moved into one of its own children
+		if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return nil, ErrInvalidPath
+		}
+
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+	case OpTest:
+		value, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(value, op.Value) {
+			return nil, ErrTestFailed
+		}
+
+		return doc, nil
+	}
+
+	return nil, ErrInvalidOperation
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, ErrInvalidPath
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = UnescapeToken(token)
+	}
+
+	return tokens, nil
+}
+
+// Parses an array index token. `-` (the position after the last element) is only valid when appending.
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return length, nil
+	}
+
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, ErrInvalidPath
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, ErrInvalidPath
+	}
+
+	if index > length || (index == length && !appending) {
+		return 0, ErrPathNotFound
+	}
+
+	return index, nil
+}
+
+func get(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+
+			doc = value
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = node[index]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+
+	return doc, nil
+}
+
+// Walks down to the parent of the last token of the path and lets `change` replace it.
+// Containers are rebuilt on the way back up, since slices may be reallocated.
+func update(doc any, path []string, change func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return change(doc, path[0])
+	}
+
+	switch node := doc.(type) {
+	case map[string]any:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, ErrPathNotFound
+		}
+
+		updated, err := update(child, path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+
+		node[path[0]] = updated
+		return node, nil
+	case []any:
+		index, err := arrayIndex(path[0], len(node), false)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := update(node[index], path[1:], change)
+		if err != nil {
+			return nil, err
+		}
+
+		node[index] = updated
+		return node, nil
+	}
+
+	return nil, ErrPathNotFound
+}
+
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			node[token] = value
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+
+		return nil, ErrPathNotFound
+	})
+}
+
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, ErrInvalidPath
+	}
+
+	return update(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, ErrPathNotFound
+			}
+
+			delete(node, token)
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(node[:index], node[index+1:]...), nil
+		}
+
+		return nil, ErrPathNotFound
+	})
+}
+
+func replace(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(parent any, token string) (any, error) {
+		switch node := parent.(type) {
+		case map[string]any:
+			if _, ok := node[token]; !ok {
+				return nil, ErrPathNotFound
+			}
+
+			node[token] = value
+			return node, nil
+		case []any:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+
+			node[index] = value
+			return node, nil
+		}
+
+		return nil, ErrPathNotFound
+	})
+}
+
+func deepCopy(value any) any {
+	switch node := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(node))
+		for key, child := range node {
+			copied[key] = deepCopy(child)
+		}
+
+		return copied
+	case []any:
+		copied := make([]any, len(node))
+		for i, child := range node {
+			copied[i] = deepCopy(child)
+		}
+
+		return copied
+	}
+
+	return value
+}
@@ -0,0 +1,50 @@
+package jsonpatch
+
+// Applies a JSON Merge Patch (RFC 7396): objects are merged recursively, null removes a member
+// and anything else replaces the target. The given document is never modified.
+func MergePatch(doc, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return deepCopy(patch)
+	}
+
+	docObject, ok := doc.(map[string]any)
+	if !ok {
+		docObject = map[string]any{}
+	}
+
+	merged := make(map[string]any, len(docObject))
+	for key, value := range docObject {
+		merged[key] = value
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+
+		merged[key] = MergePatch(merged[key], value)
+	}
+
+	return merged
+}
+
+// Brings the changes made from `base` to `theirs` into `ours`, one operation at a time. Where both sides
+// changed the same value, theirs wins. Operations that no longer apply to ours, such as changes to something
+// ours removed, are skipped and returned. The given documents are never modified.
+func ThreeWayMerge(base, ours, theirs any) (any, []Operation) {
+	skipped := []Operation{}
+
+	for _, op := range Diff(base, theirs) {
+		patched, err := Apply(ours, []Operation{op})
+		if err != nil {
+			skipped = append(skipped, op)
+			continue
+		}
+
+		ours = patched
+	}
+
+	return ours, skipped
+}
@@ -0,0 +1,72 @@
+package live
+
+import (
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/swibly/swibly-api/translations"
+)
+
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = (pongWait * 9) / 10
+
+	sendBuffer = 64
+)
+
+type client struct {
+	conn *websocket.Conn
+	send chan Message
+
+	dict     translations.Translation
+	presence Presence
+
+	// Whether the user is still allowed to edit the project, as sessions and permissions can change while connected
+	authorize func() bool
+}
+
+// Writes the queued messages and keeps the connection alive with pings.
+// It owns every write to the connection, as gorilla/websocket allows a single writer.
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+
+			if err := c.conn.WriteJSON(message); err != nil {
+				log.Print(err)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Queues a message without blocking the room. Clients that can't keep up are disconnected
+// and are expected to reconnect and sync.
+func (c *client) push(message Message) {
+	select {
+	case c.send <- message:
+	default:
+		c.conn.Close()
+	}
+}
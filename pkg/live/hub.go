@@ -0,0 +1,241 @@
+package live
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/translations"
+)
+
+// Where the documents are loaded from and persisted to. Satisfied by usecase.ProjectUseCase.
+type Storage interface {
+	GetContent(projectID uint) (any, uint, error)
+	SaveContent(projectID, authorID, expectedVersion uint, content any) (uint, error)
+}
+
+// Keeps a room for every project that has someone connected. Everything lives in memory,
+// so all the editors of a project must be connected to the same instance.
+type Hub struct {
+	storage Storage
+
+	mu    sync.Mutex
+	rooms map[uint]*room
+
+	lastClientID atomic.Uint64
+}
+
+var Default *Hub
+
+func Init(storage Storage) {
+	Default = NewHub(storage)
+
+	log.Print("Loaded live editing hub")
+}
+
+func NewHub(storage Storage) *Hub {
+	return &Hub{storage: storage, rooms: make(map[uint]*room)}
+}
+
+// Runs the connection until it is closed. The user is expected to be allowed to edit the project when it opens,
+// authorize is called again before the document is persisted and the client is disconnected once it fails.
+func (h *Hub) Serve(projectID uint, user *dto.UserProfile, dict translations.Translation, conn *websocket.Conn, authorize func() bool) {
+	c := &client{
+		conn:      conn,
+		send:      make(chan Message, sendBuffer),
+		dict:      dict,
+		authorize: authorize,
+		presence: Presence{
+			Client:         h.lastClientID.Add(1),
+			ID:             user.ID,
+			FirstName:      user.FirstName,
+			LastName:       user.LastName,
+			Username:       user.Username,
+			ProfilePicture: user.ProfilePicture,
+		},
+	}
+
+	r, err := h.join(projectID, c)
+	if err != nil {
+		log.Print(err)
+		conn.WriteJSON(Message{Type: MessageError, Error: dict.InternalServerError})
+		conn.Close()
+		return
+	}
+
+	go c.writePump()
+
+	defer h.leave(r, c)
+
+	if config.Projects.Live.MaxMessageSize > 0 {
+		conn.SetReadLimit(config.Projects.Live.MaxMessageSize)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Print(err)
+			}
+
+			return
+		}
+
+		var message Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			r.reply(c, Message{Type: MessageError, Error: dict.InvalidBody})
+			continue
+		}
+
+		r.handle(c, message)
+	}
+}
+
+// Drops the unsaved live changes of the project, if it has a room, and sends everyone the stored content.
+// Called after the content is saved outside the live session.
+func (h *Hub) Reload(projectID uint) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	r, ok := h.rooms[projectID]
+	h.mu.Unlock()
+
+	if ok {
+		r.reload()
+	}
+}
+
+// Disconnects the clients of the project that are no longer allowed to edit it.
+// Called after the permissions on the project change.
+func (h *Hub) Recheck(projectID uint) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	r, ok := h.rooms[projectID]
+	h.mu.Unlock()
+
+	if ok {
+		r.recheck()
+	}
+}
+
+// Same as Recheck for every room, used when a change can reach many projects at once, like organization roles
+func (h *Hub) RecheckAll() {
+	if h == nil {
+		return
+	}
+
+	for _, r := range h.allRooms() {
+		r.recheck()
+	}
+}
+
+// Saves every room, used on shutdown
+func (h *Hub) PersistAll() {
+	if h == nil {
+		return
+	}
+
+	for _, r := range h.allRooms() {
+		r.persist()
+	}
+}
+
+func (h *Hub) allRooms() []*room {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rooms := make([]*room, 0, len(h.rooms))
+	for _, r := range h.rooms {
+		rooms = append(rooms, r)
+	}
+
+	return rooms
+}
+
+func (h *Hub) join(projectID uint, c *client) (*room, error) {
+	for {
+		h.mu.Lock()
+
+		if r, ok := h.rooms[projectID]; ok {
+			// The last client just left and the document is being saved, wait before loading it again
+			if r.closing {
+				h.mu.Unlock()
+				<-r.done
+				continue
+			}
+
+			r.add(c)
+			h.mu.Unlock()
+
+			return r, nil
+		}
+
+		h.mu.Unlock()
+
+		content, version, err := h.storage.GetContent(projectID)
+		if err != nil {
+			return nil, err
+		}
+
+		h.mu.Lock()
+
+		// Someone else created the room while the content was loading
+		if _, ok := h.rooms[projectID]; ok {
+			h.mu.Unlock()
+			continue
+		}
+
+		r := newRoom(projectID, h.storage, content, version)
+		h.rooms[projectID] = r
+		r.add(c)
+
+		h.mu.Unlock()
+
+		interval := config.Projects.Live.PersistInterval
+		if interval <= 0 {
+			interval = 10 * time.Second
+		}
+
+		go r.persistEvery(interval)
+
+		return r, nil
+	}
+}
+
+func (h *Hub) leave(r *room, c *client) {
+	h.mu.Lock()
+	// Clients removed by a recheck leave afterwards, only the first of them to find the room empty closes it
+	empty := r.remove(c) && !r.closing
+	if empty {
+		r.closing = true
+	}
+	h.mu.Unlock()
+
+	if !empty {
+		return
+	}
+
+	close(r.stop)
+	r.persist()
+
+	h.mu.Lock()
+	delete(h.rooms, r.projectID)
+	h.mu.Unlock()
+
+	close(r.done)
+}
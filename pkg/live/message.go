@@ -0,0 +1,50 @@
+package live
+
+import "github.com/swibly/swibly-api/pkg/jsonpatch"
+
+const (
+	// Sent by clients
+	MessagePatch  = "patch"  // JSON Patch operations to apply to the document
+	MessageCursor = "cursor" // Cursor or selection metadata, it is relayed as is
+	MessageSync   = "sync"   // Asks for the whole document again
+
+	// Sent by the server
+	MessageInit  = "init"  // Document, version and presence, sent once after connecting or when asked to sync
+	MessageAck   = "ack"   // The patch of the client was applied with the given seq
+	MessageJoin  = "join"  // Someone started editing
+	MessageLeave = "leave" // Someone stopped editing
+	MessageSaved = "saved" // The document was persisted, `version` is the new ETag of the content
+	MessageReset = "reset" // The document was replaced outside the live session, clients must drop their state
+	MessageError = "error" // The last message of the client was rejected
+)
+
+type Message struct {
+	Type string `json:"type"`
+
+	Ops    []jsonpatch.Operation `json:"ops,omitempty"`
+	Cursor any                   `json:"cursor,omitempty"`
+
+	Content any    `json:"content,omitempty"`
+	Version uint   `json:"version,omitempty"`
+	Seq     uint64 `json:"seq,omitempty"`
+
+	User  *Presence  `json:"user,omitempty"`
+	Users []Presence `json:"users,omitempty"`
+
+	Error  string `json:"error,omitempty"`
+	Errors any    `json:"errors,omitempty"`
+}
+
+// Someone connected to the room. The same user can have more than one connection (tabs, devices),
+// each one identified by `client`
+type Presence struct {
+	Client uint64 `json:"client"`
+
+	ID             uint   `json:"id"`
+	FirstName      string `json:"firstname"`
+	LastName       string `json:"lastname"`
+	Username       string `json:"username"`
+	ProfilePicture string `json:"pfp"`
+
+	Cursor any `json:"cursor"`
+}
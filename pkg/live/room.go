@@ -0,0 +1,310 @@
+package live
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/contentschema"
+	"github.com/swibly/swibly-api/pkg/jsonpatch"
+)
+
+// The document of a project being edited live. Patches are applied in the order they arrive,
+// every one of them gets the next `seq` and is relayed to the other clients.
+type room struct {
+	projectID uint
+	storage   Storage
+
+	mu      sync.Mutex
+	clients map[uint64]*client
+
+	document any
+	version  uint
+	seq      uint64
+
+	// seq of the last persisted patch and who sent the latest patch, used as the author of the revision
+	savedSeq   uint64
+	lastAuthor uint
+
+	persistMu sync.Mutex
+
+	closing bool // guarded by Hub.mu
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newRoom(projectID uint, storage Storage, document any, version uint) *room {
+	return &room{
+		projectID: projectID,
+		storage:   storage,
+		clients:   make(map[uint64]*client),
+		document:  document,
+		version:   version,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+func (r *room) presence() []Presence {
+	users := make([]Presence, 0, len(r.clients))
+	for _, c := range r.clients {
+		users = append(users, c.presence)
+	}
+
+	return users
+}
+
+// Must be called with r.mu held
+func (r *room) broadcast(message Message, except *client) {
+	for _, c := range r.clients {
+		if c != except {
+			c.push(message)
+		}
+	}
+}
+
+// Must be called with r.mu held
+func (r *room) initMessage() Message {
+	return Message{Type: MessageInit, Content: r.document, Version: r.version, Seq: r.seq, Users: r.presence()}
+}
+
+func (r *room) add(c *client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clients[c.presence.Client] = c
+
+	user := c.presence
+
+	c.push(r.initMessage())
+	r.broadcast(Message{Type: MessageJoin, User: &user}, c)
+}
+
+// Reports whether the room is empty after removing the client
+func (r *room) remove(c *client) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.removeLocked(c)
+
+	return len(r.clients) == 0
+}
+
+// Must be called with r.mu held
+func (r *room) removeLocked(c *client) {
+	if _, ok := r.clients[c.presence.Client]; !ok {
+		return
+	}
+
+	delete(r.clients, c.presence.Client)
+	close(c.send)
+
+	user := c.presence
+	r.broadcast(Message{Type: MessageLeave, User: &user}, nil)
+}
+
+// Queues the message for the client as long as it was not removed from the room
+func (r *room) reply(c *client, message Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.clients[c.presence.Client]; ok {
+		c.push(message)
+	}
+}
+
+func (r *room) handle(c *client, message Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The client was disconnected by a recheck, what it still sends is ignored
+	if _, ok := r.clients[c.presence.Client]; !ok {
+		return
+	}
+
+	// Messages are encoded by other goroutines, so they get a copy of the presence
+	user := c.presence
+
+	switch message.Type {
+	case MessagePatch:
+		document, err := jsonpatch.Apply(r.document, message.Ops)
+		if err != nil {
+			c.push(Message{Type: MessageError, Seq: r.seq, Error: patchErrorMessage(c, err)})
+			return
+		}
+
+		if err := contentschema.Validate(contentschema.Project, document); err != nil {
+			c.push(Message{Type: MessageError, Seq: r.seq, Error: c.dict.ContentInvalid, Errors: err})
+			return
+		}
+
+		r.document = document
+		r.seq++
+		r.lastAuthor = c.presence.ID
+
+		c.push(Message{Type: MessageAck, Seq: r.seq})
+		r.broadcast(Message{Type: MessagePatch, Ops: message.Ops, Seq: r.seq, User: &user}, c)
+	case MessageCursor:
+		c.presence.Cursor = message.Cursor
+		user.Cursor = message.Cursor
+
+		r.broadcast(Message{Type: MessageCursor, Cursor: message.Cursor, User: &user}, c)
+	case MessageSync:
+		c.push(r.initMessage())
+	default:
+		c.push(Message{Type: MessageError, Seq: r.seq, Error: c.dict.InvalidBody})
+	}
+}
+
+func patchErrorMessage(c *client, err error) string {
+	var patchErr *jsonpatch.Error
+	if errors.As(err, &patchErr) {
+		return fmt.Sprintf(c.dict.ProjectPatchInvalid, patchErr.Operation.Path)
+	}
+
+	return c.dict.InvalidBody
+}
+
+// Saves the document if it changed since the last save. When the content was changed outside
+// the live session in the meantime, the room is reset to what is stored.
+// The clients are rechecked first, so that nothing is saved on behalf of someone who lost access.
+func (r *room) persist() {
+	r.persistMu.Lock()
+	defer r.persistMu.Unlock()
+
+	r.recheckLocked()
+
+	r.mu.Lock()
+	if r.seq == r.savedSeq {
+		r.mu.Unlock()
+		return
+	}
+
+	// Apply never modifies the documents it receives, so the snapshot can be used without the lock
+	document, version, seq, author := r.document, r.version, r.seq, r.lastAuthor
+	r.mu.Unlock()
+
+	newVersion, err := r.storage.SaveContent(r.projectID, author, version, document)
+	if err != nil {
+		if errors.Is(err, repository.ErrProjectVersionMismatch) {
+			r.reloadLocked()
+			return
+		}
+
+		log.Print(err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.version = newVersion
+	r.savedSeq = seq
+
+	r.broadcast(Message{Type: MessageSaved, Version: newVersion, Seq: seq}, nil)
+}
+
+func (r *room) persistEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.persist()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+func (r *room) recheck() {
+	r.persistMu.Lock()
+	defer r.persistMu.Unlock()
+
+	r.recheckLocked()
+}
+
+// Disconnects the clients that are no longer allowed to edit the project. If the latest patch came from someone
+// who lost access, the changes that were not persisted are dropped rather than saved under their name.
+// Must be called with r.persistMu held.
+func (r *room) recheckLocked() {
+	r.mu.Lock()
+	clients := make([]*client, 0, len(r.clients))
+	for _, c := range r.clients {
+		clients = append(clients, c)
+	}
+	r.mu.Unlock()
+
+	// The checks reach the database, so they run without holding the room
+	revoked := []*client{}
+	for _, c := range clients {
+		if c.authorize != nil && !c.authorize() {
+			revoked = append(revoked, c)
+		}
+	}
+
+	if len(revoked) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+
+	for _, c := range revoked {
+		if _, ok := r.clients[c.presence.Client]; !ok {
+			continue
+		}
+
+		// The error is written before the close message, then the connection is closed and the client leaves
+		c.push(Message{Type: MessageError, Seq: r.seq, Error: c.dict.ProjectMissingPermissions})
+		r.removeLocked(c)
+	}
+
+	// The changes are kept when the author is still connected with another session that passed the check
+	authorRevoked := false
+	for _, c := range revoked {
+		authorRevoked = authorRevoked || c.presence.ID == r.lastAuthor
+	}
+
+	for _, c := range r.clients {
+		authorRevoked = authorRevoked && c.presence.ID != r.lastAuthor
+	}
+
+	drop := authorRevoked && r.seq != r.savedSeq
+
+	r.mu.Unlock()
+
+	if drop {
+		r.reloadLocked()
+	}
+}
+
+// Replaces the document with the stored one, dropping the changes that were not persisted
+func (r *room) reload() {
+	r.persistMu.Lock()
+	defer r.persistMu.Unlock()
+
+	r.reloadLocked()
+}
+
+// Must be called with r.persistMu held
+func (r *room) reloadLocked() {
+	content, version, err := r.storage.GetContent(r.projectID)
+	if err != nil {
+		log.Print(err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.document = content
+	r.version = version
+	r.seq++
+	r.savedSeq = r.seq
+
+	r.broadcast(Message{Type: MessageReset, Content: content, Version: version, Seq: r.seq}, nil)
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/swibly/swibly-api/config"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/pkg/ratelimit"
 	"github.com/swibly/swibly-api/pkg/utils"
 	"github.com/swibly/swibly-api/translations"
 	"github.com/gin-gonic/gin"
@@ -19,7 +22,7 @@ import (
 func APIKeyLookup(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
-	key, err := service.APIKey.GetByKey(ctx.Param("key"))
+	key, err := service.APIKey.GetByPrefix(ctx.Param("prefix"))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.NoAPIKeyFound})
@@ -45,33 +48,104 @@ func APIKeyLookup(ctx *gin.Context) {
 func GetAPIKey(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
-	if strings.TrimSpace(ctx.GetHeader("X-API-KEY")) == "" {
+	secret := ctx.GetHeader("X-API-KEY")
+
+	// Same as the bearer token, WebSocket requests can't carry the header
+	if strings.TrimSpace(secret) == "" && ctx.IsWebsocket() {
+		secret = ctx.Query("api_key")
+	}
+
+	if strings.TrimSpace(secret) == "" {
 		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dict.InvalidAPIKey})
 		return
 	}
 
-	key, err := service.APIKey.GetByKey(ctx.GetHeader("X-API-KEY"))
+	key, err := service.APIKey.GetByKey(secret)
 	if err != nil {
 		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dict.InvalidAPIKey})
 		return
 	}
 
+	if key.IsExpired() {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dict.APIKeyExpired})
+		return
+	}
+
 	ctx.Set("api_key", key)
 	ctx.Next()
 }
 
+// Enforces the per minute/hour/day quotas of the key and sets the X-RateLimit-* headers
+// according to the window closest to its limit
+func apiKeyQuota(ctx *gin.Context, key *dto.ReadAPIKey) bool {
+	dict := translations.GetTranslation(ctx)
+
+	windows := []struct {
+		name  string
+		limit uint
+		size  time.Duration
+	}{
+		{"minute", key.RateLimitMinute, time.Minute},
+		{"hour", key.RateLimitHour, time.Hour},
+		{"day", key.RateLimitDay, 24 * time.Hour},
+	}
+
+	var tightest *ratelimit.Result
+
+	for _, window := range windows {
+		if window.limit == 0 {
+			continue
+		}
+
+		result, err := ratelimit.Allow(fmt.Sprintf("apikey:%d:%s", key.ID, window.name), config.RateLimitRule{Limit: int(window.limit), Window: window.size})
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		if tightest == nil || !result.Allowed || (tightest.Allowed && result.Remaining < tightest.Remaining) {
+			tightest = &result
+		}
+
+		if !result.Allowed {
+			break
+		}
+	}
+
+	if tightest == nil {
+		return true
+	}
+
+	ctx.Header("X-RateLimit-Limit", strconv.Itoa(tightest.Limit))
+	ctx.Header("X-RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+	ctx.Header("X-RateLimit-Reset", strconv.FormatInt(tightest.ResetAt.Unix(), 10))
+
+	if !tightest.Allowed {
+		AbortRateLimited(ctx, tightest.ResetAt, dict.APIKeyRateLimited)
+		return false
+	}
+
+	return true
+}
+
 func apiKeyHas(ctx *gin.Context, b int, permission string) {
 	dict := translations.GetTranslation(ctx)
 
 	key := ctx.Keys["api_key"].(*dto.ReadAPIKey)
 
-	if key.MaxUsage != 0 && key.TimesUsed >= key.MaxUsage {
-		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dict.MaximumAPIKey})
-		return
-	}
+	// Routes can be guarded by more than one of these, but a request only counts once
+	if _, counted := ctx.Get("api_key_counted"); !counted {
+		if key.MaxUsage != 0 && service.APIKey.TimesUsed(key) >= key.MaxUsage {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dict.MaximumAPIKey})
+			return
+		}
 
-	if err := service.APIKey.RegisterUse(key.Key); err != nil {
-		log.Print(err)
+		if !apiKeyQuota(ctx, key) {
+			return
+		}
+
+		service.APIKey.RegisterUse(key, ctx.ClientIP())
+		ctx.Set("api_key_counted", true)
 	}
 
 	if b == -1 {
@@ -12,10 +12,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Browsers can't set headers when opening a WebSocket, so those requests may send the token
+// in the `token` query param instead
+func bearerToken(ctx *gin.Context) string {
+	token := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+
+	if token == "" && ctx.IsWebsocket() {
+		token = ctx.Query("token")
+	}
+
+	return token
+}
+
 func Auth(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
 
-	tokenString := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	tokenString := bearerToken(ctx)
 
 	if tokenString == "" {
 		log.Print("Couldn't find JWT token in header")
@@ -40,6 +52,21 @@ func Auth(ctx *gin.Context) {
 		return
 	}
 
+	sessionID, err := strconv.Atoi(claims.Id)
+	if err != nil || sessionID < 0 {
+		log.Print(err)
+
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dict.Unauthorized})
+		return
+	}
+
+	if active, err := service.Session.IsActive(uint(id), uint(sessionID)); !active || err != nil {
+		log.Print("Session is not active: ", err)
+
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": dict.Unauthorized})
+		return
+	}
+
 	pf, err := service.User.GetByID(uint(id))
 	if pf == nil && err != nil {
 		log.Print(err)
@@ -49,12 +76,13 @@ func Auth(ctx *gin.Context) {
 	}
 
 	ctx.Set("auth_user", pf)
+	ctx.Set("auth_session", uint(sessionID))
 	ctx.Next()
 }
 
 func OptionalAuth(ctx *gin.Context) {
 	dict := translations.GetTranslation(ctx)
-	tokenString := strings.TrimPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	tokenString := bearerToken(ctx)
 
 	if tokenString == "" {
 		ctx.Next()
@@ -77,6 +105,20 @@ func OptionalAuth(ctx *gin.Context) {
 		return
 	}
 
+	sessionID, err := strconv.Atoi(claims.Id)
+	if err != nil || sessionID < 0 {
+		log.Print(err)
+		ctx.Next()
+		return
+	}
+
+	// Revoked sessions are treated the same way as expired tokens
+	if active, err := service.Session.IsActive(uint(id), uint(sessionID)); !active || err != nil {
+		log.Print("Session is not active: ", err)
+		ctx.Next()
+		return
+	}
+
 	pf, err := service.User.GetByID(uint(id))
 	if pf == nil && err != nil {
 		log.Print(err)
@@ -86,5 +128,6 @@ func OptionalAuth(ctx *gin.Context) {
 	}
 
 	ctx.Set("auth_user", pf)
+	ctx.Set("auth_session", uint(sessionID))
 	ctx.Next()
 }
@@ -38,7 +38,7 @@ func ComponentLookup(ctx *gin.Context) {
 		return
 	}
 
-	if !component.IsPublic && issuer.ID != component.OwnerID {
+	if !component.IsPublic && (component.OrganizationID != nil || issuer.ID != component.OwnerID) && organizationRole(component.OrganizationID, issuer.ID) == "" {
 		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.ComponentNotFound})
 		return
 	}
@@ -54,7 +54,23 @@ func ComponentOwnership(ctx *gin.Context) {
 	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
 
 	component := ctx.Keys["component_lookup"].(*dto.ComponentInfo)
-	if issuer.HasPermissions(config.Permissions.ManageStore) || component.OwnerUsername != issuer.Username {
+	if issuer.HasPermissions(config.Permissions.ManageStore) || !isOwner(component.OrganizationID, component.OwnerID, issuer.ID) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.ComponentNotFound})
+		return
+	}
+
+	ctx.Next()
+}
+
+// middleware.ComponentLookup must be called before this. Unlike middleware.ComponentOwnership, users with ManageStore
+// can hand over components they do not own
+func ComponentTransferOwnership(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	component := ctx.Keys["component_lookup"].(*dto.ComponentInfo)
+	if !issuer.HasPermissions(config.Permissions.ManageStore) && !isOwner(component.OrganizationID, component.OwnerID, issuer.ID) {
 		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.ComponentNotFound})
 		return
 	}
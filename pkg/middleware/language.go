@@ -17,6 +17,7 @@ func GetLanguage(ctx *gin.Context) {
 	}
 
 	ctx.Set("lang", translations.Translations[xlang])
+	ctx.Set("lang_code", xlang)
 
 	ctx.Next()
 }
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/translations"
+	"gorm.io/gorm"
+)
+
+func OrganizationLookup(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	organization, err := service.Organization.GetByName(issuer.ID, ctx.Param("organization"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.OrganizationNotFound})
+			return
+		}
+
+		log.Print(err)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+		return
+	}
+
+	ctx.Set("organization_lookup", organization)
+	ctx.Next()
+}
+
+// middleware.OrganizationLookup must be called before this. Users with ManageUser pass regardless of their role.
+func OrganizationHasRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		dict := translations.GetTranslation(ctx)
+
+		issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+		organization := ctx.Keys["organization_lookup"].(*dto.OrganizationInfo)
+
+		if !slices.Contains(roles, organization.Role) && !issuer.HasPermissions(config.Permissions.ManageUser) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.OrganizationMissingPermissions})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// The role of the user in the organization owning a project or component, empty when there is none
+func organizationRole(organizationID *uint, userID uint) string {
+	if organizationID == nil {
+		return ""
+	}
+
+	role, err := service.Organization.GetRole(*organizationID, userID)
+	if err != nil {
+		log.Print(err)
+		return ""
+	}
+
+	return role
+}
+
+// Whether the user has the rights of the owner of a project or component. Those belonging to an organization are
+// owned by it, so only its owners and admins have them, not the member who created them.
+func isOwner(organizationID *uint, ownerID, userID uint) bool {
+	if organizationID == nil {
+		return ownerID == userID
+	}
+
+	return dto.IsOrganizationManager(organizationRole(organizationID, userID))
+}
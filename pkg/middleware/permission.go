@@ -3,22 +3,23 @@ package middleware
 import (
 	"net/http"
 
-	"github.com/swibly/swibly-api/pkg/utils"
-	"github.com/swibly/swibly-api/translations"
 	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/translations"
 )
 
+// middleware.Auth must be called before this
 func HasPermissions(permissions ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		dict := translations.GetTranslation(ctx)
 
-		list, exists := ctx.Get("permissions")
+		issuer, exists := ctx.Get("auth_user")
 		if !exists {
 			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.Unauthorized})
 			return
 		}
 
-		if !utils.HasPermissions(list.([]string), permissions...) {
+		if !issuer.(*dto.UserProfile).HasPermissions(permissions...) {
 			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.Unauthorized})
 			return
 		}
@@ -26,3 +27,25 @@ func HasPermissions(permissions ...string) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// Lets the request through when the issuer has at least one of the permissions
+func HasAnyPermissions(permissions ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		dict := translations.GetTranslation(ctx)
+
+		issuer, exists := ctx.Get("auth_user")
+		if !exists {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.Unauthorized})
+			return
+		}
+
+		for _, permission := range permissions {
+			if issuer.(*dto.UserProfile).HasPermissions(permission) {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.Unauthorized})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/swibly/swibly-api/config"
 	"github.com/swibly/swibly-api/internal/model/dto"
 	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
 	"github.com/swibly/swibly-api/translations"
 	"gorm.io/gorm"
 )
@@ -39,87 +40,195 @@ func ProjectLookup(ctx *gin.Context) {
 	}
 
 	ctx.Set("project_lookup", project)
+
+	// Share links can be sent along any project request, granting what their scope allows. They are left alone for
+	// those who are already part of the project, so that a stale link does not lock them out.
+	if token := shareToken(ctx); token != "" && !belongsToProject(issuer, project) {
+		link, err := service.ProjectShare.Use(project.ID, issuer.ID, token)
+		if err != nil {
+			if errors.Is(err, repository.ErrShareLinkInvalid) {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.ProjectShareLinkInvalid})
+				return
+			}
+
+			log.Print(err)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": dict.InternalServerError})
+			return
+		}
+
+		ctx.Set("project_share_link", link)
+	}
+
 	ctx.Next()
 }
 
+func shareToken(ctx *gin.Context) string {
+	if token := ctx.GetHeader("X-Share-Token"); token != "" {
+		return token
+	}
+
+	return ctx.Query("share")
+}
+
+// Whether the issuer is part of the project on their own, leaving aside public projects and share links
+func belongsToProject(issuer *dto.UserProfile, project *dto.ProjectInfo) bool {
+	if (project.OrganizationID == nil && project.OwnerID == issuer.ID) || issuer.HasPermissions(config.Permissions.ManageProjects) {
+		return true
+	}
+
+	for _, allowedUser := range project.AllowedUsers {
+		if allowedUser.ID == issuer.ID {
+			return true
+		}
+	}
+
+	return organizationRole(project.OrganizationID, issuer.ID) != ""
+}
+
 // middleware.ProjectLookup must be called before middleware.ProjectOwnership
 func ProjectIsAllowed(requiredPermissions dto.Allow) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		dict := translations.GetTranslation(ctx)
 
-		project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
-		issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+		if !HasProjectPermissions(ctx, requiredPermissions) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.ProjectMissingPermissions})
+			return
+		}
 
-		if project.OwnerUsername != issuer.Username && !issuer.HasPermissions(config.Permissions.ManageProjects) {
-			isAllowed := false
+		ctx.Next()
+	}
+}
+
+// Reports whether the issuer has the permissions on the project found by middleware.ProjectLookup, be it as the owner,
+// as a member of the organization owning it, as an assigned user or through the share link sent with the request
+func HasProjectPermissions(ctx *gin.Context, requiredPermissions dto.Allow) bool {
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	if (project.OrganizationID == nil && project.OwnerID == issuer.ID) || issuer.HasPermissions(config.Permissions.ManageProjects) {
+		return true
+	}
+
+	if !requiredPermissions.IsEmpty() && dto.OrganizationRoleAllow(organizationRole(project.OrganizationID, issuer.ID)).Covers(requiredPermissions) {
+		return true
+	}
+
+	if link, ok := ctx.Keys["project_share_link"].(*dto.ProjectShareLinkInfo); ok && link.Allow().Covers(requiredPermissions) {
+		return true
+	}
 
-			if requiredPermissions.View && project.IsPublic {
-				isAllowed = true
+	isAllowed := false
+
+	if requiredPermissions.View && project.IsPublic {
+		isAllowed = true
+	}
+
+	for _, allowedUser := range project.AllowedUsers {
+		if allowedUser.Username == issuer.Username {
+			if requiredPermissions.View {
+				if !allowedUser.View && !project.IsPublic {
+					isAllowed = false
+					break
+				}
 			}
 
-			for _, allowedUser := range project.AllowedUsers {
-				if allowedUser.Username == issuer.Username {
-					if requiredPermissions.View {
-						if !allowedUser.View && !project.IsPublic {
-							isAllowed = false
-							break
-						}
-					}
-
-					if requiredPermissions.Edit {
-						if !allowedUser.Edit {
-							isAllowed = false
-							break
-						}
-					}
-
-					if requiredPermissions.Delete {
-						if !allowedUser.Delete {
-							isAllowed = false
-							break
-						}
-					}
-
-					if requiredPermissions.Publish {
-						if !allowedUser.Publish {
-							isAllowed = false
-							break
-						}
-					}
-
-					if requiredPermissions.Share {
-						if !allowedUser.Share && !project.IsPublic {
-							isAllowed = false
-							break
-						}
-					}
-
-					if requiredPermissions.Manage.Users {
-						if !allowedUser.ManageUsers {
-							isAllowed = false
-							break
-						}
-					}
-
-					if requiredPermissions.Manage.Metadata {
-						if !allowedUser.ManageMetadata {
-							isAllowed = false
-							break
-						}
-					}
-
-					isAllowed = true
+			if requiredPermissions.Edit {
+				if !allowedUser.Edit {
+					isAllowed = false
+					break
 				}
 			}
 
-			if !isAllowed {
-				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.ProjectMissingPermissions})
-				return
+			if requiredPermissions.Delete {
+				if !allowedUser.Delete {
+					isAllowed = false
+					break
+				}
 			}
+
+			if requiredPermissions.Publish {
+				if !allowedUser.Publish {
+					isAllowed = false
+					break
+				}
+			}
+
+			if requiredPermissions.Share {
+				if !allowedUser.Share && !project.IsPublic {
+					isAllowed = false
+					break
+				}
+			}
+
+			if requiredPermissions.Manage.Users {
+				if !allowedUser.ManageUsers {
+					isAllowed = false
+					break
+				}
+			}
+
+			if requiredPermissions.Manage.Metadata {
+				if !allowedUser.ManageMetadata {
+					isAllowed = false
+					break
+				}
+			}
+
+			isAllowed = true
 		}
+	}
 
-		ctx.Next()
+	return isAllowed
+}
+
+// Goes through middleware.Auth, middleware.ProjectLookup and HasProjectPermissions again with what is stored now,
+// for connections that outlive the request that opened them. The request itself is left untouched.
+func StillHasProjectPermissions(ctx *gin.Context, requiredPermissions dto.Allow) bool {
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+	sessionID := ctx.Keys["auth_session"].(uint)
+
+	if active, err := service.Session.IsActive(issuer.ID, sessionID); !active || err != nil {
+		return false
+	}
+
+	issuer, err := service.User.GetByID(issuer.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Print(err)
+		}
+
+		return false
+	}
+
+	project, err = service.Project.GetByID(issuer.ID, project.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Print(err)
+		}
+
+		return false
+	}
+
+	check := ctx.Copy()
+	check.Set("auth_user", issuer)
+	check.Set("project_lookup", project)
+	delete(check.Keys, "project_share_link")
+
+	if token := shareToken(ctx); token != "" && !belongsToProject(issuer, project) {
+		link, err := service.ProjectShare.Use(project.ID, issuer.ID, token)
+		if err != nil {
+			if !errors.Is(err, repository.ErrShareLinkInvalid) {
+				log.Print(err)
+			}
+
+			return false
+		}
+
+		check.Set("project_share_link", link)
 	}
+
+	return HasProjectPermissions(check, requiredPermissions)
 }
 
 // middleware.ProjectLookup must be called before middleware.ProjectOwnership
@@ -129,7 +238,23 @@ func ProjectOwnership(ctx *gin.Context) {
 	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
 
 	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
-	if issuer.HasPermissions(config.Permissions.ManageProjects) || project.OwnerUsername != issuer.Username {
+	if issuer.HasPermissions(config.Permissions.ManageProjects) || !isOwner(project.OrganizationID, project.OwnerID, issuer.ID) {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.ProjectNotFound})
+		return
+	}
+
+	ctx.Next()
+}
+
+// middleware.ProjectLookup must be called before this. Unlike middleware.ProjectOwnership, users with ManageProjects
+// can hand over projects they do not own
+func ProjectTransferOwnership(ctx *gin.Context) {
+	dict := translations.GetTranslation(ctx)
+
+	issuer := ctx.Keys["auth_user"].(*dto.UserProfile)
+
+	project := ctx.Keys["project_lookup"].(*dto.ProjectInfo)
+	if !issuer.HasPermissions(config.Permissions.ManageProjects) && !isOwner(project.OrganizationID, project.OwnerID, issuer.ID) {
 		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": dict.ProjectNotFound})
 		return
 	}
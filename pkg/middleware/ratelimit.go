@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/pkg/ratelimit"
+	"github.com/swibly/swibly-api/translations"
+)
+
+// Limits the requests of each IP. The name separates the counters of different rules
+func RateLimit(name string, rule config.RateLimitRule) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		dict := translations.GetTranslation(ctx)
+
+		result, err := ratelimit.Allow(name+":"+ctx.ClientIP(), rule)
+		if err != nil {
+			// Better to let the request through than to take the whole API down with the store
+			log.Print(err)
+			ctx.Next()
+			return
+		}
+
+		if !result.Allowed {
+			AbortRateLimited(ctx, result.ResetAt, dict.RateLimited)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func AbortRateLimited(ctx *gin.Context, until time.Time, message string) {
+	seconds := int(math.Ceil(time.Until(until).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	ctx.Header("Retry-After", strconv.Itoa(seconds))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": message})
+}
@@ -60,6 +60,10 @@ func UserPrivacy(requiredShow dto.UserShow) gin.HandlerFunc {
 				isAllowed = false
 			}
 
+			if requiredShow.Achievements == true && !user.Show.Achievements && !issuer.HasPermissions(config.Permissions.ManageUser) {
+				isAllowed = false
+			}
+
 			if !isAllowed {
 				ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": dict.UserMissingPermissions})
 				return
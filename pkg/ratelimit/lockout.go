@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"github.com/swibly/swibly-api/config"
+)
+
+func failuresKey(key string) string { return "failures:" + key }
+func lockKey(key string) string     { return "lock:" + key }
+
+// Returns the time until which `key` is locked, or the zero time if it is not locked
+func LockedUntil(key string) (time.Time, error) {
+	if Default == nil {
+		return time.Time{}, nil
+	}
+
+	counter, err := Default.Get(lockKey(key))
+	if err != nil || counter.Count == 0 {
+		return time.Time{}, err
+	}
+
+	return counter.ResetAt, nil
+}
+
+// Records a failed attempt for `key`. Once the failures inside the lockout window reach the threshold,
+// the key gets locked, and every further failure doubles the lock duration (up to the configured maximum).
+// Returns the time until which the key is locked, or the zero time if it is not.
+func RegisterFailure(key string) (time.Time, error) {
+	policy := config.RateLimit.Lockout
+	if Default == nil || policy.Threshold <= 0 {
+		return time.Time{}, nil
+	}
+
+	failures, err := Default.Increment(failuresKey(key), policy.Window)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if failures.Count < policy.Threshold {
+		return time.Time{}, nil
+	}
+
+	duration := policy.Base
+	// Without a maximum the lock keeps doubling, short of overflowing the duration
+	for i := policy.Threshold; i < failures.Count && (policy.Max == 0 || duration < policy.Max) && duration <= math.MaxInt64/2; i++ {
+		duration *= 2
+	}
+
+	if policy.Max > 0 && duration > policy.Max {
+		duration = policy.Max
+	}
+
+	until := time.Now().Add(duration)
+
+	// Keep counting failures for as long as the lock lasts, so the next failure locks for longer
+	if failures.ResetAt.Before(until) {
+		failures.ResetAt = until
+		if err := Default.Set(failuresKey(key), failures); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return until, Default.Set(lockKey(key), Counter{Count: 1, ResetAt: until})
+}
+
+func ClearFailures(key string) error {
+	if Default == nil {
+		return nil
+	}
+
+	if err := Default.Reset(failuresKey(key)); err != nil {
+		return err
+	}
+
+	return Default.Reset(lockKey(key))
+}
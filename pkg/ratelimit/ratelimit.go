@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"log"
+	"time"
+
+	"github.com/swibly/swibly-api/config"
+)
+
+var Default Store
+
+func Init() {
+	switch config.RateLimit.Backend {
+	case "", "memory":
+		Default = NewMemoryStore(time.Minute)
+	default:
+		log.Fatalf("error: unknown rate limit backend %q", config.RateLimit.Backend)
+	}
+
+	log.Print("Loaded rate limiter")
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+func (r Result) RetryAfter() time.Duration {
+	return time.Until(r.ResetAt)
+}
+
+// Counts a hit for `key` and reports whether it is still inside the rule's limit.
+// Rules without a limit or a window are treated as disabled.
+func Allow(key string, rule config.RateLimitRule) (Result, error) {
+	if Default == nil || rule.Limit <= 0 || rule.Window <= 0 {
+		return Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit}, nil
+	}
+
+	counter, err := Default.Increment(key, rule.Window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:   counter.Count <= rule.Limit,
+		Limit:     rule.Limit,
+		Remaining: max(rule.Limit-counter.Count, 0),
+		ResetAt:   counter.ResetAt,
+	}, nil
+}
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type Counter struct {
+	Count   int
+	ResetAt time.Time
+}
+
+func (c Counter) Expired(now time.Time) bool {
+	return !c.ResetAt.After(now)
+}
+
+// Backend used to keep the counters. Implementations must be safe for concurrent use
+// and should treat expired counters as missing.
+type Store interface {
+	// Increments the counter of `key`, starting a new window of `window` length if there is none
+	Increment(key string, window time.Duration) (Counter, error)
+	Get(key string) (Counter, error)
+	Set(key string, counter Counter) error
+	Reset(key string) error
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	counters map[string]Counter
+}
+
+// In-process store. Counters are not shared between instances of the API
+func NewMemoryStore(cleanupInterval time.Duration) Store {
+	s := &memoryStore{counters: make(map[string]Counter)}
+
+	if cleanupInterval > 0 {
+		go func() {
+			for range time.Tick(cleanupInterval) {
+				s.cleanup()
+			}
+		}()
+	}
+
+	return s
+}
+
+func (s *memoryStore) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, counter := range s.counters {
+		if counter.Expired(now) {
+			delete(s.counters, key)
+		}
+	}
+}
+
+func (s *memoryStore) Increment(key string, window time.Duration) (Counter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	counter, ok := s.counters[key]
+	if !ok || counter.Expired(now) {
+		counter = Counter{ResetAt: now.Add(window)}
+	}
+
+	counter.Count++
+	s.counters[key] = counter
+
+	return counter, nil
+}
+
+func (s *memoryStore) Get(key string) (Counter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, ok := s.counters[key]
+	if !ok || counter.Expired(time.Now()) {
+		return Counter{}, nil
+	}
+
+	return counter, nil
+}
+
+func (s *memoryStore) Set(key string, counter Counter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.counters[key] = counter
+
+	return nil
+}
+
+func (s *memoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.counters, key)
+
+	return nil
+}
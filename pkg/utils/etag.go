@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Versions are sent to clients as strong entity tags, e.g. "3"
+func ETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// Parses a single entity tag created by ETag. Weak tags (W/"3") are accepted as well.
+func ParseETag(tag string) (uint, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(version), true
+}
+
+// Reports whether an If-None-Match (or If-Match) header value matches the version.
+// The header can hold a list of tags or "*", which matches any version.
+func ETagMatches(header string, version uint) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if parsed, ok := ParseETag(tag); ok && parsed == version {
+			return true
+		}
+	}
+
+	return false
+}
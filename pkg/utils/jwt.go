@@ -8,10 +8,19 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-func GenerateJWT(id uint) (string, error) {
+const ChallengeAudience = "2fa"
+
+// Access tokens are short-lived and bound to a session, which is stored in the `jti` claim
+func GenerateJWT(id, sessionID uint) (string, error) {
+	ttl := config.Security.AccessTokenTTL
+	if ttl <= 0 {
+		ttl = 15 * time.Minute
+	}
+
 	claims := &jwt.StandardClaims{
+		Id:        fmt.Sprint(sessionID),
 		Subject:   fmt.Sprint(id),
-		ExpiresAt: time.Now().Add(time.Hour * 24 * 7).Unix(), // Token expires after 7 days
+		ExpiresAt: time.Now().Add(ttl).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -24,6 +33,20 @@ func GenerateJWT(id uint) (string, error) {
 	return tokenString, nil
 }
 
+// Issued after the password check when the user has 2FA enabled. It has no session attached, so it
+// is not accepted by middleware.Auth and can only be exchanged for a session along with a TOTP code
+func GenerateChallengeJWT(id uint) (string, error) {
+	claims := &jwt.StandardClaims{
+		Audience:  ChallengeAudience,
+		Subject:   fmt.Sprint(id),
+		ExpiresAt: time.Now().Add(5 * time.Minute).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	return token.SignedString([]byte(config.Security.JWTSecret))
+}
+
 func GetClaimsJWT(tokenString string) (*jwt.StandardClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &jwt.StandardClaims{}, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
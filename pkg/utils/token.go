@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+const APIKeyIdentifier = "swb"
+
+// Generates a cryptographically secure random token with `size` bytes of entropy, encoded as hex
+func RandomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Tokens handed to clients are never stored as is, only their SHA-256 digest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// API keys look like `swb_<prefix>_<secret>`. The prefix is public and identifies the key,
+// only the digest of the whole string is stored
+func GenerateAPIKey() (key, prefix string, err error) {
+	prefix, err = RandomToken(4)
+	if err != nil {
+		return "", "", err
+	}
+
+	secret, err := RandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return fmt.Sprintf("%s_%s_%s", APIKeyIdentifier, prefix, secret), prefix, nil
+}
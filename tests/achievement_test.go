@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"gopkg.in/yaml.v3"
+)
+
+func TestAchievementDefinitions(t *testing.T) {
+	content, err := os.ReadFile("../config/achievements.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var achievements struct {
+		Definitions []config.AchievementDefinition `yaml:"achievements"`
+	}
+
+	if err := yaml.Unmarshal(content, &achievements); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := []string{
+		dto.MetricProjectsCreated,
+		dto.MetricProjectsPublished,
+		dto.MetricFavoritesReceived,
+		dto.MetricForksReceived,
+		dto.MetricComponentsSold,
+		dto.MetricFollowers,
+	}
+
+	keys := map[string]bool{}
+	for _, definition := range achievements.Definitions {
+		if keys[definition.Key] {
+			t.Errorf("%s is defined more than once", definition.Key)
+		}
+		keys[definition.Key] = true
+
+		if definition.Name == "" {
+			t.Errorf("%s has no name", definition.Key)
+		}
+
+		if !slices.Contains(metrics, definition.Metric) {
+			t.Errorf("%s counts an unknown metric %q", definition.Key, definition.Metric)
+		}
+
+		if definition.Threshold == 0 {
+			t.Errorf("%s would be unlocked by everyone", definition.Key)
+		}
+	}
+}
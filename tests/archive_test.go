@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/swibly/swibly-api/pkg/archive"
+	"github.com/swibly/swibly-api/pkg/contentschema"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	original := &archive.Archive{
+		Manifest: archive.Manifest{
+			Project: archive.Metadata{Name: "Living room", Description: "Sofa and shelves", Width: 40, Height: 30, Budget: 5000},
+			Components: []archive.ComponentReference{
+				{ID: 3, Name: "Sofa", OwnerUsername: "john"},
+			},
+		},
+		Content: decodeJSON(t, `{"schema_version": 1, "items": [{"component_id": 3}]}`),
+		Banner:  []byte("not really an image"),
+	}
+
+	buf := new(bytes.Buffer)
+	if err := original.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := archive.Read(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read.Manifest.Format != archive.FormatVersion || read.Manifest.Banner != archive.BannerFile {
+		t.Fatalf("unexpected manifest %+v", read.Manifest)
+	}
+
+	if read.Manifest.Project != original.Manifest.Project {
+		t.Fatalf("expected %+v, got %+v", original.Manifest.Project, read.Manifest.Project)
+	}
+
+	if !reflect.DeepEqual(read.Manifest.Components, original.Manifest.Components) {
+		t.Fatalf("expected %+v, got %+v", original.Manifest.Components, read.Manifest.Components)
+	}
+
+	if !reflect.DeepEqual(read.Content, original.Content) {
+		t.Fatalf("expected %v, got %v", original.Content, read.Content)
+	}
+
+	if !bytes.Equal(read.Banner, original.Banner) {
+		t.Fatalf("expected the banner to be kept")
+	}
+}
+
+func TestArchiveWithoutBanner(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := (&archive.Archive{Content: decodeJSON(t, `{}`)}).Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := archive.Read(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read.Manifest.Banner != "" || read.Banner != nil {
+		t.Fatalf("expected no banner, got %q", read.Manifest.Banner)
+	}
+}
+
+func TestArchiveInvalid(t *testing.T) {
+	if _, err := archive.Read([]byte("not a zip")); !errors.Is(err, archive.ErrInvalidArchive) {
+		t.Fatalf("expected ErrInvalidArchive, got %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+	f, _ := zw.Create(archive.ManifestFile)
+	f.Write([]byte(`{"format": 99}`))
+	zw.Close()
+
+	if _, err := archive.Read(buf.Bytes()); !errors.Is(err, archive.ErrUnsupportedFormat) {
+		t.Fatalf("expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestComponentReferences(t *testing.T) {
+	doc := decodeJSON(t, `{
+		"schema_version": 1,
+		"items": [
+			{"component_id": 7, "x": 1},
+			{"group": [{"component_id": 2}, {"component_id": 7}]},
+			{"component_id": "12"},
+			{"component_id": 1.5}
+		]
+	}`)
+
+	if got := contentschema.ComponentReferences(doc); !reflect.DeepEqual(got, []uint{2, 7}) {
+		t.Fatalf("expected [2 7], got %v", got)
+	}
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/swibly/swibly-api/pkg/contentschema"
+)
+
+const testKind contentschema.Kind = "test"
+
+func registerTestSchemas(t *testing.T) {
+	t.Helper()
+
+	maxLength := 8
+	additional := false
+
+	contentschema.Register(testKind, 1, &contentschema.Schema{Type: "object"})
+	contentschema.Register(testKind, 2, &contentschema.Schema{
+		Type:                 "object",
+		Required:             []string{"schema_version", "name"},
+		AdditionalProperties: &additional,
+		Properties: map[string]*contentschema.Schema{
+			"schema_version": {Type: "integer"},
+			"name":           {Type: "string", MaxLength: &maxLength},
+			"layers":         {Type: "array", Items: &contentschema.Schema{Type: "object", Required: []string{"id"}}},
+		},
+	})
+
+	contentschema.RegisterUpgrade(testKind, 0, func(doc map[string]any) (map[string]any, error) {
+		return doc, nil
+	})
+
+	contentschema.RegisterUpgrade(testKind, 1, func(doc map[string]any) (map[string]any, error) {
+		if _, ok := doc["name"]; !ok {
+			doc["name"] = "untitled"
+		}
+
+		return doc, nil
+	})
+}
+
+func TestContentSchema_Upgrade_Legacy(t *testing.T) {
+	registerTestSchemas(t)
+
+	legacy := decodeJSON(t, `{"layers": []}`)
+
+	doc, err := contentschema.Normalize(testKind, legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := decodeJSON(t, `{"schema_version": 2, "name": "untitled", "layers": []}`)
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("expected %v, got %v", expected, doc)
+	}
+
+	if _, ok := legacy.(map[string]any)["name"]; ok {
+		t.Error("the original document was modified")
+	}
+
+	if doc, err := contentschema.Normalize(testKind, nil); err != nil || !reflect.DeepEqual(doc, contentschema.Empty(testKind)) {
+		t.Errorf("expected missing content to become the empty document, got %v (%v)", doc, err)
+	}
+}
+
+func TestContentSchema_Validate_Paths(t *testing.T) {
+	registerTestSchemas(t)
+
+	doc := decodeJSON(t, `{"schema_version": 2, "name": "far too long", "layers": [{"id": 1}, {}], "extra": true}`)
+
+	err := contentschema.Validate(testKind, doc)
+
+	var errs contentschema.ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	expected := contentschema.ValidationErrors{
+		{Path: "/extra", Rule: "additionalProperties", Param: false},
+		{Path: "/layers/1/id", Rule: "required"},
+		{Path: "/name", Rule: "maxLength", Param: 8},
+	}
+
+	if !reflect.DeepEqual(errs, expected) {
+		t.Errorf("expected %v, got %v", expected, errs)
+	}
+}
+
+func TestContentSchema_Validate_Version(t *testing.T) {
+	registerTestSchemas(t)
+
+	for _, raw := range []string{`{"schema_version": 3, "name": "a"}`, `{"schema_version": "2", "name": "a"}`, `[]`} {
+		if _, err := contentschema.Normalize(testKind, decodeJSON(t, raw)); err == nil {
+			t.Errorf("expected %s to be rejected", raw)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/swibly/swibly-api/pkg/utils"
+)
+
+func TestETag_RoundTrip(t *testing.T) {
+	version, ok := utils.ParseETag(utils.ETag(42))
+	if !ok || version != 42 {
+		t.Errorf("expected 42, got %d (ok: %v)", version, ok)
+	}
+
+	if version, ok := utils.ParseETag(`W/"7"`); !ok || version != 7 {
+		t.Errorf("expected weak tag to parse as 7, got %d (ok: %v)", version, ok)
+	}
+
+	for _, invalid := range []string{"", "7", `"abc"`, `"-1"`, `"`} {
+		if _, ok := utils.ParseETag(invalid); ok {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestETag_Matches(t *testing.T) {
+	if !utils.ETagMatches(`"1", "3"`, 3) {
+		t.Error("expected list to match")
+	}
+
+	if !utils.ETagMatches("*", 5) {
+		t.Error("expected * to match any version")
+	}
+
+	if utils.ETagMatches(`"2"`, 3) {
+		t.Error("expected different version not to match")
+	}
+}
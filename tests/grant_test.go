@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/utils"
+)
+
+func TestGrantValidation(t *testing.T) {
+	cases := []struct {
+		name  string
+		grant dto.GrantCreation
+		valid bool
+	}{
+		{"grant", dto.GrantCreation{Currency: dto.GrantArkhoin, Amount: 100, Reason: "Event prize"}, true},
+		{"deduction", dto.GrantCreation{Currency: dto.GrantXP, Amount: -50, Reason: "Abuse"}, true},
+		{"zero", dto.GrantCreation{Currency: dto.GrantXP, Amount: 0, Reason: "Nothing"}, false},
+		{"no reason", dto.GrantCreation{Currency: dto.GrantArkhoin, Amount: 100}, false},
+		{"unknown currency", dto.GrantCreation{Currency: "gold", Amount: 100, Reason: "Event prize"}, false},
+	}
+
+	for _, c := range cases {
+		if valid := utils.ValidateStruct(&c.grant) == nil; valid != c.valid {
+			t.Errorf("%s: expected valid to be %v", c.name, c.valid)
+		}
+	}
+}
+
+func TestAirdropNeedsRecipients(t *testing.T) {
+	airdrop := dto.AirdropCreation{
+		Currency:    dto.GrantArkhoin,
+		Amount:      10,
+		Reason:      "Launch party",
+		ScheduledAt: time.Now().Add(time.Hour),
+	}
+
+	if utils.ValidateStruct(&airdrop) == nil {
+		t.Fatal("an airdrop without usernames should need everyone")
+	}
+
+	airdrop.Everyone = true
+	if errs := utils.ValidateStruct(&airdrop); errs != nil {
+		t.Fatalf("an airdrop to everyone should be valid, got %v", errs)
+	}
+
+	airdrop.Amount = -10
+	if utils.ValidateStruct(&airdrop) == nil {
+		t.Fatal("airdrops should not deduct")
+	}
+}
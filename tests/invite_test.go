@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+)
+
+func TestInvitePermissions(t *testing.T) {
+	yes, no := true, false
+
+	requested := dto.ProjectAssign{View: &yes, Edit: &yes, Delete: &no, ManageMetadata: &yes}
+
+	allow := requested.Allow()
+	if allow != (dto.Allow{View: true, Edit: true, Manage: dto.AllowManage{Metadata: true}}) {
+		t.Fatalf("unexpected permissions %+v", allow)
+	}
+
+	invite := dto.ProjectInviteInfo{View: allow.View, Edit: allow.Edit, ManageMetadata: allow.Manage.Metadata}
+
+	// Accepting sets every flag, so the invite fully replaces whatever the user had before
+	assign := invite.Assign()
+	for name, flag := range map[string]*bool{
+		"view": assign.View, "edit": assign.Edit, "delete": assign.Delete, "publish": assign.Publish,
+		"share": assign.Share, "manage_users": assign.ManageUsers, "manage_metadata": assign.ManageMetadata,
+	} {
+		if flag == nil {
+			t.Fatalf("%s is not set", name)
+		}
+	}
+
+	if assign.Allow() != allow {
+		t.Fatalf("expected %+v, got %+v", allow, assign.Allow())
+	}
+}
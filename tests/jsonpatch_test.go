@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/swibly/swibly-api/pkg/jsonpatch"
+)
+
+func decodeJSON(t *testing.T, raw string) any {
+	t.Helper()
+
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
+
+func TestJSONPatch_Diff_Equal(t *testing.T) {
+	value := decodeJSON(t, `{"a": [1, 2, {"b": true}], "c": null}`)
+
+	if ops := jsonpatch.Diff(value, value); len(ops) != 0 {
+		t.Errorf("expected no operations, got %v", ops)
+	}
+}
+
+func TestJSONPatch_Diff_Success(t *testing.T) {
+	from := decodeJSON(t, `{"name": "a", "gone": 1, "list": [1, 2, 3], "nested": {"x/y": 1}}`)
+	to := decodeJSON(t, `{"name": "b", "list": [1, 5], "nested": {"x/y": 2}, "new": [true]}`)
+
+	expected := []jsonpatch.Operation{
+		{Op: jsonpatch.OpRemove, Path: "/gone"},
+		{Op: jsonpatch.OpReplace, Path: "/list/1", Value: float64(5)},
+		{Op: jsonpatch.OpRemove, Path: "/list/2"},
+		{Op: jsonpatch.OpReplace, Path: "/name", Value: "b"},
+		{Op: jsonpatch.OpReplace, Path: "/nested/x~1y", Value: float64(2)},
+		{Op: jsonpatch.OpAdd, Path: "/new", Value: []any{true}},
+	}
+
+	if ops := jsonpatch.Diff(from, to); !reflect.DeepEqual(ops, expected) {
+		t.Errorf("expected %v, got %v", expected, ops)
+	}
+}
+
+func TestJSONPatch_Diff_TypeChange(t *testing.T) {
+	ops := jsonpatch.Diff(decodeJSON(t, `{"a": {}}`), decodeJSON(t, `{"a": []}`))
+
+	if len(ops) != 1 || ops[0].Op != jsonpatch.OpReplace || ops[0].Path != "/a" {
+		t.Errorf("expected a single replace of /a, got %v", ops)
+	}
+}
+
+func TestJSONPatch_Apply_Success(t *testing.T) {
+	doc := decodeJSON(t, `{"foo": ["bar", "baz"], "qux": {"a": 1}}`)
+
+	ops := []jsonpatch.Operation{
+		{Op: jsonpatch.OpAdd, Path: "/foo/1", Value: "qux"},
+		{Op: jsonpatch.OpAdd, Path: "/foo/-", Value: "end"},
+		{Op: jsonpatch.OpRemove, Path: "/foo/0"},
+		{Op: jsonpatch.OpReplace, Path: "/qux/a", Value: float64(2)},
+		{Op: jsonpatch.OpCopy, From: "/qux", Path: "/copy"},
+		{Op: jsonpatch.OpMove, From: "/qux/a", Path: "/moved"},
+		{Op: jsonpatch.OpTest, Path: "/copy/a", Value: float64(2)},
+	}
+
+	result, err := jsonpatch.Apply(doc, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := decodeJSON(t, `{"foo": ["qux", "baz", "end"], "qux": {}, "copy": {"a": 2}, "moved": 2}`)
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if original := decodeJSON(t, `{"foo": ["bar", "baz"], "qux": {"a": 1}}`); !reflect.DeepEqual(doc, original) {
+		t.Errorf("the original document was modified: %v", doc)
+	}
+}
+
+func TestJSONPatch_Apply_Failure(t *testing.T) {
+	doc := decodeJSON(t, `{"list": [1, 2]}`)
+
+	cases := []struct {
+		op       jsonpatch.Operation
+		expected error
+	}{
+		{jsonpatch.Operation{Op: jsonpatch.OpRemove, Path: "/missing"}, jsonpatch.ErrPathNotFound},
+		{jsonpatch.Operation{Op: jsonpatch.OpReplace, Path: "/list/2", Value: 3}, jsonpatch.ErrPathNotFound},
+		{jsonpatch.Operation{Op: jsonpatch.OpAdd, Path: "/list/01", Value: 3}, jsonpatch.ErrInvalidPath},
+		{jsonpatch.Operation{Op: jsonpatch.OpAdd, Path: "list", Value: 3}, jsonpatch.ErrInvalidPath},
+		{jsonpatch.Operation{Op: jsonpatch.OpMove, From: "/list", Path: "/list/0"}, jsonpatch.ErrInvalidPath},
+		{jsonpatch.Operation{Op: jsonpatch.OpTest, Path: "/list/0", Value: float64(2)}, jsonpatch.ErrTestFailed},
+		{jsonpatch.Operation{Op: "unknown", Path: "/list"}, jsonpatch.ErrInvalidOperation},
+	}
+
+	for _, c := range cases {
+		ops := []jsonpatch.Operation{{Op: jsonpatch.OpAdd, Path: "/added", Value: true}, c.op}
+
+		_, err := jsonpatch.Apply(doc, ops)
+
+		var patchErr *jsonpatch.Error
+		if !errors.As(err, &patchErr) || !errors.Is(err, c.expected) || patchErr.Index != 1 {
+			t.Errorf("%v: expected %v at operation 1, got %v", c.op, c.expected, err)
+		}
+	}
+
+	if _, ok := doc.(map[string]any)["added"]; ok {
+		t.Error("a failed patch modified the original document")
+	}
+}
+
+func TestJSONPatch_MergePatch(t *testing.T) {
+	// Example from RFC 7396 section 3
+	doc := decodeJSON(t, `{"title": "Goodbye!", "author": {"givenName": "John", "familyName": "Doe"}, "tags": ["example", "sample"], "content": "This will be unchanged"}`)
+	patch := decodeJSON(t, `{"title": "Hello!", "phoneNumber": "+01-123-456-7890", "author": {"familyName": null}, "tags": ["example"]}`)
+
+	expected := decodeJSON(t, `{"title": "Hello!", "author": {"givenName": "John"}, "tags": ["example"], "content": "This will be unchanged", "phoneNumber": "+01-123-456-7890"}`)
+
+	if result := jsonpatch.MergePatch(doc, patch); !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if _, ok := doc.(map[string]any)["author"].(map[string]any)["familyName"]; !ok {
+		t.Error("the original document was modified")
+	}
+}
+
+func TestThreeWayMerge(t *testing.T) {
+	base := decodeJSON(t, `{"title": "Room", "width": 10, "items": {"sofa": {"x": 1}, "lamp": {"x": 2}}}`)
+	ours := decodeJSON(t, `{"title": "My room", "width": 10, "items": {"sofa": {"x": 1}}}`)
+	theirs := decodeJSON(t, `{"title": "Room", "width": 12, "items": {"sofa": {"x": 5}, "lamp": {"x": 3}}}`)
+
+	merged, skipped := jsonpatch.ThreeWayMerge(base, ours, theirs)
+
+	expected := decodeJSON(t, `{"title": "My room", "width": 12, "items": {"sofa": {"x": 5}}}`)
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("expected %v, got %v", expected, merged)
+	}
+
+	if len(skipped) != 1 || skipped[0].Path != "/items/lamp/x" {
+		t.Fatalf("expected the lamp change to be skipped, got %+v", skipped)
+	}
+
+	if ours.(map[string]any)["width"] != float64(10) {
+		t.Fatal("ours was modified")
+	}
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func TestJWT_Multi_Success(t *testing.T) {
-	tokenString, err := utils.GenerateJWT(1)
+	tokenString, err := utils.GenerateJWT(1, 2)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -17,7 +17,7 @@ func TestJWT_Multi_Success(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if claims.Subject != "1" {
+	if claims.Subject != "1" || claims.Id != "2" {
 		t.Fail()
 	}
 }
@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/translations"
+)
+
+func TestOrganizationRoleAllow(t *testing.T) {
+	everything := dto.Allow{
+		View:    true,
+		Edit:    true,
+		Delete:  true,
+		Publish: true,
+		Share:   true,
+		Manage:  dto.AllowManage{Users: true, Metadata: true},
+	}
+
+	for _, role := range []string{dto.OrganizationRoleOwner, dto.OrganizationRoleAdmin} {
+		if !dto.OrganizationRoleAllow(role).Covers(everything) {
+			t.Fatalf("%s should be allowed to do everything", role)
+		}
+
+		if !dto.IsOrganizationManager(role) {
+			t.Fatalf("%s should manage the organization", role)
+		}
+	}
+
+	member := dto.OrganizationRoleAllow(dto.OrganizationRoleMember)
+	if !member.Covers(dto.Allow{View: true, Edit: true}) {
+		t.Fatal("members should view and edit")
+	}
+
+	if member.Covers(dto.Allow{Delete: true}) || member.Covers(dto.Allow{Manage: dto.AllowManage{Users: true}}) {
+		t.Fatal("members should not delete or manage users")
+	}
+
+	if dto.IsOrganizationManager(dto.OrganizationRoleMember) {
+		t.Fatal("members should not manage the organization")
+	}
+
+	if !dto.OrganizationRoleAllow("").IsEmpty() {
+		t.Fatal("non members should get nothing")
+	}
+}
+
+func createTestOrganization(t *testing.T, ownerID uint) uint {
+	t.Helper()
+
+	organizationID, err := service.Organization.Create(&dto.OrganizationCreation{Name: fmt.Sprintf("org-%d", ownerID), DisplayName: "Test organization"}, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		service.Organization.Delete(organizationID, ownerID)
+	})
+
+	return organizationID
+}
+
+// A context as the middlewares see it once the user is authenticated and the project or component was looked up
+func lookupContext(user *model.User, key string, value any) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Keys = map[string]any{
+		"lang":      translations.Translation{},
+		"auth_user": &dto.UserProfile{ID: user.ID, Username: user.Username},
+		key:         value,
+	}
+
+	return ctx
+}
+
+// Whether the middleware lets the request through
+func passes(handler gin.HandlerFunc, ctx *gin.Context) bool {
+	handler(ctx)
+	return !ctx.IsAborted()
+}
+
+func TestOrganizationOwnsItsProjects(t *testing.T) {
+	setupPostgres(t)
+	service.Init()
+
+	owner := createTestUser(t, 0)
+	creator := createTestUser(t, 0)
+	organizationID := createTestOrganization(t, owner.ID)
+
+	if err := service.Organization.SetMember(organizationID, creator.ID, dto.OrganizationRoleMember); err != nil {
+		t.Fatal(err)
+	}
+
+	project := &dto.ProjectInfo{OwnerID: creator.ID, OwnerUsername: creator.Username, OrganizationID: &organizationID}
+
+	if !middleware.HasProjectPermissions(lookupContext(creator, "project_lookup", project), dto.Allow{View: true, Edit: true}) {
+		t.Fatal("the creator should view and edit as a member")
+	}
+
+	if middleware.HasProjectPermissions(lookupContext(creator, "project_lookup", project), dto.Allow{Delete: true}) {
+		t.Fatal("the creator should not delete a project of the organization as a member")
+	}
+
+	if passes(middleware.ProjectOwnership, lookupContext(creator, "project_lookup", project)) {
+		t.Fatal("the creator should not own a project of the organization")
+	}
+
+	if !passes(middleware.ProjectOwnership, lookupContext(owner, "project_lookup", project)) {
+		t.Fatal("owners of the organization should own its projects")
+	}
+
+	if err := service.Organization.RemoveMember(organizationID, creator.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if middleware.HasProjectPermissions(lookupContext(creator, "project_lookup", project), dto.Allow{View: true}) {
+		t.Fatal("the creator should lose access once removed from the organization")
+	}
+
+	personal := &dto.ProjectInfo{OwnerID: creator.ID, OwnerUsername: creator.Username}
+	if !middleware.HasProjectPermissions(lookupContext(creator, "project_lookup", personal), dto.Allow{Delete: true}) {
+		t.Fatal("owners of personal projects should be allowed to do everything")
+	}
+}
+
+func TestOrganizationOwnsItsComponents(t *testing.T) {
+	setupPostgres(t)
+	service.Init()
+
+	owner := createTestUser(t, 0)
+	creator := createTestUser(t, 0)
+	organizationID := createTestOrganization(t, owner.ID)
+
+	if err := service.Organization.SetMember(organizationID, creator.ID, dto.OrganizationRoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	component := &dto.ComponentInfo{OwnerID: creator.ID, OwnerUsername: creator.Username, OrganizationID: &organizationID}
+
+	if !passes(middleware.ComponentOwnership, lookupContext(creator, "component_lookup", component)) {
+		t.Fatal("admins of the organization should manage its components")
+	}
+
+	if err := service.Organization.RemoveMember(organizationID, creator.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if passes(middleware.ComponentOwnership, lookupContext(creator, "component_lookup", component)) {
+		t.Fatal("the creator should stop managing the component once removed from the organization")
+	}
+
+	if passes(middleware.ComponentTransferOwnership, lookupContext(creator, "component_lookup", component)) {
+		t.Fatal("the creator should not be able to transfer the component once removed from the organization")
+	}
+
+	if !passes(middleware.ComponentOwnership, lookupContext(owner, "component_lookup", component)) {
+		t.Fatal("owners of the organization should manage its components")
+	}
+}
+
+func TestDeletingMembersKeepsOrganizationProjects(t *testing.T) {
+	setupPostgres(t)
+	service.Init()
+
+	owner := createTestUser(t, 0)
+	creator := createTestUser(t, 0)
+	organizationID := createTestOrganization(t, owner.ID)
+	projectID := createTestProject(t, creator.ID)
+
+	if err := service.Organization.SetMember(organizationID, creator.ID, dto.OrganizationRoleMember); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Postgres.Model(&model.ProjectOwner{}).Where("project_id = ?", projectID).Update("organization_id", organizationID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.User.DeleteUser(owner.ID); !errors.Is(err, repository.ErrOrganizationLastOwner) {
+		t.Fatalf("expected the last owner to be kept from deleting their account, got %v", err)
+	}
+
+	if err := service.User.DeleteUser(creator.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := projectOwnerOf(t, projectID); got.UserID != nil || got.OrganizationID == nil || *got.OrganizationID != organizationID {
+		t.Fatalf("expected the project to stay with the organization, got %+v", got)
+	}
+
+	if role, err := service.Organization.GetRole(organizationID, creator.ID); err != nil || role != "" {
+		t.Fatalf("expected the membership to be gone, got %q (%v)", role, err)
+	}
+}
+
+func TestDeletingOrganizationPaysOutItsWallet(t *testing.T) {
+	setupPostgres(t)
+	service.Init()
+
+	owner := createTestUser(t, 100)
+
+	organizationID, err := service.Organization.Create(&dto.OrganizationCreation{Name: fmt.Sprintf("org-%d", owner.ID), DisplayName: "Test organization"}, owner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Organization.Deposit(organizationID, owner.ID, 40); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.Organization.Delete(organizationID, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if balance := balanceOf(t, owner.ID); balance != 100 {
+		t.Fatalf("expected the wallet of the organization to go back to the owner, got %d", balance)
+	}
+
+	assertLedger(t, owner)
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/pkg/utils"
+)
+
+func TestProjectRolePresets(t *testing.T) {
+	previous := dto.Allow{}
+
+	// Every preset allows at least what the one before it does
+	for _, name := range dto.ProjectRolePresets {
+		allow, ok := dto.ProjectRolePreset(name)
+		if !ok {
+			t.Fatalf("%s should be a preset", name)
+		}
+
+		if allow.IsEmpty() || !allow.Covers(previous) {
+			t.Fatalf("%s should allow more than the preset before it", name)
+		}
+
+		previous = allow
+	}
+
+	if _, ok := dto.ProjectRolePreset("owner"); ok {
+		t.Fatal("owner should not be a preset")
+	}
+}
+
+func TestProjectRoleAssign(t *testing.T) {
+	allow, _ := dto.ProjectRolePreset(dto.ProjectRoleEditor)
+
+	assign := allow.Assign(dto.ProjectRoleEditor)
+	if assign.RoleName() != dto.ProjectRoleEditor {
+		t.Fatalf("expected role %q, got %q", dto.ProjectRoleEditor, assign.RoleName())
+	}
+
+	// Flags the role does not allow are sent as false so they get revoked
+	if assign.Delete == nil || *assign.Delete {
+		t.Fatal("delete should be explicitly revoked")
+	}
+
+	if assign.Allow() != allow {
+		t.Fatalf("assignment should grant %+v, got %+v", allow, assign.Allow())
+	}
+}
+
+func TestProjectRoleUpdateApply(t *testing.T) {
+	current := dto.Allow{View: true, Edit: true}
+
+	updated := dto.ProjectRoleUpdate{Edit: utils.ToPtr(false), Publish: utils.ToPtr(true)}.Apply(current)
+	if updated != (dto.Allow{View: true, Publish: true}) {
+		t.Fatalf("unexpected permissions %+v", updated)
+	}
+
+	if (dto.ProjectRoleUpdate{}).Apply(current) != current {
+		t.Fatal("an empty update should keep the permissions")
+	}
+}
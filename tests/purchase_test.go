@@ -0,0 +1,307 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/swibly/swibly-api/config"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/utils"
+)
+
+// These tests hammer purchases, sales and refunds against a real database. They only run when
+// POSTGRES_CONNECTION_STRING points to one, e.g. the one from docker-compose.yml.
+
+var postgresOnce sync.Once
+
+func setupPostgres(t *testing.T) repository.ComponentRepository {
+	t.Helper()
+
+	if os.Getenv("POSTGRES_CONNECTION_STRING") == "" {
+		t.Skip("POSTGRES_CONNECTION_STRING is not set")
+	}
+
+	postgresOnce.Do(func() {
+		// Config files are read relative to the root of the repository
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Chdir(".."); err != nil {
+			t.Fatal(err)
+		}
+
+		config.Parse()
+
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+
+		db.Load()
+	})
+
+	return repository.NewComponentRepository(repository.NewUserRepository())
+}
+
+func createTestUser(t *testing.T, arkhoin uint64) *model.User {
+	t.Helper()
+
+	token, err := utils.RandomToken(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := &model.User{
+		FirstName: "Test",
+		LastName:  "User",
+		Username:  "test-" + token,
+		Email:     fmt.Sprintf("test-%s@swibly.test", token),
+		Arkhoin:   arkhoin,
+	}
+
+	if err := repository.NewUserRepository().Create(user); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Postgres.Where("user_id = ? OR counterparty_user_id = ?", user.ID, user.ID).Delete(&model.ArkhoinTransaction{})
+		db.Postgres.Where("user_id = ?", user.ID).Delete(&model.ComponentHolder{})
+		db.Postgres.Where("id = ?", user.ID).Delete(&model.User{})
+	})
+
+	return user
+}
+
+func createTestComponent(t *testing.T, ownerID uint, price int) uint {
+	t.Helper()
+
+	component := &model.Component{Name: "Test component", Content: "{}", Price: price}
+	if err := db.Postgres.Create(component).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Postgres.Create(&model.ComponentOwner{ComponentID: component.ID, UserID: &ownerID}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Postgres.Where("component_id = ?", component.ID).Delete(&model.ComponentOwner{})
+		db.Postgres.Unscoped().Where("id = ?", component.ID).Delete(&model.Component{})
+	})
+
+	return component.ID
+}
+
+func balanceOf(t *testing.T, userID uint) uint64 {
+	t.Helper()
+
+	var user model.User
+	if err := db.Postgres.Where("id = ?", userID).First(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return user.Arkhoin
+}
+
+// The stored balance must always be the sum of the ledger entries of the wallet
+func assertLedger(t *testing.T, users ...*model.User) {
+	t.Helper()
+
+	for _, user := range users {
+		var total int64
+		if err := db.Postgres.Model(&model.ArkhoinTransaction{}).
+			Where("user_id = ? AND organization_id IS NULL", user.ID).
+			Select("COALESCE(SUM(amount), 0)").
+			Scan(&total).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		if balance := balanceOf(t, user.ID); int64(balance) != total {
+			t.Fatalf("balance of %s is %d but the ledger adds up to %d", user.Username, balance, total)
+		}
+	}
+}
+
+// Runs fn n times at once and returns the error of every call
+func hammer(n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = fn(i)
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	return errs
+}
+
+func countSuccesses(t *testing.T, errs []error, allowed ...error) int {
+	t.Helper()
+
+	successes := 0
+
+outer:
+	for _, err := range errs {
+		if err == nil {
+			successes++
+			continue
+		}
+
+		for _, a := range allowed {
+			if errors.Is(err, a) {
+				continue outer
+			}
+		}
+
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return successes
+}
+
+func TestConcurrentPurchasesChargeOnce(t *testing.T) {
+	cr := setupPostgres(t)
+
+	owner := createTestUser(t, 1000)
+	buyer := createTestUser(t, 1000)
+	componentID := createTestComponent(t, owner.ID, 100)
+
+	errs := hammer(20, func(int) error { return cr.Buy(buyer.ID, componentID, nil, nil) })
+
+	if successes := countSuccesses(t, errs, repository.ErrComponentAlreadyOwned); successes != 1 {
+		t.Fatalf("expected exactly one purchase, got %d", successes)
+	}
+
+	if balance := balanceOf(t, buyer.ID); balance != 900 {
+		t.Fatalf("buyer should have 900 left, has %d", balance)
+	}
+
+	if balance := balanceOf(t, owner.ID); balance != 1100 {
+		t.Fatalf("owner should have 1100, has %d", balance)
+	}
+
+	assertLedger(t, owner, buyer)
+}
+
+func TestConcurrentPurchasesCannotOverspend(t *testing.T) {
+	cr := setupPostgres(t)
+
+	owner := createTestUser(t, 1000)
+	buyer := createTestUser(t, 1000)
+
+	components := make([]uint, 10)
+	for i := range components {
+		components[i] = createTestComponent(t, owner.ID, 300)
+	}
+
+	errs := hammer(len(components), func(i int) error { return cr.Buy(buyer.ID, components[i], nil, nil) })
+
+	if successes := countSuccesses(t, errs, repository.ErrInsufficientArkhoins); successes != 3 {
+		t.Fatalf("1000 Arkhoins should buy exactly 3 components of 300, bought %d", successes)
+	}
+
+	if balance := balanceOf(t, buyer.ID); balance != 100 {
+		t.Fatalf("buyer should have 100 left, has %d", balance)
+	}
+
+	assertLedger(t, owner, buyer)
+}
+
+func TestConcurrentSalesRefundOnce(t *testing.T) {
+	cr := setupPostgres(t)
+
+	owner := createTestUser(t, 1000)
+	holder := createTestUser(t, 1000)
+	componentID := createTestComponent(t, owner.ID, 250)
+
+	if err := cr.Buy(holder.ID, componentID, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := hammer(20, func(int) error { return cr.Sell(holder.ID, componentID, nil) })
+
+	if successes := countSuccesses(t, errs, repository.ErrComponentNotOwned); successes != 1 {
+		t.Fatalf("expected exactly one sale, got %d", successes)
+	}
+
+	if balance := balanceOf(t, holder.ID); balance != 1000 {
+		t.Fatalf("holder should be back to 1000, has %d", balance)
+	}
+
+	assertLedger(t, owner, holder)
+}
+
+func TestIdempotentPurchaseRetries(t *testing.T) {
+	cr := setupPostgres(t)
+
+	owner := createTestUser(t, 1000)
+	buyer := createTestUser(t, 1000)
+	componentID := createTestComponent(t, owner.ID, 100)
+
+	key := "retry-" + buyer.Username
+
+	errs := hammer(10, func(int) error { return cr.Buy(buyer.ID, componentID, nil, &key) })
+
+	if successes := countSuccesses(t, errs, repository.ErrAlreadyProcessed); successes != 1 {
+		t.Fatalf("expected exactly one purchase, got %d", successes)
+	}
+
+	if balance := balanceOf(t, buyer.ID); balance != 900 {
+		t.Fatalf("buyer should have been charged once, has %d", balance)
+	}
+
+	if err := cr.Sell(buyer.ID, componentID, &key); !errors.Is(err, repository.ErrIdempotencyKeyReused) {
+		t.Fatalf("selling with the key of the purchase should fail, got %v", err)
+	}
+
+	assertLedger(t, owner, buyer)
+}
+
+func TestRefundNeverUnderflows(t *testing.T) {
+	cr := setupPostgres(t)
+
+	owner := createTestUser(t, 1)
+	buyer := createTestUser(t, 1000)
+	seller := createTestUser(t, 1000)
+
+	componentID := createTestComponent(t, owner.ID, 500)
+	otherID := createTestComponent(t, seller.ID, 500)
+
+	if err := cr.Buy(buyer.ID, componentID, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The owner spends what the sale earned them
+	if err := cr.Buy(owner.ID, otherID, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cr.SafeDelete(componentID); err != nil {
+		t.Fatal(err)
+	}
+
+	if balance := balanceOf(t, owner.ID); balance != 0 {
+		t.Fatalf("owner should be left with 0, has %d", balance)
+	}
+
+	if balance := balanceOf(t, buyer.ID); balance != 1000 {
+		t.Fatalf("buyer should be refunded in full, has %d", balance)
+	}
+
+	assertLedger(t, owner, buyer, seller)
+}
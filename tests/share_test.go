@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/model/dto"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/internal/service/usecase"
+	"github.com/swibly/swibly-api/pkg/db"
+	"github.com/swibly/swibly-api/pkg/middleware"
+	"github.com/swibly/swibly-api/pkg/utils"
+	"github.com/swibly/swibly-api/translations"
+)
+
+func TestShareLinkScopes(t *testing.T) {
+	view := dto.ProjectShareLinkInfo{Scope: dto.ShareScopeView}.Allow()
+	edit := dto.ProjectShareLinkInfo{Scope: dto.ShareScopeEdit}.Allow()
+	unknown := dto.ProjectShareLinkInfo{Scope: "admin"}.Allow()
+
+	cases := []struct {
+		name     string
+		allow    dto.Allow
+		required dto.Allow
+		expected bool
+	}{
+		{"view link can view", view, dto.Allow{View: true}, true},
+		{"view link cannot edit", view, dto.Allow{Edit: true}, false},
+		{"edit link can view", edit, dto.Allow{View: true}, true},
+		{"edit link can edit", edit, dto.Allow{Edit: true}, true},
+		{"edit link cannot share", edit, dto.Allow{Share: true}, false},
+		{"edit link cannot manage", edit, dto.Allow{Manage: dto.AllowManage{Users: true}}, false},
+		{"unknown scope grants nothing", unknown, dto.Allow{View: true}, false},
+	}
+
+	for _, c := range cases {
+		if got := c.allow.Covers(c.required); got != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
+
+// Like the purchase tests, the ones below only run when POSTGRES_CONNECTION_STRING points to a database
+
+func createTestShareLink(t *testing.T, projectID uint, maxUses uint, expiresAt time.Time) string {
+	t.Helper()
+
+	token, err := utils.RandomToken(24)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link := &model.ProjectShareLink{
+		ProjectID: projectID,
+		Token:     utils.HashToken(token),
+		Scope:     dto.ShareScopeView,
+		ExpiresAt: expiresAt,
+		MaxUses:   maxUses,
+	}
+
+	if err := db.Postgres.Create(link).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Postgres.Where("link_id = ?", link.ID).Delete(&model.ProjectShareLinkRedemption{})
+		db.Postgres.Where("id = ?", link.ID).Delete(&model.ProjectShareLink{})
+	})
+
+	return token
+}
+
+func TestShareLinkUsesCountOncePerUser(t *testing.T) {
+	setupPostgres(t)
+	shares := usecase.NewProjectShareUseCase()
+
+	owner := createTestUser(t, 0)
+	first := createTestUser(t, 0)
+	second := createTestUser(t, 0)
+	third := createTestUser(t, 0)
+	projectID := createTestProject(t, owner.ID)
+	token := createTestShareLink(t, projectID, 2, time.Now().Add(time.Hour))
+
+	for i := 0; i < 3; i++ {
+		if _, err := shares.Use(projectID, first.ID, token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	link, err := shares.Use(projectID, second.ID, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if link.Uses != 2 {
+		t.Fatalf("expected each user to count once, got %d uses", link.Uses)
+	}
+
+	if _, err := shares.Use(projectID, third.ID, token); !errors.Is(err, repository.ErrShareLinkInvalid) {
+		t.Fatalf("expected the link to run out of uses, got %v", err)
+	}
+
+	if _, err := shares.Use(projectID, first.ID, token); err != nil {
+		t.Fatalf("expected users who used the link to keep using it, got %v", err)
+	}
+}
+
+func TestShareLinkExpiry(t *testing.T) {
+	setupPostgres(t)
+	shares := usecase.NewProjectShareUseCase()
+
+	owner := createTestUser(t, 0)
+	user := createTestUser(t, 0)
+	projectID := createTestProject(t, owner.ID)
+	token := createTestShareLink(t, projectID, 0, time.Now().Add(time.Hour))
+
+	if _, err := shares.Use(projectID, user.ID, token); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Postgres.Model(&model.ProjectShareLink{}).Where("token = ?", utils.HashToken(token)).Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := shares.Use(projectID, user.ID, token); !errors.Is(err, repository.ErrShareLinkInvalid) {
+		t.Fatalf("expected an expired link to stop working, got %v", err)
+	}
+
+	if _, err := shares.Use(projectID+1, user.ID, token); !errors.Is(err, repository.ErrShareLinkInvalid) {
+		t.Fatalf("expected the link to only work for its project, got %v", err)
+	}
+}
+
+func TestShareLinkIgnoredForMembers(t *testing.T) {
+	setupPostgres(t)
+	service.Init()
+
+	owner := createTestUser(t, 0)
+	stranger := createTestUser(t, 0)
+	projectID := createTestProject(t, owner.ID)
+
+	lookup := func(user *model.User) int {
+		router := gin.New()
+		router.GET("/:id", func(ctx *gin.Context) {
+			ctx.Set("lang", translations.Translation{})
+			ctx.Set("auth_user", &dto.UserProfile{ID: user.ID, Username: user.Username})
+		}, middleware.ProjectLookup, func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%d", projectID), nil)
+		request.Header.Set("X-Share-Token", "not-a-link")
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		return recorder.Code
+	}
+
+	if code := lookup(owner); code != http.StatusOK {
+		t.Fatalf("expected the owner to get through with an invalid link, got %d", code)
+	}
+
+	if code := lookup(stranger); code != http.StatusForbidden {
+		t.Fatalf("expected an invalid link to be refused to others, got %d", code)
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swibly/swibly-api/pkg/utils"
+)
+
+// Test vectors from RFC 6238 appendix B (SHA1), truncated to 6 digits
+var totpVectors = []struct {
+	time int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+// base32 of the ASCII secret "12345678901234567890"
+const totpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_RFC6238_Success(t *testing.T) {
+	for _, vector := range totpVectors {
+		code, err := utils.TOTPCode(totpSecret, utils.TOTPStep(time.Unix(vector.time, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if code != vector.code {
+			t.Errorf("at %d: expected %s, got %s", vector.time, vector.code, code)
+		}
+	}
+}
+
+func TestTOTP_Validate_Skew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	previous, err := utils.TOTPCode(totpSecret, utils.TOTPStep(now)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if step, ok := utils.ValidateTOTP(totpSecret, previous, now); !ok || step != utils.TOTPStep(now)-1 {
+		t.Fail()
+	}
+
+	old, err := utils.TOTPCode(totpSecret, utils.TOTPStep(now)-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := utils.ValidateTOTP(totpSecret, old, now); ok {
+		t.Fail()
+	}
+}
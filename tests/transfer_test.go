@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/swibly/swibly-api/internal/model"
+	"github.com/swibly/swibly-api/internal/service"
+	"github.com/swibly/swibly-api/internal/service/repository"
+	"github.com/swibly/swibly-api/internal/service/usecase"
+	"github.com/swibly/swibly-api/pkg/db"
+)
+
+// Like the purchase tests, these only run when POSTGRES_CONNECTION_STRING points to a database
+
+func createTestProject(t *testing.T, ownerID uint) uint {
+	t.Helper()
+
+	project := &model.Project{Name: "Test project", Content: "{}"}
+	if err := db.Postgres.Create(project).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Postgres.Create(&model.ProjectOwner{ProjectID: project.ID, UserID: &ownerID}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		db.Postgres.Where("project_id = ?", project.ID).Delete(&model.ProjectTransfer{})
+		db.Postgres.Where("project_id = ?", project.ID).Delete(&model.ProjectUserPermission{})
+		db.Postgres.Where("project_id = ?", project.ID).Delete(&model.ProjectOwner{})
+		db.Postgres.Unscoped().Where("id = ?", project.ID).Delete(&model.Project{})
+	})
+
+	return project.ID
+}
+
+func projectOwnerOf(t *testing.T, projectID uint) model.ProjectOwner {
+	t.Helper()
+
+	var owner model.ProjectOwner
+	if err := db.Postgres.Where("project_id = ?", projectID).First(&owner).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return owner
+}
+
+func componentOwnerOf(t *testing.T, componentID uint) model.ComponentOwner {
+	t.Helper()
+
+	var owner model.ComponentOwner
+	if err := db.Postgres.Where("component_id = ?", componentID).First(&owner).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return owner
+}
+
+func TestProjectTransferAccept(t *testing.T) {
+	setupPostgres(t)
+	transfers := usecase.NewProjectTransferUseCase()
+
+	owner := createTestUser(t, 0)
+	recipient := createTestUser(t, 0)
+	projectID := createTestProject(t, owner.ID)
+
+	if err := transfers.Start(projectID, owner.ID, recipient.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := transfers.GetByRecipient(recipient.ID, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pending.Data) != 1 || pending.Data[0].ProjectID != projectID || pending.Data[0].OwnerID != owner.ID {
+		t.Fatalf("expected the transfer to be waiting for the recipient, got %+v", pending.Data)
+	}
+
+	// Only the recipient can answer the transfer
+	if _, err := transfers.Accept(owner.ID, pending.Data[0].ID); !errors.Is(err, repository.ErrProjectTransferNotFound) {
+		t.Fatalf("expected the owner to be unable to accept, got %v", err)
+	}
+
+	transfer, err := transfers.Accept(recipient.ID, pending.Data[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if transfer.OwnerID != owner.ID {
+		t.Fatalf("expected the previous owner to be reported, got %d", transfer.OwnerID)
+	}
+
+	if got := projectOwnerOf(t, projectID).UserID; got == nil || *got != recipient.ID {
+		t.Fatalf("expected the recipient to own the project, got %v", got)
+	}
+
+	var permission model.ProjectUserPermission
+	if err := db.Postgres.Where("project_id = ? AND user_id = ?", projectID, owner.ID).First(&permission).Error; err != nil {
+		t.Fatalf("expected the previous owner to keep access: %v", err)
+	}
+
+	if !permission.Allow.Edit || !permission.Allow.Delete || !permission.Allow.Manage.Users {
+		t.Fatalf("expected the previous owner to keep every permission, got %+v", permission.Allow)
+	}
+
+	if _, err := transfers.Get(projectID); !errors.Is(err, repository.ErrProjectTransferNotFound) {
+		t.Fatalf("expected the transfer to be gone once accepted, got %v", err)
+	}
+}
+
+func TestProjectTransferDecline(t *testing.T) {
+	setupPostgres(t)
+	transfers := usecase.NewProjectTransferUseCase()
+
+	owner := createTestUser(t, 0)
+	recipient := createTestUser(t, 0)
+	projectID := createTestProject(t, owner.ID)
+
+	if err := transfers.Start(projectID, owner.ID, recipient.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := transfers.Get(projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transfers.Decline(recipient.ID, pending.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := projectOwnerOf(t, projectID).UserID; got == nil || *got != owner.ID {
+		t.Fatalf("expected the owner to keep the project, got %v", got)
+	}
+
+	if _, err := transfers.Accept(recipient.ID, pending.ID); !errors.Is(err, repository.ErrProjectTransferNotFound) {
+		t.Fatalf("expected a declined transfer to be impossible to accept, got %v", err)
+	}
+}
+
+func TestProjectTransferLeavesOrganization(t *testing.T) {
+	setupPostgres(t)
+	service.Init()
+	transfers := usecase.NewProjectTransferUseCase()
+
+	owner := createTestUser(t, 0)
+	recipient := createTestUser(t, 0)
+	organizationID := createTestOrganization(t, owner.ID)
+	projectID := createTestProject(t, owner.ID)
+
+	if err := db.Postgres.Model(&model.ProjectOwner{}).Where("project_id = ?", projectID).Update("organization_id", organizationID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := transfers.Start(projectID, owner.ID, recipient.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := transfers.Get(projectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transfers.Accept(recipient.ID, pending.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := projectOwnerOf(t, projectID); got.UserID == nil || *got.UserID != recipient.ID || got.OrganizationID != nil {
+		t.Fatalf("expected the recipient to own the project instead of the organization, got %+v", got)
+	}
+}
+
+func TestComponentTransferAccept(t *testing.T) {
+	setupPostgres(t)
+	transfers := usecase.NewComponentTransferUseCase()
+
+	owner := createTestUser(t, 0)
+	recipient := createTestUser(t, 0)
+	componentID := createTestComponent(t, owner.ID, 0)
+	t.Cleanup(func() {
+		db.Postgres.Where("component_id = ?", componentID).Delete(&model.ComponentTransfer{})
+	})
+
+	if err := transfers.Start(componentID, owner.ID, recipient.ID, true); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := transfers.Get(componentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pending.OwnerID != owner.ID || pending.RecipientID != recipient.ID {
+		t.Fatalf("expected the transfer to go from the owner to the recipient, got %+v", pending)
+	}
+
+	if _, err := transfers.Accept(recipient.ID, pending.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := componentOwnerOf(t, componentID).UserID; got == nil || *got != recipient.ID {
+		t.Fatalf("expected the recipient to own the component, got %v", got)
+	}
+
+	var holders int64
+	if err := db.Postgres.Model(&model.ComponentHolder{}).Where("component_id = ? AND user_id = ?", componentID, owner.ID).Count(&holders).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if holders != 1 {
+		t.Fatalf("expected the previous owner to keep holding the component, got %d holders", holders)
+	}
+}
+
+func TestComponentTransferCancel(t *testing.T) {
+	setupPostgres(t)
+	transfers := usecase.NewComponentTransferUseCase()
+
+	owner := createTestUser(t, 0)
+	recipient := createTestUser(t, 0)
+	componentID := createTestComponent(t, owner.ID, 0)
+	t.Cleanup(func() {
+		db.Postgres.Where("component_id = ?", componentID).Delete(&model.ComponentTransfer{})
+	})
+
+	if err := transfers.Start(componentID, owner.ID, recipient.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := transfers.Get(componentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := transfers.Cancel(componentID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transfers.Accept(recipient.ID, pending.ID); !errors.Is(err, repository.ErrComponentTransferNotFound) {
+		t.Fatalf("expected a canceled transfer to be impossible to accept, got %v", err)
+	}
+
+	if got := componentOwnerOf(t, componentID).UserID; got == nil || *got != owner.ID {
+		t.Fatalf("expected the owner to keep the component, got %v", got)
+	}
+}
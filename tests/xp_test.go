@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/swibly/swibly-api/internal/model/dto"
+)
+
+func TestLevels(t *testing.T) {
+	thresholds := []uint64{0, 100, 250}
+
+	cases := []struct {
+		xp       uint64
+		level    int
+		current  uint64
+		next     uint64
+		progress float64
+	}{
+		{0, 1, 0, 100, 0},
+		{50, 1, 0, 100, 0.5},
+		{100, 2, 100, 250, 0},
+		{249, 2, 100, 250, 149.0 / 150.0},
+		{250, 3, 250, 450, 0},
+		// Past the last threshold, every level takes the same step
+		{700, 5, 650, 850, 0.25},
+	}
+
+	for _, c := range cases {
+		level := dto.NewLevel(c.xp, thresholds, 200)
+
+		if level.Level != c.level || level.Current != c.current || level.Next != c.next || level.Progress != c.progress {
+			t.Errorf("%d XP: expected level %d (%d-%d, %v), got %+v", c.xp, c.level, c.current, c.next, c.progress, level)
+		}
+	}
+}
+
+func TestLevelsWithoutThresholds(t *testing.T) {
+	if level := dto.NewLevel(1000, nil, 300); level.Level != 4 || level.Current != 900 || level.Next != 1200 {
+		t.Fatalf("expected level 4, got %+v", level)
+	}
+}
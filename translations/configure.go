@@ -14,16 +14,25 @@ type Translation struct {
 	Hello                   string `yaml:"hello"`
 	InvalidAPIKey           string `yaml:"invalid_api_key"`
 	MaximumAPIKey           string `yaml:"maximum_api_key"`
+	APIKeyRateLimited       string `yaml:"api_key_rate_limited"`
+	APIKeyExpired           string `yaml:"api_key_expired"`
 	RequirePermissionAPIKey string `yaml:"require_permission_api_key"`
 
-	CategoryAuth      string `yaml:"category_auth"`
-	CategoryFollowers string `yaml:"category_followers"`
-	CategoryProject   string `yaml:"category_project"`
-	CategoryComponent string `yaml:"category_component"`
+	CategoryAuth         string `yaml:"category_auth"`
+	CategoryFollowers    string `yaml:"category_followers"`
+	CategoryProject      string `yaml:"category_project"`
+	CategoryComponent    string `yaml:"category_component"`
+	CategoryOrganization string `yaml:"category_organization"`
+	CategoryWallet       string `yaml:"category_wallet"`
+	CategoryLevel        string `yaml:"category_level"`
+	CategoryAchievement  string `yaml:"category_achievement"`
 
 	InternalServerError string `yaml:"internal_server_error"`
 	Unauthorized        string `yaml:"unauthorized"`
 	InvalidBody         string `yaml:"invalid_body"`
+	ContentInvalid      string `yaml:"content_invalid"`
+	RateLimited         string `yaml:"rate_limited"`
+	AccountLocked       string `yaml:"account_locked"`
 
 	NoAPIKeyFound   string `yaml:"no_api_key_found"` // Used in queries for getting the permissions of keys
 	APIKeyDestroyed string `yaml:"api_key_destroyed"`
@@ -33,27 +42,63 @@ type Translation struct {
 	AuthUserDeleted      string `yaml:"auth_user_deleted"`
 	AuthUserUpdated      string `yaml:"auth_user_updated"`
 	AuthWrongCredentials string `yaml:"auth_wrong_credentials"`
+	AuthLoggedOut        string `yaml:"auth_logged_out"`
 
-	NotificationWelcomeUserRegister        string `yaml:"notification_welcome_user_register"`
-	NotificationNewLoginDetected           string `yaml:"notification_new_login_detected"`
-	NotificationUserFollowedYou            string `yaml:"notification_user_followed_you"`
-	NotificationNewProjectCreated          string `yaml:"notification_new_project_created"`
-	NotificationUserClonedYourProject      string `yaml:"notification_user_cloned_your_project"`
-	NotificationYourProjectPublished       string `yaml:"notification_your_project_published"`
-	NotificationYourProjectFavorited       string `yaml:"notification_your_project_favorited"`
-	NotificationDeletedProjectFromTrash    string `yaml:"notification_deleted_project_from_trash"`
-	NotificationRestoredProjectFromTrash   string `yaml:"notification_restored_project_from_trash"`
-	NotificationAddedUserToProject         string `yaml:"notification_added_user_to_project"`
-	NotificationRemovedUserFromProject     string `yaml:"notification_removed_user_from_project"`
-	NotificationAddedYouToProject          string `yaml:"notification_added_you_to_project"`
-	NotificationRemovedYouFromProject      string `yaml:"notification_removed_you_from_project"`
-	NotificationUserLeftProject            string `yaml:"notification_user_left_project"`
-	NotificationNewComponentCreated        string `yaml:"notification_new_component_created"`
-	NotificationYourComponentPublished     string `yaml:"notification_your_component_published"`
-	NotificationDeletedComponentFromTrash  string `yaml:"notification_deleted_component_from_trash"`
-	NotificationRestoredComponentFromTrash string `yaml:"notification_restored_component_from_trash"`
-	NotificationYourComponentBought        string `yaml:"notification_your_component_bought"`
-	NotificationYouBoughtComponent         string `yaml:"notification_you_bought_component"`
+	AuthTwoFactorRequired string `yaml:"auth_two_factor_required"`
+	TOTPInvalidChallenge  string `yaml:"totp_invalid_challenge"`
+	TOTPInvalidCode       string `yaml:"totp_invalid_code"`
+	TOTPAlreadyEnabled    string `yaml:"totp_already_enabled"`
+	TOTPNotEnabled        string `yaml:"totp_not_enabled"`
+	TOTPEnabled           string `yaml:"totp_enabled"`
+	TOTPDisabled          string `yaml:"totp_disabled"`
+
+	InvalidRefreshToken string `yaml:"invalid_refresh_token"`
+	SessionNotFound     string `yaml:"session_not_found"`
+	SessionRevoked      string `yaml:"session_revoked"`
+	SessionsRevoked     string `yaml:"sessions_revoked"`
+
+	NotificationWelcomeUserRegister           string `yaml:"notification_welcome_user_register"`
+	NotificationNewLoginDetected              string `yaml:"notification_new_login_detected"`
+	NotificationUserFollowedYou               string `yaml:"notification_user_followed_you"`
+	NotificationNewProjectCreated             string `yaml:"notification_new_project_created"`
+	NotificationUserClonedYourProject         string `yaml:"notification_user_cloned_your_project"`
+	NotificationYourProjectPublished          string `yaml:"notification_your_project_published"`
+	NotificationYourProjectFavorited          string `yaml:"notification_your_project_favorited"`
+	NotificationDeletedProjectFromTrash       string `yaml:"notification_deleted_project_from_trash"`
+	NotificationRestoredProjectFromTrash      string `yaml:"notification_restored_project_from_trash"`
+	NotificationAddedUserToProject            string `yaml:"notification_added_user_to_project"`
+	NotificationRemovedUserFromProject        string `yaml:"notification_removed_user_from_project"`
+	NotificationAddedYouToProject             string `yaml:"notification_added_you_to_project"`
+	NotificationRemovedYouFromProject         string `yaml:"notification_removed_you_from_project"`
+	NotificationUserLeftProject               string `yaml:"notification_user_left_project"`
+	NotificationInvitedYouToProject           string `yaml:"notification_invited_you_to_project"`
+	NotificationUserDeclinedInvite            string `yaml:"notification_user_declined_invite"`
+	NotificationTransferProjectToYou          string `yaml:"notification_transfer_project_to_you"`
+	NotificationUserAcceptedProjectTransfer   string `yaml:"notification_user_accepted_project_transfer"`
+	NotificationYouOwnProject                 string `yaml:"notification_you_own_project"`
+	NotificationUserDeclinedProjectTransfer   string `yaml:"notification_user_declined_project_transfer"`
+	NotificationAddedYouToOrganization        string `yaml:"notification_added_you_to_organization"`
+	NotificationRemovedYouFromOrganization    string `yaml:"notification_removed_you_from_organization"`
+	NotificationNewComponentCreated           string `yaml:"notification_new_component_created"`
+	NotificationYourComponentPublished        string `yaml:"notification_your_component_published"`
+	NotificationDeletedComponentFromTrash     string `yaml:"notification_deleted_component_from_trash"`
+	NotificationRestoredComponentFromTrash    string `yaml:"notification_restored_component_from_trash"`
+	NotificationYourComponentBought           string `yaml:"notification_your_component_bought"`
+	NotificationYouBoughtComponent            string `yaml:"notification_you_bought_component"`
+	NotificationTransferComponentToYou        string `yaml:"notification_transfer_component_to_you"`
+	NotificationUserAcceptedComponentTransfer string `yaml:"notification_user_accepted_component_transfer"`
+	NotificationYouOwnComponent               string `yaml:"notification_you_own_component"`
+	NotificationArkhoinGranted                string `yaml:"notification_arkhoin_granted"`
+	NotificationArkhoinDeducted               string `yaml:"notification_arkhoin_deducted"`
+	NotificationXPGranted                     string `yaml:"notification_xp_granted"`
+	NotificationXPDeducted                    string `yaml:"notification_xp_deducted"`
+	NotificationLevelUp                       string `yaml:"notification_level_up"`
+	NotificationAchievementUnlocked           string `yaml:"notification_achievement_unlocked"`
+	NotificationUserDeclinedComponentTransfer string `yaml:"notification_user_declined_component_transfer"`
+	NotificationPermissionGranted             string `yaml:"notification_permission_granted"`
+	NotificationPermissionRevoked             string `yaml:"notification_permission_revoked"`
+	NotificationTOTPEnabled                   string `yaml:"notification_totp_enabled"`
+	NotificationTOTPDisabled                  string `yaml:"notification_totp_disabled"`
 
 	NotificationInvalid        string `yaml:"notification_invalid"`
 	NotificationAlreadyRead    string `yaml:"notification_already_read"`
@@ -84,54 +129,117 @@ type Translation struct {
 	ValidatorMustBeSupportedLanguage string `yaml:"validator_must_be_supported_language"`
 	ValidatorRequired                string `yaml:"validator_required"`
 
-	ProjectNotFound           string `yaml:"project_not_found"`
-	ProjectCreated            string `yaml:"project_created"`
-	ProjectUpdated            string `yaml:"project_updated"`
-	ProjectDeleted            string `yaml:"project_deleted"`
-	ProjectPublished          string `yaml:"project_published"`
-	ProjectUnpublished        string `yaml:"project_unpublished"`
-	ProjectInvalid            string `yaml:"project_invalid"`
-	ProjectMissingPermissions string `yaml:"project_missing_permissions"`
-	ProjectTrashed            string `yaml:"project_trashed"`
-	ProjectRestored           string `yaml:"project_restored"`
-	ProjectAlreadyTrashed     string `yaml:"project_already_trashed"`
-	ProjectNotTrashed         string `yaml:"project_not_trashed"`
-	ProjectFavorited          string `yaml:"project_favorite"`
-	ProjectUnfavorited        string `yaml:"project_unfavorite"`
-	ProjectAlreadyFavorited   string `yaml:"project_already_favorite"`
-	ProjectNotFavorited       string `yaml:"project_not_favorite"`
-	ProjectForked             string `yaml:"project_forked"`
-	ProjectIsNotAFork         string `yaml:"project_is_not_a_fork"`
-	ProjectUnlinked           string `yaml:"project_unlinked"`
-	ProjectAssignedUser       string `yaml:"project_assigned_user"`
-	ProjectUnassignedUser     string `yaml:"project_unassigned_user"`
-	ProjectEmptyAssign        string `yaml:"project_empty_assign"`
-	ProjectUserNotAssigned    string `yaml:"project_user_not_assigned"`
-	ProjectCannotAssignOwner  string `yaml:"project_cannot_assign_owner"`
-
-	UpstreamNotPublic    string `yaml:"upstream_not_public"`
-	TrashCleared         string `yaml:"trash_cleared"`
-	InsufficientArkhoins string `yaml:"insufficient_arkhoins"`
-
-	ComponentCreated         string `yaml:"component_created"`
-	ComponentUpdated         string `yaml:"component_updated"`
-	ComponentPublished       string `yaml:"component_published"`
-	ComponentUnpublished     string `yaml:"component_unpublished"`
-	ComponentBought          string `yaml:"component_bought"`
-	ComponentSold            string `yaml:"component_sold"`
-	ComponentTrashed         string `yaml:"component_trashed"`
-	ComponentRestored        string `yaml:"component_restored"`
-	ComponentDeleted         string `yaml:"component_deleted"`
-	ComponentInvalid         string `yaml:"component_invalid"`
-	ComponentNotFound        string `yaml:"component_not_found"`
-	ComponentAlreadyTrashed  string `yaml:"component_already_trashed"`
-	ComponentNotTrashed      string `yaml:"component_not_trashed"`
-	ComponentAlreadyOwned    string `yaml:"component_already_owned"`
-	ComponentNotOwned        string `yaml:"component_not_owned"`
-	ComponentAlreadyPublic   string `yaml:"component_already_public"`
-	ComponentNotPublic       string `yaml:"yaml:component_not_public"`
-	ComponentOwnerCannotBuy  string `yaml:"component_owner_cannot_buy"`
-	ComponentOwnerCannotSell string `yaml:"component_owner_cannot_sell"`
+	ProjectNotFound             string `yaml:"project_not_found"`
+	ProjectCreated              string `yaml:"project_created"`
+	ProjectUpdated              string `yaml:"project_updated"`
+	ProjectDeleted              string `yaml:"project_deleted"`
+	ProjectPublished            string `yaml:"project_published"`
+	ProjectUnpublished          string `yaml:"project_unpublished"`
+	ProjectInvalid              string `yaml:"project_invalid"`
+	ProjectMissingPermissions   string `yaml:"project_missing_permissions"`
+	ProjectTrashed              string `yaml:"project_trashed"`
+	ProjectRestored             string `yaml:"project_restored"`
+	ProjectAlreadyTrashed       string `yaml:"project_already_trashed"`
+	ProjectNotTrashed           string `yaml:"project_not_trashed"`
+	ProjectFavorited            string `yaml:"project_favorite"`
+	ProjectUnfavorited          string `yaml:"project_unfavorite"`
+	ProjectAlreadyFavorited     string `yaml:"project_already_favorite"`
+	ProjectNotFavorited         string `yaml:"project_not_favorite"`
+	ProjectForked               string `yaml:"project_forked"`
+	ProjectIsNotAFork           string `yaml:"project_is_not_a_fork"`
+	ProjectUnlinked             string `yaml:"project_unlinked"`
+	ProjectForkSynced           string `yaml:"project_fork_synced"`
+	ProjectAssignedUser         string `yaml:"project_assigned_user"`
+	ProjectUnassignedUser       string `yaml:"project_unassigned_user"`
+	ProjectEmptyAssign          string `yaml:"project_empty_assign"`
+	ProjectUserNotAssigned      string `yaml:"project_user_not_assigned"`
+	ProjectCannotAssignOwner    string `yaml:"project_cannot_assign_owner"`
+	ProjectRevisionInvalid      string `yaml:"project_revision_invalid"`
+	ProjectRevisionNotFound     string `yaml:"project_revision_not_found"`
+	ProjectRevisionRestored     string `yaml:"project_revision_restored"`
+	ProjectVersionRequired      string `yaml:"project_version_required"`
+	ProjectVersionMismatch      string `yaml:"project_version_mismatch"`
+	ProjectVersionInvalid       string `yaml:"project_version_invalid"`
+	ProjectPatchInvalid         string `yaml:"project_patch_invalid"`
+	ProjectPatchUnsupportedType string `yaml:"project_patch_unsupported_type"`
+	ProjectImported             string `yaml:"project_imported"`
+	ProjectArchiveMissing       string `yaml:"project_archive_missing"`
+	ProjectArchiveInvalid       string `yaml:"project_archive_invalid"`
+	ProjectShareLinkCreated     string `yaml:"project_share_link_created"`
+	ProjectShareLinkRevoked     string `yaml:"project_share_link_revoked"`
+	ProjectShareLinkNotFound    string `yaml:"project_share_link_not_found"`
+	ProjectShareLinkInvalid     string `yaml:"project_share_link_invalid"`
+	ProjectShareLinkExpiry      string `yaml:"project_share_link_expiry"`
+	ProjectRoleCreated          string `yaml:"project_role_created"`
+	ProjectRoleUpdated          string `yaml:"project_role_updated"`
+	ProjectRoleDeleted          string `yaml:"project_role_deleted"`
+	ProjectRoleNotFound         string `yaml:"project_role_not_found"`
+	ProjectRoleNameTaken        string `yaml:"project_role_name_taken"`
+	ProjectRoleIsPreset         string `yaml:"project_role_is_preset"`
+	ProjectRoleEmpty            string `yaml:"project_role_empty"`
+	ProjectInviteSent           string `yaml:"project_invite_sent"`
+	ProjectInviteAccepted       string `yaml:"project_invite_accepted"`
+	ProjectInviteDeclined       string `yaml:"project_invite_declined"`
+	ProjectInviteCanceled       string `yaml:"project_invite_canceled"`
+	ProjectInviteNotFound       string `yaml:"project_invite_not_found"`
+	ProjectInviteEmailSubject   string `yaml:"project_invite_email_subject"`
+	ProjectInviteEmailTemplate  string `yaml:"project_invite_email_template"`
+	ProjectTransferSent         string `yaml:"project_transfer_sent"`
+	ProjectTransferAccepted     string `yaml:"project_transfer_accepted"`
+	ProjectTransferDeclined     string `yaml:"project_transfer_declined"`
+	ProjectTransferCanceled     string `yaml:"project_transfer_canceled"`
+	ProjectTransferNotFound     string `yaml:"project_transfer_not_found"`
+	ProjectTransferToOwner      string `yaml:"project_transfer_to_owner"`
+
+	UpstreamNotPublic              string `yaml:"upstream_not_public"`
+	TrashCleared                   string `yaml:"trash_cleared"`
+	InsufficientArkhoins           string `yaml:"insufficient_arkhoins"`
+	IdempotencyKeyInvalid          string `yaml:"idempotency_key_invalid"`
+	IdempotencyKeyReused           string `yaml:"idempotency_key_reused"`
+	InsufficientXP                 string `yaml:"insufficient_xp"`
+	GrantApplied                   string `yaml:"grant_applied"`
+	GrantUsersNotFound             string `yaml:"grant_users_not_found"`
+	AirdropScheduled               string `yaml:"airdrop_scheduled"`
+	AirdropCanceled                string `yaml:"airdrop_canceled"`
+	AirdropNotFound                string `yaml:"airdrop_not_found"`
+	AirdropInPast                  string `yaml:"airdrop_in_past"`
+	OrganizationCreated            string `yaml:"organization_created"`
+	OrganizationUpdated            string `yaml:"organization_updated"`
+	OrganizationDeleted            string `yaml:"organization_deleted"`
+	OrganizationNotFound           string `yaml:"organization_not_found"`
+	OrganizationNameTaken          string `yaml:"organization_name_taken"`
+	OrganizationMissingPermissions string `yaml:"organization_missing_permissions"`
+	OrganizationMemberUpdated      string `yaml:"organization_member_updated"`
+	OrganizationMemberRemoved      string `yaml:"organization_member_removed"`
+	OrganizationNotMember          string `yaml:"organization_not_member"`
+	OrganizationLastOwner          string `yaml:"organization_last_owner"`
+	OrganizationDeposited          string `yaml:"organization_deposited"`
+
+	ComponentCreated          string `yaml:"component_created"`
+	ComponentUpdated          string `yaml:"component_updated"`
+	ComponentPublished        string `yaml:"component_published"`
+	ComponentUnpublished      string `yaml:"component_unpublished"`
+	ComponentBought           string `yaml:"component_bought"`
+	ComponentSold             string `yaml:"component_sold"`
+	ComponentTrashed          string `yaml:"component_trashed"`
+	ComponentRestored         string `yaml:"component_restored"`
+	ComponentDeleted          string `yaml:"component_deleted"`
+	ComponentInvalid          string `yaml:"component_invalid"`
+	ComponentNotFound         string `yaml:"component_not_found"`
+	ComponentAlreadyTrashed   string `yaml:"component_already_trashed"`
+	ComponentNotTrashed       string `yaml:"component_not_trashed"`
+	ComponentAlreadyOwned     string `yaml:"component_already_owned"`
+	ComponentNotOwned         string `yaml:"component_not_owned"`
+	ComponentAlreadyPublic    string `yaml:"component_already_public"`
+	ComponentNotPublic        string `yaml:"yaml:component_not_public"`
+	ComponentOwnerCannotBuy   string `yaml:"component_owner_cannot_buy"`
+	ComponentOwnerCannotSell  string `yaml:"component_owner_cannot_sell"`
+	ComponentTransferSent     string `yaml:"component_transfer_sent"`
+	ComponentTransferAccepted string `yaml:"component_transfer_accepted"`
+	ComponentTransferDeclined string `yaml:"component_transfer_declined"`
+	ComponentTransferCanceled string `yaml:"component_transfer_canceled"`
+	ComponentTransferNotFound string `yaml:"component_transfer_not_found"`
+	ComponentTransferToOwner  string `yaml:"component_transfer_to_owner"`
 
 	PasswordResetRequest       string `yaml:"password_reset_request"`
 	PasswordResetSuccess       string `yaml:"password_reset_success"`
@@ -139,6 +247,21 @@ type Translation struct {
 	PasswordResetEmailTemplate string `yaml:"password_reset_email_template"`
 	InvalidPasswordResetKey    string `yaml:"invalid_password_reset_key"`
 
+	EmailVerificationSent          string `yaml:"email_verification_sent"`
+	EmailVerificationThrottled     string `yaml:"email_verification_throttled"`
+	EmailVerificationEmailSubject  string `yaml:"email_verification_email_subject"`
+	EmailVerificationEmailTemplate string `yaml:"email_verification_email_template"`
+	EmailVerified                  string `yaml:"email_verified"`
+	EmailAlreadyVerified           string `yaml:"email_already_verified"`
+	InvalidEmailVerificationKey    string `yaml:"invalid_email_verification_key"`
+
+	PermissionNotFound         string `yaml:"permission_not_found"`
+	PermissionGranted          string `yaml:"permission_granted"`
+	PermissionRevoked          string `yaml:"permission_revoked"`
+	PermissionAlreadyGranted   string `yaml:"permission_already_granted"`
+	PermissionNotGranted       string `yaml:"permission_not_granted"`
+	PermissionCannotRevokeSelf string `yaml:"permission_cannot_revoke_self"`
+
 	UnsupportedFileType string `yaml:"unsupported_file_type"`
 	UnableToDecodeFile  string `yaml:"unable_to_decode_file"`
 	UnableToEncodeFile  string `yaml:"unable_to_encode_file"`